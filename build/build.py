@@ -44,7 +44,7 @@ if len(sys.argv) > 1 and sys.argv[1] == 'srv':
 
     os.chdir('../golang')
     os.system('go fmt .')
-    os.system('go build')
+    os.system('go build -tags sqlite_fts5')
 
     shutil.copyfile('tgtsrv.exe', '../server/tgtsrv.exe')
 
@@ -1,4 +1,5 @@
 import json
+import re
 
 
 def to_go(k):
@@ -11,7 +12,6 @@ package main
 
 import (
     "database/sql"
-    "errors"
     "fmt"
     "time"
 
@@ -23,7 +23,8 @@ var db *sql.DB
 
 func DBconnect(dbFile string) error {{
     var err error
-    db, err = sql.Open("sqlite3", dbFile)
+    // writers wait for each other, a transaction reads rows it writes
+    db, err = sql.Open("sqlite3", "file:"+dbFile+"?_busy_timeout=10000&_txlock=immediate")
     return err
 }}
 func DbClose() error {{
@@ -39,6 +40,7 @@ package main
 
 import (
     "encoding/json"
+    "flag"
     "log"
     "net/http"
     "os"
@@ -85,8 +87,47 @@ def create_go_main_footer():
     r.HandleFunc("/copy_base/{fs}", WrapAuth(CopyBase, ADMIN)).Methods("GET")
     r.HandleFunc("/delete_base/{fs}", WrapAuth(DeleteBackupBase, ADMIN)).Methods("GET")
     r.HandleFunc("/get_bases", WrapAuth(GetBackupBases, ADMIN)).Methods("GET")
-    r.HandleFunc("/restore_base/{fs}", WrapAuth(RestoreBaseFromBackup, ADMIN)).Methods("GET")
+    r.HandleFunc("/restore_base/{fs}", WrapAuthAdd(RestoreBaseFromBackup, ADMIN, ADD_RESTORE_BASE)).Methods("GET")
     r.HandleFunc("/ws", WrapAuth(UpgradeWS, WS_CONNECT)).Methods("GET")
+    r.HandleFunc("/revoke_sessions/{id:[0-9]+}", WrapAuth(RevokeSessions, ADMIN)).Methods("GET")
+    r.HandleFunc("/session_get_all", WrapAuth(GetSessionAll, ADMIN)).Methods("GET")
+    r.HandleFunc("/session/{id:[0-9]+}", WrapAuth(TerminateSession, ADMIN)).Methods("DELETE")
+    r.HandleFunc("/access_names", WrapAuth(GetAccessNames, LOGOUT)).Methods("GET")
+    r.HandleFunc("/search", WrapAuth(GetSearch, LOGOUT)).Methods("GET")
+    r.HandleFunc("/api_token", WrapAuth(CreateApiToken, ADMIN)).Methods("POST")
+    r.HandleFunc("/api_token_get_all", WrapAuth(GetApiTokenAll, ADMIN)).Methods("GET")
+    r.HandleFunc("/api_token/{id:[0-9]+}", WrapAuth(RevokeApiToken, ADMIN)).Methods("DELETE")
+    r.HandleFunc("/login_lock_get_all", WrapAuth(GetLoginLockAll, ADMIN)).Methods("GET")
+    r.HandleFunc("/login_lock/{id:[0-9]+}", WrapAuth(DeleteLoginLock, ADMIN)).Methods("DELETE")
+    r.HandleFunc("/login_attempt_between_created_at/{fs}/{fs2}", WrapAuth(GetLoginAttemptBetweenCreatedAt, ADMIN)).Methods("GET")
+    r.HandleFunc("/audit_log", WrapAuth(GetAuditLog, ADMIN)).Methods("GET")
+    r.HandleFunc("/ledger_check", WrapAuth(GetLedgerCheck, ADMIN)).Methods("GET")
+    r.HandleFunc("/ledger_fix", WrapAuth(FixLedger, ADMIN)).Methods("POST")
+    r.HandleFunc("/balance/{fs}", WrapAuth(GetBalance, DOC_READ)).Methods("GET")
+    r.HandleFunc("/movement/{fs}", WrapAuth(GetMovements, DOC_READ)).Methods("GET")
+    r.HandleFunc("/document_tree/{fs}/{id:[0-9]+}", WrapAuth(GetDocumentTree, DOC_READ)).Methods("GET")
+    r.HandleFunc("/period_close_get_all", WrapAuth(GetPeriodCloseAll, DOC_READ)).Methods("GET")
+    r.HandleFunc("/period_close", WrapAuth(SetPeriodClose, ADMIN)).Methods("POST")
+    r.HandleFunc("/purge", WrapAuth(PurgeDeleted, ADMIN)).Methods("POST")
+    r.HandleFunc("/doc_sequence_get_all", WrapAuth(GetDocSequenceAll, DOC_READ)).Methods("GET")
+    r.HandleFunc("/doc_sequence", WrapAuth(SetDocSequence, ADMIN)).Methods("POST")
+    r.HandleFunc("/doc_sequence_next/{fs}", WrapAuth(GetDocSequenceNext, DOC_READ)).Methods("GET")
+    r.HandleFunc("/ordering_transition_get_all", WrapAuth(GetOrderingTransitionAll, DOC_READ)).Methods("GET")
+    r.HandleFunc("/ordering_transition", WrapAuth(SetOrderingTransition, ADMIN)).Methods("POST")
+    r.HandleFunc("/ordering_transition/{id:[0-9]+}", WrapAuth(DeleteOrderingTransition, ADMIN)).Methods("DELETE")
+    r.HandleFunc("/ordering/{id:[0-9]+}/transition/{id2:[0-9]+}", WrapAuth(CheckOrderingTransition, DOC_READ)).Methods("GET")
+    r.HandleFunc("/ordering/{id:[0-9]+}/transition/{id2:[0-9]+}", WrapAuth(TransitOrdering, DOC_UPDATE)).Methods("POST")
+    r.HandleFunc("/ordering/{id:[0-9]+}/make_invoice", WrapAuth(MakeOrderingInvoice, DOC_CREATE)).Methods("POST")
+    r.HandleFunc("/ordering/{id:[0-9]+}/pdf", WrapAuth(GetOrderingPdf, DOC_READ)).Methods("GET")
+    r.HandleFunc("/invoice/{id:[0-9]+}/pdf", WrapAuth(GetInvoicePdf, DOC_READ)).Methods("GET")
+    r.HandleFunc("/whs_out/{id:[0-9]+}/pdf", WrapAuth(GetWhsOutPdf, DOC_READ)).Methods("GET")
+    r.HandleFunc("/contragent/{id:[0-9]+}/reconciliation/pdf", WrapAuth(GetReconciliationPdf, DOC_READ)).Methods("GET")
+    r.HandleFunc("/login_totp", WrapAuth(LoginTotp, LOGIN)).Methods("POST")
+    r.HandleFunc("/totp_status", WrapAuth(GetTotpStatus, LOGOUT)).Methods("GET")
+    r.HandleFunc("/totp_enroll", WrapAuth(EnrollTotp, LOGOUT)).Methods("POST")
+    r.HandleFunc("/totp_confirm", WrapAuth(ConfirmTotp, LOGOUT)).Methods("POST")
+    r.HandleFunc("/totp_disable", WrapAuth(DisableTotp, LOGOUT)).Methods("POST")
+    r.HandleFunc("/user_totp/{id:[0-9]+}", WrapAuth(ResetUserTotp, ADMIN)).Methods("DELETE")
 
     return r
 }
@@ -99,6 +140,13 @@ type Config struct {
     NewMaketsPath string   `json:"new_makets_path"`
     MaketDirs     []string `json:"maket_dirs"`
     DBFile        string   `json:"db_file"`
+    SessionKeys   []SessionKey `json:"session_keys"`
+    SessionStore  string   `json:"session_store"`
+    LoginThrottle LoginThrottle `json:"login_throttle"`
+    Totp          TotpConfig    `json:"totp"`
+    ManualMigrate bool          `json:"manual_migrate"`
+    MeasurePieces int           `json:"measure_pieces"`
+    Pdf           PdfConfig     `json:"pdf"`
 }
 
 var Cfg Config
@@ -114,11 +162,23 @@ func LoadConfig() error {
 
 func main() {
 
+    migrateFlag := flag.Bool("migrate", false, "migrate the base to the server schema version and exit")
+    flag.Parse()
+
     if err := LoadConfig(); err != nil {
         log.Fatal("Can`t load config file!")
     }
 
-    err := DBconnect(Cfg.DBFile)
+    if *migrateFlag {
+        RunMigrations()
+    }
+
+    err := OpenBase(Cfg.DBFile)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    err = InitSessions()
     if err != nil {
         log.Fatal(err)
     }
@@ -186,7 +246,13 @@ func CreateProductToOrderingDefault(req Req) {
         req.Respond(nil, err)
         return
     }
-    req.Respond(ProductToOrderingCreateDefault(p, false))
+    tx, err := req.Begin()
+    if err != nil {
+        req.Respond(nil, err)
+        return
+    }
+    p, err = ProductToOrderingCreateDefault(p, false, tx)
+    req.RespondTx(tx, p, err)
 }
 
 func CreateProductToOrderingDefaultCC(req Req) {
@@ -195,7 +261,13 @@ func CreateProductToOrderingDefaultCC(req Req) {
         req.Respond(nil, err)
         return
     }
-    req.Respond(ProductToOrderingCreateDefault(p, true))
+    tx, err := req.Begin()
+    if err != nil {
+        req.Respond(nil, err)
+        return
+    }
+    p, err = ProductToOrderingCreateDefault(p, true, tx)
+    req.RespondTx(tx, p, err)
 }
 
     '''
@@ -233,6 +305,11 @@ def create_go_models(model, tables):
         g += g1
         h += h1
         m += m1
+        if g1:
+            g1, h1, m1 = create_go_restore(table, keys, model)
+            g += g1
+            h += h1
+            m += m1
 
         g1, h1, m1 = create_go_filter_int(table, keys, model)
         g += g1
@@ -246,6 +323,11 @@ def create_go_models(model, tables):
 
         h += create_go_decode(table)
         g += make_field_validation(table, keys)
+        if is_own_doc(table, model):
+            g += create_go_owned_by(to_go(table), table[0])
+        elif own_item_parent(table, model):
+            g += create_go_owned_by_item(to_go(table), table[0], own_item_parent(table, model))
+            g += create_go_own_item_check(table, to_go(table), table[0], own_item_parent(table, model))
         
         if table in model['documents']: 
             g1, h1, m1 = create_go_realized(table, keys, model)
@@ -290,6 +372,11 @@ def create_go_models(model, tables):
     g += g1
     h += h1
     m += m1
+    g += create_go_ledger_registers(model, tables)
+    g += create_go_document_tables(model, tables)
+    g += create_go_documents_period_check(model)
+    g += create_go_foreign_keys(model, tables)
+    g += create_go_column_hums(model, tables)
 
 
     with open ('../golang/models.go', 'w') as f:
@@ -303,6 +390,93 @@ def create_go_models(model, tables):
         f.write(m)
 
 
+def doc_of_item(table, model):
+    if table in model['doc_table_items'] or table == 'item_to_invoice':
+        return table.split('_to_')[1]
+    return ''
+
+
+def create_go_ledger_registers(model, tables):
+    g = '''
+
+// registers of models.json, the ledger checker recomputes their fields
+var ledgerRegisters = []LedgerRegister{'''
+    for table in tables:
+        parent = doc_of_item(table, model)
+        for kind, realized in (('register', 'false'), ('rz_register', 'true')):
+            for register in model['models'][table].get(kind, []):
+                if not register['func']: # last value, not a sum
+                    continue
+                reg_table, reg_field = register['reg_field'].split('.')
+                sign = '1' if register['func'] == '+' else '-1'
+                values = ', '.join(f'"{v}"' for v in register['val_field'])
+                g += f'''
+    {{Source: "{table}", Parent: "{parent}", Target: "{reg_table}", Field: "{reg_field}",
+        Values: []string{{{values}}}, Sign: {sign}, Realized: {realized}}},'''
+        for register in model['models'][table].get('complex_register', []):
+            sign = '1' if register['func'] == '+' else '-1'
+            key_fields = ', '.join(f'"{v}"' for v in register['key_fields'])
+            # "whs_in.whs_id" is the field of the document of the item
+            key_values = ', '.join('"' + (f'{parent}.' + v.split('.')[1] if '.' in v else v) + '"' for v in register['key_values'])
+            g += f'''
+    {{Source: "{table}", Parent: "{parent}", Target: "{register['reg_table']}", Field: "{register['reg_field']}",
+        Values: []string{{"{register['val_field']}"}}, Sign: {sign}, Realized: true,
+        KeyFields: []string{{{key_fields}}}, KeyValues: []string{{{key_values}}}}},'''
+    g += '''
+}
+'''
+    return g
+
+
+def is_numbered(table, model):
+    # names of orderings are given by users
+    return table in model['documents'] and table != 'ordering'
+
+
+def create_go_document_tables(model, tables):
+    documents = ', '.join(f'"{t}"' for t in model['documents'])
+    numbered = ', '.join(f'"{t}"' for t in model['documents'] if is_numbered(t, model))
+    based_on = ', '.join(f'"{t}"' for t in tables if 'based_on' in model['models'][t]['columns'])
+    own = ', '.join(f'"{t}"' for t in model['documents'] if is_own_doc(t, model))
+    catalogs = ', '.join(f'"{t}"' for t in tables
+                         if t not in model['documents'] and not document_of_item(t, model))
+    return f'''
+
+// documents which are realized
+var documentTables = []string{{{documents}}}
+
+// tables linked to parent documents by based_on
+var basedOnTables = []string{{{based_on}}}
+
+// documents named by DocNumber on create
+var numberedTables = []string{{{numbered}}}
+
+// documents with user_id, own-only users work with their rows only
+var ownTables = []string{{{own}}}
+
+// catalogs, not documents and their items
+var catalogTables = []string{{{catalogs}}}
+'''
+
+
+def create_go_column_hums(model, tables):
+    g = '''
+
+// human names of columns and of joined w_ columns for exports
+var columnHums = map[string]map[string]string{'''
+    for table in tables:
+        m = model['models'][table]
+        hums = ''.join(f'''
+        "{k}": "{v['hum']}",''' for k, v in list(m['model'].items()) + list(m['w_model'].items()))
+        g += f'''
+    "{table}": {{{hums}
+    }},'''
+    g += '''
+}
+'''
+    return g
+
+
 def create_go_models_w(model, tables):
     g = ''
     h = ''
@@ -310,6 +484,10 @@ def create_go_models_w(model, tables):
     for table in tables:
         keys = model['models'][table]['columns']
         g += create_go_model_w(table, model)
+        if is_own_doc(table, model):
+            g += create_go_owned_by('W' + to_go(table), table[0])
+        elif own_item_parent(table, model):
+            g += create_go_owned_by_item('W' + to_go(table), table[0], own_item_parent(table, model))
 
         g1, h1, m1 = create_go_get_w(table, keys, model)
         g += g1
@@ -404,6 +582,266 @@ def create_go_model(table, model):
     return g
 
 
+def create_go_tx_call(gv, call, declare=False):
+    assign = ':=' if declare else '='
+    return f'''tx, err := req.Begin()
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    {gv}, err {assign} {call}
+    req.RespondTx(tx, {gv}, err)'''
+
+
+def create_go_audit(table, gv, action, before, after):
+    return f'''
+    err = Audit(tx, "{table}", {gv}.Id, "{action}", {before}, {after})
+    if err != nil {{
+        return {gv}, err
+    }}
+    '''
+
+
+def is_own_doc(table, model):
+    return table in model['documents'] and 'user_id' in model['models'][table]['columns']
+
+
+def own_item_parent(table, model):
+    # items of own documents belong to the user of their document
+    parent = document_of_item(table, model)
+    if parent and is_own_doc(parent, model):
+        return parent
+    return ''
+
+
+def create_go_own_get_handler(name, call, gv, parent=''):
+    if parent:
+        return f'''
+func {name}(req Req) {{
+    {gv}, err := {call}
+    if err == nil {{
+        err = req.OwnDocCheck("{parent}", {gv}.{to_go(parent)}Id)
+    }}
+    req.Respond({gv}, err)
+}}
+    '''
+    return f'''
+func {name}(req Req) {{
+    {gv}, err := {call}
+    if err == nil && req.OwnOnly() && {gv}.UserId != req.UserId {{
+        req.Respond(nil, ErrNotOwner)
+        return
+    }}
+    req.Respond({gv}, err)
+}}
+    '''
+
+
+def create_go_export(table, model, call, gv, gtype, by_query=False):
+    # exports read rows one by one by the Each variant of the list function
+    each = call[:call.index('(')] + 'Each' + call[call.index('('):-1]
+    add = f'''func({gv} {gtype}) error {{
+            return add({gv})
+        }})'''
+    if by_query:
+        return f'''
+        if req.Exporting() {{
+            req.Export("{table}", {gtype}{{}}, func(add func(interface{{}}) error) error {{
+                _, err := {each}, {add}
+                return err
+            }})
+            return
+        }}'''
+    owned = ''
+    if is_own_doc(table, model):
+        add = f'''func({gv} {gtype}) error {{
+            if req.OwnOnly() && {gv}.UserId != req.UserId {{
+                return nil
+            }}
+            return add({gv})
+        }})'''
+    elif own_item_parent(table, model):
+        parent = own_item_parent(table, model)
+        owned = f'''
+        owned, err := req.OwnDocs("{parent}")
+        if err != nil {{
+            return err
+        }}'''
+        add = f'''func({gv} {gtype}) error {{
+            if owned != nil && !owned[{gv}.{to_go(parent)}Id] {{
+                return nil
+            }}
+            return add({gv})
+        }})'''
+    return f'''
+    if req.Exporting() {{
+        req.Export("{table}", {gtype}{{}}, func(add func(interface{{}}) error) error {{{owned}
+            return {each}, {add}
+        }})
+        return
+    }}'''
+
+
+def create_go_list_handler(name, call, gv, gtype, table, model, head=''):
+    export = create_go_export(table, model, call, gv, gtype)
+    owned = ''
+    if is_own_doc(table, model):
+        owned = f'''
+    if err == nil && req.OwnOnly() {{
+        {gv} = {gtype}OwnedBy({gv}, req.UserId)
+    }}'''
+    elif own_item_parent(table, model):
+        # items are filtered by their documents, it can fail
+        owned = f'''
+    if err == nil && req.OwnOnly() {{
+        {gv}, err = {gtype}OwnedBy({gv}, req.UserId)
+    }}'''
+    return f'''
+func {name}(req Req) {{{head}{export}
+    {gv}, err := {call}{owned}
+    req.RespondRows("{table}", {gv}, err)
+}}
+    '''
+
+
+def create_go_each(g):
+    # list functions of g get Each variants which give rows to the callback
+    # one by one, the list functions collect them
+    res = ''
+    pos = 0
+    found = False
+    for m in re.finditer(r'func (\w+)\(([^)]*)\) \(\[\](\w+), (int, )?error\) \{', g):
+        name, params, gtype, total = m.groups()
+        depth = 0
+        for end in range(m.end() - 1, len(g)):
+            if g[end] == '{':
+                depth += 1
+            elif g[end] == '}':
+                depth -= 1
+                if depth == 0:
+                    break
+        body = g[m.end():end]
+        if 'rows.Next()' not in body:
+            continue
+        found = True
+        gv = re.search(r'res = append\(res, (\w+)\)', body).group(1)
+        zero = '0, ' if total else ''
+        body = re.sub(r'return nil, (0, )?', 'return ' + zero, body)
+        body = re.sub(r'\n\s*res := \[\]\w+\{\}', '', body)
+        body = re.sub(r'res = append\(res, \w+\)', f'''if err := each({gv}); err != nil {{
+            return {zero}err
+        }}''', body)
+        body = body.replace('return res, total, nil', 'return total, rows.Err()')
+        body = body.replace('return res, nil', 'return rows.Err()')
+        args = ', '.join(p.split()[0] for p in params.split(','))
+        declare = 'total, err :=' if total else 'err :='
+        result = 'res, total, nil' if total else 'res, nil'
+        res += g[pos:m.start()] + f'''func {name}({params}) ([]{gtype}, {total or ''}error) {{
+    res := []{gtype}{{}}
+    {declare} {name}Each({args}, func({gv} {gtype}) error {{
+        res = append(res, {gv})
+        return nil
+    }})
+    if err != nil {{
+        return nil, {zero}err
+    }}
+    return {result}
+}}
+
+func {name}Each({params}, each func({gtype}) error) {'(int, error)' if total else 'error'} {{{body}}}'''
+        pos = end + 1
+    assert found, 'no list functions'
+    return res + g[pos:]
+
+
+def create_go_list_query(table, model, call):
+    own = ''
+    if is_own_doc(table, model):
+        own = '''
+        if req.OwnOnly() {
+            q = q.Owned(req.UserId)
+        }'''
+    elif own_item_parent(table, model):
+        parent = own_item_parent(table, model)
+        own = f'''
+        if req.OwnOnly() {{
+            q = q.OwnedIn("{parent}_id", "{parent}", req.UserId)
+        }}'''
+    export = create_go_export(table, model, call, table[0], call[:call.index('GetByQuery')], True)
+    return f'''
+    if req.HasListQuery() {{
+        q, err := req.ListQuery()
+        if err != nil {{
+            req.Respond(nil, err)
+            return
+        }}{own}{export}
+        {table[0]}, total, err := {call}
+        req.RespondList("{table}", {table[0]}, total, err)
+        return
+    }}'''
+
+
+def create_go_owned_by(gtype, gv):
+    return f'''
+
+func {gtype}OwnedBy(items []{gtype}, userId int) []{gtype} {{
+    res := []{gtype}{{}}
+    for _, {gv} := range items {{
+        if {gv}.UserId == userId {{
+            res = append(res, {gv})
+        }}
+    }}
+    return res
+}}
+'''
+
+
+def create_go_owned_by_item(gtype, gv, parent):
+    return f'''
+
+func {gtype}OwnedBy(items []{gtype}, userId int) ([]{gtype}, error) {{
+    ids, err := OwnDocIds("{parent}", userId)
+    if err != nil {{
+        return nil, err
+    }}
+    res := []{gtype}{{}}
+    for _, {gv} := range items {{
+        if ids[{gv}.{to_go(parent)}Id] {{
+            res = append(res, {gv})
+        }}
+    }}
+    return res, nil
+}}
+'''
+
+
+def create_go_own_item_check(table, gtype, gv, parent):
+    return f'''
+
+// {gtype}OwnCheck refuses own-only requests to {table} of {parent} of other users
+func {gtype}OwnCheck(req Req, id int) error {{
+    if !req.OwnOnly() {{
+        return nil
+    }}
+    {gv}, err := {gtype}Get(id, nil)
+    if err != nil {{
+        return err
+    }}
+    return req.OwnDocCheck("{parent}", {gv}.{to_go(parent)}Id)
+}}
+'''
+
+
+def create_go_own_item_check_call(table, model, gtype):
+    if not own_item_parent(table, model):
+        return ''
+    return f'''
+    if err := {gtype}OwnCheck(req, req.IntParam); err != nil {{
+        req.Respond(nil, err)
+        return
+    }}'''
+
+
 def create_go_get(table, keys, model):
     right = model['models'][table]['rights'] + '_READ'
     gtype = to_go(table)
@@ -418,9 +856,13 @@ def create_go_get(table, keys, model):
             req.Respond({gtype}Get(req.IntParam, nil))
         }}
     '''
+    if is_own_doc(table, model):
+        h = create_go_own_get_handler(f'Get{gtype}', f'{gtype}Get(req.IntParam, nil)', gv)
+    elif own_item_parent(table, model):
+        h = create_go_own_get_handler(f'Get{gtype}', f'{gtype}Get(req.IntParam, nil)', gv, own_item_parent(table, model))
 
     g = f'''
-        func {gtype}Get(id int, tx *sql.Tx) ({gtype}, error) {{
+        func {gtype}Get(id int, tx *Tx) ({gtype}, error) {{
             var {gv} {gtype}
             var row *sql.Row
             if tx != nil {{
@@ -448,14 +890,11 @@ def create_go_gets(table, keys, model):
             WrapAuth(Get{gtype}All, {right})).Methods("GET")
     '''
 
-    h = f'''
-        func Get{gtype}All(req Req) {{
-            req.Respond({gtype}GetAll(req.WithDeleted, req.DeletedOnly, nil))
-        }}
-    '''
+    head = create_go_list_query(table, model, f'{gtype}GetByQuery(q, nil)')
+    h = create_go_list_handler(f'Get{gtype}All', f'{gtype}GetAll(req.WithDeleted, req.DeletedOnly, nil)', gv, gtype, table, model, head)
 
     g = f'''
-        func {gtype}GetAll(withDeleted bool, deletedOnly bool, tx *sql.Tx) ([]{gtype}, error) {{
+        func {gtype}GetAll(withDeleted bool, deletedOnly bool, tx *Tx) ([]{gtype}, error) {{
             var rows *sql.Rows
             var err error
             query := "SELECT * FROM {table}"
@@ -486,8 +925,48 @@ def create_go_gets(table, keys, model):
             }}
             return res, nil
         }}
+
+        // {gtype}GetByQuery returns the page of the list and the number of all found rows
+        func {gtype}GetByQuery(q ListQuery, tx *Tx) ([]{gtype}, int, error) {{
+            where, args, err := q.Where("{table}", {gtype}TestForExistingField)
+            if err != nil {{
+                return nil, 0, err
+            }}
+            tail, err := q.Tail("{table}", {gtype}TestForExistingField)
+            if err != nil {{
+                return nil, 0, err
+            }}
+            var total int
+            var rows *sql.Rows
+            if tx != nil {{
+                err = tx.QueryRow("SELECT count(*) FROM {table}"+where, args...).Scan(&total)
+                if err == nil {{
+                    rows, err = tx.Query("SELECT * FROM {table}"+where+tail, args...)
+                }}
+            }} else {{
+                err = db.QueryRow("SELECT count(*) FROM {table}"+where, args...).Scan(&total)
+                if err == nil {{
+                    rows, err = db.Query("SELECT * FROM {table}"+where+tail, args...)
+                }}
+            }}
+            if err != nil {{
+                return nil, 0, err
+            }}
+            defer rows.Close()
+            res := []{gtype}{{}}
+            for rows.Next() {{
+                var {gv} {gtype}
+                if err := rows.Scan(
+            {list_of_pointers(keys, gv)}
+                ); err != nil {{
+                    return nil, 0, err
+                }}
+                res = append(res, {gv})
+            }}
+            return res, total, nil
+        }}
         '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 def create_go_decode(type):
     gtype = to_go(type)
@@ -536,6 +1015,189 @@ def create_go_create_complex_registers(register, gv, table):
     return reg
 
 
+def create_go_hooks(table, model, act, when, gv):
+    g = ''
+    if 'hooks' in model['models'][table]:
+        for hook in model['models'][table]['hooks']:
+            if hook['act'] == act and hook['when'] == when:
+                g += f'''
+                err = {hook['func']}(&{gv}, tx)
+                if err != nil {{
+                    return {gv}, err
+                }}
+                '''
+    return g
+
+
+def document_of_item(table, model):
+    if '_to_' in table and table.split('_to_')[1] in model['documents']:
+        return table.split('_to_')[1]
+    return ''
+
+
+def is_deletable(table, model):
+    # register = last value can't be undone, such rows are not deleted
+    for kind in ('register', 'rz_register'):
+        for register in model['models'][table].get(kind, []):
+            if not register['func']:
+                return False
+    return True
+
+
+def is_referenced_catalog(table, model):
+    if table in model['documents'] or document_of_item(table, model):
+        return False
+    for t in model['models']:
+        for ref in model['models'][t].get('fkeys', {}).values():
+            if ref[0] == table:
+                return True
+    return False
+
+
+def create_go_fkeys_check(table, model, gv, before=''):
+    if not model['models'][table].get('fkeys'):
+        return ''
+    gtype = to_go(table)
+    old = f'{gtype}ForeignKeys({before})' if before else 'nil'
+    return f'''
+        err = ForeignKeysCheck("{table}", {gtype}ForeignKeys({gv}), {old}, tx)
+        if err != nil {{
+            return {gv}, err
+        }}
+        '''
+
+
+def create_go_period_check(table, model, v, gv):
+    parent = document_of_item(table, model)
+    if table in model['documents']:
+        owner = f'{v}.OwnerId' if 'owner_id' in model['models'][table]['columns'] else '0'
+        check = f'PeriodCheck("{table}", {v}.Id, {v}.CreatedAt, {owner}, tx)'
+    elif parent:
+        check = f'{to_go(parent)}PeriodCheck({v}.{to_go(parent)}Id, tx)'
+    else:
+        return ''
+    return f'''
+        err = {check}
+        if err != nil {{
+            return {gv}, err
+        }}
+        '''
+
+
+def create_go_documents_period_check(model):
+    g = ''
+    for table in model['documents']:
+        gtype = to_go(table)
+        owner = 'd.OwnerId' if 'owner_id' in model['models'][table]['columns'] else '0'
+        g += f'''
+
+// {gtype}PeriodCheck refuses changes of items of the {table} created in the closed period
+func {gtype}PeriodCheck(id int, tx *Tx) error {{
+    d, err := {gtype}Get(id, tx)
+    if err != nil {{
+        return err
+    }}
+    return PeriodCheck("{table}", d.Id, d.CreatedAt, {owner}, tx)
+}}
+'''
+    checks = ''.join(f'''
+    "{table}": {to_go(table)}PeriodCheck,''' for table in model['documents'])
+    g += f'''
+
+var documentPeriodChecks = map[string]func(id int, tx *Tx) error{{{checks}
+}}
+'''
+    return g
+
+
+def create_go_foreign_keys(model, tables):
+    names = ', '.join(f'"{t}"' for t in tables)
+    g = f'''
+
+var modelTables = []string{{{names}}}
+
+// fkeys of models.json
+var foreignKeys = []ForeignKey{{'''
+    for table in tables:
+        for column, ref in model['models'][table].get('fkeys', {}).items():
+            g += f'''
+    {{"{table}", "{column}", "{ref[0]}"}},'''
+    g += '''
+}
+
+// deletes of models, cascade of references deletes rows by them
+var deleteFuncs = map[string]func(id int, tx *Tx) error{'''
+    for table in tables:
+        if is_deletable(table, model):
+            g += f'''
+    "{table}": func(id int, tx *Tx) error {{
+        _, err := {to_go(table)}Delete(id, tx, false)
+        return err
+    }},'''
+    g += '''
+}
+'''
+    for table in tables:
+        fkeys = model['models'][table].get('fkeys', {})
+        if not fkeys:
+            continue
+        gtype = to_go(table)
+        gv = table[0]
+        values = ''.join(f'''
+        "{column}": {gv}.{to_go(column)},''' for column in fkeys)
+        g += f'''
+func {gtype}ForeignKeys({gv} {gtype}) map[string]int {{
+    return map[string]int{{{values}
+    }}
+}}
+'''
+    return g
+
+
+def create_go_restore(table, keys, model):
+    right = model['models'][table]['rights'] + '_DELETE'
+    gtype = to_go(table)
+    gv = table[0]
+    m = f'''
+        r.HandleFunc("/{table}/{{id:[0-9]+}}/restore",
+            WrapAuth(Restore{gtype}, {right})).Methods("POST")
+    '''
+    h = f'''
+        func Restore{gtype}(req Req) {{{create_go_own_item_check_call(table, model, gtype)}
+            {create_go_tx_call(gv, f'{gtype}Restore(req.IntParam, tx)', True)}
+        }}
+        '''
+    g = f'''
+        // {gtype}Restore reverses the delete of {table} with the rows deleted with it
+        func {gtype}Restore(id int, tx *Tx) ({gtype}, error) {{
+            needCommit := false
+            var err error
+            var {gv} {gtype}
+            if tx == nil {{
+                tx, err = Begin()
+                if err != nil {{
+                    return {gv}, err
+                }}
+                needCommit = true
+                defer tx.Rollback()
+            }}
+            _, err = Restore("{table}", id, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            {gv}, err = {gtype}Get(id, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            if needCommit {{
+                err = tx.Commit()
+            }}
+            return {gv}, err
+        }}
+        '''
+    return g, h, m
+
+
 def create_go_create(table, keys, model):
     right = model['models'][table]['rights'] + '_CREATE'
     gtype = to_go(table)
@@ -553,9 +1215,45 @@ def create_go_create(table, keys, model):
                 req.Respond(nil, err)
                 return
             }}
-            req.Respond({gtype}Create({gv}, nil))
+            {create_go_tx_call(gv, f'{gtype}Create({gv}, tx)')}
         }}
     '''
+    if own_item_parent(table, model):
+        h = f'''
+func Create{gtype}(req Req) {{
+    {gv}, err := Decode{gtype}(req)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    err = req.OwnDocCheck("{own_item_parent(table, model)}", {gv}.{to_go(own_item_parent(table, model))}Id)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    {create_go_tx_call(gv, f'{gtype}Create({gv}, tx)')}
+}}
+    '''
+    if 'add_access' in model['models'][table]:
+        h = f'''
+func Create{gtype}(req Req) {{
+    {gv}, err := Decode{gtype}(req)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}'''
+        for field, access in model['models'][table]['add_access'].items():
+            fdef = get_def(table, field, model)
+            fdef = json.dumps(fdef) if isinstance(fdef, (str, bool)) else f'{fdef:g}'
+            h += f'''
+    if {gv}.{to_go(field)} != {fdef} && !req.HasAddAccess({access}) {{
+        req.Respond(nil, ErrAccessDenied)
+        return
+    }}'''
+        h += f'''
+    {create_go_tx_call(gv, f'{gtype}Create({gv}, tx)')}
+}}
+    '''
     reg_get = ''
     if 'register' in model['models'][table]:
         registers = model['models'][table]['register']
@@ -564,38 +1262,54 @@ def create_go_create(table, keys, model):
 
     doc_update_name = ''
     doc_update_name_tx = ''
-    if table in model['documents']:
-        if table != 'ordering':
-            doc_update_name = f'''
-                {gv}, err = {gtype}Update({gv}, tx)
-                if err != nil {{
-                    return {gv}, err
-                }}
-            '''
-            doc_update_name_tx = f'''
-                {gv}.Name = fmt.Sprintf("%s-%d", {gv}.Name, {gv}.Id)
-            '''
-    created_at = ''
+    if is_numbered(table, model):
+        owner = f'{gv}.OwnerId' if 'owner_id' in keys else '0'
+        # the name is set by the row itself, Update would audit
+        # and rewrite the just created document
+        doc_update_name = f'''
+            _, err = tx.Exec("UPDATE {table} SET name=? WHERE id=?", {gv}.Name, {gv}.Id)
+            if err != nil {{
+                return {gv}, err
+            }}
+        '''
+        doc_update_name_tx = f'''
+            {gv}.Name, err = DocNumber("{table}", {gv}.Name, {gv}.Id, {owner}, {gv}.CreatedAt, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+        '''
+    hooks_before = create_go_hooks(table, model, 'create', 'before', gv)
+    hooks_after = create_go_hooks(table, model, 'create', 'after', gv)
+    link = ''
+    if 'based_on' in keys:
+        link = f'''
+        err = DocumentLinkSet("{table}", {gv}.Id, {gv}.BasedOn, tx)
+        if err != nil {{
+            return {gv}, err
+        }}
+        '''
+    created_at = f'''
+        {gv}.Version = 1'''
     if 'created_at' in keys:
-        created_at = f'''
+        created_at += f'''
         t := time.Now()
         {gv}.CreatedAt = t.Format("2006-01-02T15:04:05")
         '''
 
     g = f'''
-    func {gtype}Create({gv} {gtype}, tx *sql.Tx) ({gtype}, error) {{
+    func {gtype}Create({gv} {gtype}, tx *Tx) ({gtype}, error) {{
         var err error
         needCommit := false
 
         if tx == nil {{
-            tx, err = db.Begin()
+            tx, err = Begin()
             if err != nil {{
                 return {gv}, err
             }}
             needCommit = true
             defer tx.Rollback()
         }}
-        {reg_get}{created_at}
+        {hooks_before}{create_go_fkeys_check(table, model, gv)}{reg_get}{created_at}{create_go_period_check(table, model, gv, gv)}
         sql := `INSERT INTO {table}
             ({', '.join(list(keys)[1:])})
             VALUES({('?, '*(len(keys)-1))[:-2]});`
@@ -611,7 +1325,7 @@ def create_go_create(table, keys, model):
             return {gv}, err
         }}
         {gv}.Id = int(last_id){doc_update_name_tx}
-        {doc_update_name}
+        {link}{doc_update_name}{hooks_after}{create_go_audit(table, gv, 'create', 'nil', gv)}
         if needCommit {{
             err = tx.Commit()
             if err != nil {{
@@ -629,8 +1343,18 @@ def create_go_realized_relateds(related, gv, table_name):
     val = related['filter_value']
     gval = to_go(val)
     if related['filter'] == 'based_on':
-        filter_val = f'fmt.Sprintf("{table_name}.%d", {gv}.{gval})'
-        filter_prefix = 'Str'
+        return f'''
+    {table}_ids, err := DocumentChildIds("{table_name}", {gv}.{gval}, "{table}", tx)
+    if err != nil {{
+        return {gv}, err
+    }}
+    for _, {table}_id := range {table}_ids {{
+        _, err = {gtable}Realized({table}_id, tx)
+        if err != nil {{
+            return {gv}, err
+        }}
+    }}
+    '''
     else:
         filter_val = f'{gv}.{gval}'
         filter_prefix = 'Int'
@@ -660,7 +1384,7 @@ def create_go_realized(table, keys, model):
 
     h = f'''
         func Realized{gtype}(req Req){{
-            req.Respond({gtype}Realized(req.IntParam, nil))
+            {create_go_tx_call(gv, f'{gtype}Realized(req.IntParam, tx)', True)}
         }}
     '''
     complex_reg = ''
@@ -681,14 +1405,21 @@ def create_go_realized(table, keys, model):
             if related['table'] in model['documents'] or related['table'] in model['doc_table_items']: 
                 r = create_go_realized_relateds(related, gv, table)
                 rel_realized += r
+    movements = ''
+    if table in model['documents']:
+        movements = f'''
+        err = MovementsWrite(tx, "{table}", {gv}.Id)
+        if err != nil {{
+            return {gv}, err
+        }}'''
     
     g = f'''
-    func {gtype}Realized(id int, tx *sql.Tx) ({gtype}, error) {{
+    func {gtype}Realized(id int, tx *Tx) ({gtype}, error) {{
         var err error
         needCommit := false
         var {gv} {gtype}
         if tx == nil {{
-            tx, err = db.Begin()
+            tx, err = Begin()
             if err != nil {{
                 return {gv}, err
             }}
@@ -701,13 +1432,15 @@ def create_go_realized(table, keys, model):
             }}
             if {gv}.IsRealized {{
                 return {gv}, nil
-            }}
+            }}{create_go_period_check(table, model, gv, gv)}{create_go_hooks(table, model, 'realize', 'before', gv)}
         {complex_reg}{reg_get}{rel_realized}
-        sql := `UPDATE {table} SET is_realized=1 WHERE id=?;`
+        sql := `UPDATE {table} SET is_realized=1, version=version+1 WHERE id=?;`
         _, err = tx.Exec(sql, {gv}.Id)
         if err != nil {{
             return {gv}, err
-        }}
+        }}{movements}
+        after := {gv}
+        after.IsRealized = true{create_go_audit(table, gv, 'realize', gv, 'after')}
         if needCommit {{
             err = tx.Commit()
             if err != nil {{
@@ -743,12 +1476,12 @@ def create_go_realized_item(table, keys, model):
             rel_realized += r
     
     g = f'''
-    func {gtype}Realized(id int, tx *sql.Tx) ({gtype}, error) {{
+    func {gtype}Realized(id int, tx *Tx) ({gtype}, error) {{
         var err error
         needCommit := false
         var {gv} {gtype}
         if tx == nil {{
-            tx, err = db.Begin()
+            tx, err = Begin()
             if err != nil {{
                 return {gv}, err
             }}
@@ -809,6 +1542,7 @@ def create_go_update_registers(register, gv, table):
         apply_func += f"{reg_table}.{g_reg_field} {register['func']}= {gv}.{to_go(vf)}\n"
 
 
+    # like create and delete, skip not existing rows (e.g. contact_id 0)
     r_get = f'''
     {reg_table}, err := {g_reg_table}Get({table}.{g_reg_table}Id, tx)
     if err == nil {{
@@ -816,20 +1550,21 @@ def create_go_update_registers(register, gv, table):
     }}
 
     if {table}.{g_reg_table}Id != {gv}.{g_reg_table}Id {{
-        _, err = {g_reg_table}Update({reg_table}, tx)
-        if err != nil {{
-            return {gv}, err
+        if err == nil {{
+            _, err = {g_reg_table}Update({reg_table}, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
         }}
         {reg_table}, err = {g_reg_table}Get({gv}.{g_reg_table}Id, tx)
+    }}
+    if err == nil {{
+        {apply_func}
+        _, err = {g_reg_table}Update({reg_table}, tx)
         if err != nil {{
             return {gv}, err
         }}
     }}
-    {apply_func}
-    _, err = {g_reg_table}Update({reg_table}, tx)
-    if err != nil {{
-        return {gv}, err
-    }}
     '''
     return r_get
 
@@ -850,9 +1585,73 @@ def create_go_update(table, keys, model):
                 req.Respond(nil, err)
                 return
             }}
-            req.Respond({gtype}Update({gv}, nil))
+            {create_go_tx_call(gv, f'{gtype}UpdateVersioned({gv}, tx)')}
         }}
         '''
+    if 'add_access' in model['models'][table]:
+        h = f'''
+func Update{gtype}(req Req) {{
+    {gv}, err := Decode{gtype}(req)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    prev, err := {gtype}Get({gv}.Id, nil)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}'''
+        for field, access in model['models'][table]['add_access'].items():
+            h += f'''
+    if prev.{to_go(field)} != {gv}.{to_go(field)} && !req.HasAddAccess({access}) {{
+        req.Respond(nil, ErrAccessDenied)
+        return
+    }}'''
+        h += f'''
+    {create_go_tx_call(gv, f'{gtype}UpdateVersioned({gv}, tx)')}
+}}
+    '''
+    if is_own_doc(table, model):
+        h = f'''
+func Update{gtype}(req Req) {{
+    {gv}, err := Decode{gtype}(req)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    if req.OwnOnly() {{
+        prev, err := {gtype}Get({gv}.Id, nil)
+        if err != nil {{
+            req.Respond(nil, err)
+            return
+        }}
+        if prev.UserId != req.UserId || {gv}.UserId != req.UserId {{
+            req.Respond(nil, ErrNotOwner)
+            return
+        }}
+    }}
+    {create_go_tx_call(gv, f'{gtype}UpdateVersioned({gv}, tx)')}
+}}
+    '''
+    if own_item_parent(table, model):
+        h = f'''
+func Update{gtype}(req Req) {{
+    {gv}, err := Decode{gtype}(req)
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    err = {gtype}OwnCheck(req, {gv}.Id)
+    if err == nil {{
+        err = req.OwnDocCheck("{own_item_parent(table, model)}", {gv}.{to_go(own_item_parent(table, model))}Id)
+    }}
+    if err != nil {{
+        req.Respond(nil, err)
+        return
+    }}
+    {create_go_tx_call(gv, f'{gtype}UpdateVersioned({gv}, tx)')}
+}}
+    '''
 
     complex_reg = ''
     if 'complex_register' in model['models'][table]:
@@ -878,22 +1677,24 @@ def create_go_update(table, keys, model):
         for register in registers:
             rz_reg_get += create_go_update_registers(register, gv, table)
 
-    hooks_before = ''
-    hooks_after = ''
-    if 'hooks' in model['models'][table]:
-        hooks = model['models'][table]['hooks']
-        for hook in hooks:
-            if hook['act'] == 'update':
-                if hook['when'] == 'before':
-                    hooks_before += f"{hook['func']}(&{gv})\n"
-                else:
-                    hooks_after += f"{hook['func']}(&{gv})\n"
+    hooks_before = create_go_hooks(table, model, 'update', 'before', gv)
+    hooks_after = create_go_hooks(table, model, 'update', 'after', gv)
+    before = table
+    get_before = ''
+    if not prew:
+        before = 'before'
+        get_before = f'''before, err := {gtype}Get({gv}.Id, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            '''
     update = ''
     if 'updated_at' in keys:
         update = f'''
         t := time.Now()
         {gv}.UpdatedAt = t.Format("2006-01-02T15:04:05")
         '''
+    fields = [k for k in keys if k not in ('id', 'version')]
     realized = ''
     if table in model['documents'] and (rz_reg_get or complex_reg):
         realized = f'''
@@ -916,40 +1717,108 @@ def create_go_update(table, keys, model):
                     
                 '''
 
+    period_check = ''
+    changed = ''
+    if table in model['documents']:
+        changed = 'CreatedAt'
+    elif document_of_item(table, model):
+        changed = to_go(document_of_item(table, model)) + 'Id'
+    if changed:
+        period_check = create_go_period_check(table, model, before, gv) + f'''
+            if {gv}.{changed} != {before}.{changed} {{
+            {create_go_period_check(table, model, gv, gv)}
+            }}
+            '''
+    movements = ''
+    if 'based_on' in keys:
+        movements = f'''
+            err = DocumentLinkSet("{table}", {gv}.Id, {gv}.BasedOn, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            '''
+    if table in model['documents']:
+        movements += f'''
+            err = MovementsRewrite(tx, "{table}", {gv}.Id)
+            if err != nil {{
+                return {gv}, err
+            }}
+            '''
+    elif table in model['doc_table_items'] and (rz_reg_get or complex_reg):
+        to_table = table.split('_to_')[1]
+        gtable = to_go(to_table)
+        movements = f'''
+            err = MovementsRewrite(tx, "{to_table}", {gv}.{gtable}Id)
+            if err != nil {{
+                return {gv}, err
+            }}
+            if {before}.{gtable}Id != {gv}.{gtable}Id {{
+                err = MovementsRewrite(tx, "{to_table}", {before}.{gtable}Id)
+                if err != nil {{
+                    return {gv}, err
+                }}
+            }}
+            '''
+
+    lower = gtype[0].lower() + gtype[1:]
     g = f'''
-        func {gtype}Update({gv} {gtype}, tx *sql.Tx) ({gtype}, error) {{
-            {hooks_before}var err error
+        func {gtype}Update({gv} {gtype}, tx *Tx) ({gtype}, error) {{
+            return {lower}Update({gv}, false, tx)
+        }}
+
+        // {gtype}UpdateVersioned updates {gv} if its version is current,
+        // returns ConflictError with the current {table} otherwise
+        func {gtype}UpdateVersioned({gv} {gtype}, tx *Tx) ({gtype}, error) {{
+            return {lower}Update({gv}, true, tx)
+        }}
+
+        // {lower}Update updates the row of the version of {gv} if versioned,
+        // else of the version it has in tx
+        func {lower}Update({gv} {gtype}, versioned bool, tx *Tx) ({gtype}, error) {{
+            var err error
             needCommit := false
             if tx == nil {{
-                tx, err = db.Begin()
+                tx, err = Begin()
                 if err != nil {{
                     return {gv}, err
                 }}
                 needCommit = true
                 defer tx.Rollback()
             }}
-            {prew}
+            {get_before}{hooks_before}{prew}version := {before}.Version
+            if versioned && {gv}.Version != version {{
+                return {gv}, ConflictError{{{before}}}
+            }}
+            {period_check}{create_go_fkeys_check(table, model, gv, before)}
             {reg_get}{update}
             {realized}
-            sql := `UPDATE {table} SET
-                    {'=?, '.join(list(keys)[1:])}=?
-                    WHERE id=?;`
+            query := `UPDATE {table} SET
+                    {'=?, '.join(fields)}=?, version=version+1
+                    WHERE id=? AND version=? RETURNING version;`
 
-            _, err = tx.Exec(
-                    sql,
-                {list_of_vars(list(keys)[1:], gv)}
+            err = tx.QueryRow(
+                    query,
+                {list_of_vars(fields, gv)}
                     {gv}.Id,
-                )
+                    version,
+                ).Scan(&{gv}.Version)
+            if err == sql.ErrNoRows {{
+                current, err := {gtype}Get({gv}.Id, tx)
+                if err != nil {{
+                    return {gv}, err
+                }}
+                return {gv}, ConflictError{{current}}
+            }}
             if err != nil {{
                 return {gv}, err
             }}
-            if needCommit {{
+            {movements}{hooks_after}{create_go_audit(table, gv, 'update', before, gv)}if needCommit {{
                 err = tx.Commit()
                 if err != nil {{
                     return {gv}, err
                 }}
             }}
-            {hooks_after}return {gv}, nil
+            return {gv}, nil
         }}
         '''
     return g, h, m
@@ -966,13 +1835,24 @@ def create_go_delete_complex_registers(register, gv, table):
 
 
 def create_go_delete_relateds(related, gv, table):
+    parent = table
     table = related['table']
     gtable = to_go(table)
     val = related['filter_value']
     gval = to_go(val)
     if related['filter'] == 'based_on':
-        filter_val = f'fmt.Sprintf("{table}.%d", {gv}.{gval})'
-        filter_prefix = 'Str'
+        return f'''
+        {table}_ids, err := DocumentChildIds("{parent}", {gv}.{gval}, "{table}", tx)
+        if err != nil {{
+            return {gv}, err
+        }}
+        for _, {table}_id := range {table}_ids {{
+            _, err = {gtable}Delete({table}_id, tx, isUnRealize)
+            if err != nil {{
+                return {gv}, err
+            }}
+        }}
+    '''
     else:
         filter_val = f'{gv}.{gval}'
         filter_prefix = 'Int'
@@ -1015,6 +1895,8 @@ def create_go_delete_registers(register, gv):
 
 
 def create_go_delete(table, keys, model):
+    if not is_deletable(table, model):
+        return '', '', ''
     right = model['models'][table]['rights'] + '_DELETE'
     gtype = to_go(table)
     gv = table[0]
@@ -1022,7 +1904,7 @@ def create_go_delete(table, keys, model):
     h = ''
     check_unrealize = f'''
             if !isUnRealize {{
-                sql := `UPDATE {table} SET is_active=0 WHERE id=?;`
+                sql := `UPDATE {table} SET is_active=0, version=version+1 WHERE id=?;`
                 _, err = tx.Exec(sql, {gv}.Id)
                 if err != nil {{
                     return {gv}, err
@@ -1032,18 +1914,18 @@ def create_go_delete(table, keys, model):
     if table in model['documents']:
         m += f'''
             r.HandleFunc("/unrealize/{table}/{{id:[0-9]+}}",
-                WrapAuth(UnRealize{gtype}, {right})).Methods("GET")
+                WrapAuthAdd(UnRealize{gtype}, {right}, ADD_UNREALIZE)).Methods("GET")
         '''
 
         h += f'''
             func UnRealize{gtype}(req Req) {{
-                req.Respond({gtype}Delete(req.IntParam, nil, true))
+                {create_go_tx_call(gv, f'{gtype}Delete(req.IntParam, tx, true)', True)}
             }}
             '''    
         check_unrealize = f'''
-            sql := `UPDATE {table} SET is_active=0 WHERE id=?;`
+            sql := `UPDATE {table} SET is_active=0, version=version+1 WHERE id=?;`
             if isUnRealize {{
-                sql = `UPDATE {table} SET is_realized=0 WHERE id=?;`
+                sql = `UPDATE {table} SET is_realized=0, version=version+1 WHERE id=?;`
             }} 
             _, err = tx.Exec(sql, {gv}.Id)
             if err != nil {{
@@ -1057,9 +1939,34 @@ def create_go_delete(table, keys, model):
             WrapAuth(Delete{gtype}, {right})).Methods("DELETE")
     '''
 
-    h += f'''
+    references = ''
+    if is_referenced_catalog(table, model):
+        h += f'''
         func Delete{gtype}(req Req) {{
-            req.Respond({gtype}Delete(req.IntParam, nil, false))
+            tx, err := req.Begin()
+            if err != nil {{
+                req.Respond(nil, err)
+                return
+            }}
+            err = ReferencesResolve("{table}", req.IntParam, req.R.URL.Query(), tx)
+            if err != nil {{
+                req.RespondTx(tx, nil, err)
+                return
+            }}
+            {gv}, err := {gtype}Delete(req.IntParam, tx, false)
+            req.RespondTx(tx, {gv}, err)
+        }}
+        '''
+        references = f'''
+            err = ReferencesCheck("{table}", {gv}.Id, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            '''
+    else:
+        h += f'''
+        func Delete{gtype}(req Req) {{{create_go_own_item_check_call(table, model, gtype)}
+            {create_go_tx_call(gv, f'{gtype}Delete(req.IntParam, tx, false)', True)}
         }}
         '''
     complex_reg = ''
@@ -1072,16 +1979,38 @@ def create_go_delete(table, keys, model):
     if 'register' in model['models'][table]:
         registers = model['models'][table]['register']
         for register in registers:
-            if not register['func']: #register = last value, no need to delete
-                return '', '', ''
             reg_get += create_go_delete_registers(register, gv)
     rz_reg_get = ''
     if 'rz_register' in model['models'][table]:
         registers = model['models'][table]['rz_register']
         for register in registers:
-            if not register['func']: #register = last value, no need to delete
-                return '', '', ''
             rz_reg_get += create_go_delete_registers(register, gv)
+    parent = doc_of_item(table, model)
+    if table in model['documents']:
+        rz_reg_get = f'''
+            if {gv}.IsRealized {{
+            {rz_reg_get}
+                err = MovementsDelete(tx, "{table}", {gv}.Id)
+                if err != nil {{
+                    return {gv}, err
+                }}
+            }}
+            '''
+    elif parent and (rz_reg_get or complex_reg):
+        rz_reg_get = f'''
+            {parent[:3]}, err := {to_go(parent)}Get({gv}.{to_go(parent)}Id, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            if {parent[:3]}.IsRealized {{
+            {complex_reg}{rz_reg_get}
+                err = MovementsDeleteSource(tx, "{table}", {gv}.Id)
+                if err != nil {{
+                    return {gv}, err
+                }}
+            }}
+            '''
+        complex_reg = ''
     rel_delete = ''
     if 'related' in model['models'][table]:
         relateds = model['models'][table]['related']
@@ -1092,10 +2021,22 @@ def create_go_delete(table, keys, model):
             r = create_go_delete_relateds(related, gv, table)
             rel_delete += r
 
+    registers = complex_reg + reg_get + rz_reg_get
+    if table in model['documents'] and rel_delete:
+        # items update sums of the document with its registers,
+        # the document undoes what remains after them
+        registers = f'''{rel_delete}
+            {gv}, err = {gtype}Get(id, tx)
+            if err != nil {{
+                return {gv}, err
+            }}
+            {registers}'''
+        rel_delete = ''
+
     if table in model['doc_table_items'] or model == 'item_to_invoice':
         check_unrealize = f'''
             if !isUnRealize {{
-                sql := `UPDATE {table} SET is_active=0 WHERE id=?;`
+                sql := `UPDATE {table} SET is_active=0, version=version+1 WHERE id=?;`
                 _, err = tx.Exec(sql, {gv}.Id)
                 if err != nil {{
                     return {gv}, err
@@ -1110,13 +2051,35 @@ def create_go_delete(table, keys, model):
                 }}
                 '''
 
+    audit = f'''
+            if !isUnRealize {{
+                after := {gv}
+                after.IsActive = false{create_go_audit(table, gv, 'delete', gv, 'after')}
+            }}
+            '''
+    if table in model['documents']:
+        audit = f'''
+            after := {gv}
+            action := "delete"
+            if isUnRealize {{
+                after.IsRealized = false
+                action = "unrealize"
+            }} else {{
+                after.IsActive = false
+            }}
+            err = Audit(tx, "{table}", {gv}.Id, action, {gv}, after)
+            if err != nil {{
+                return {gv}, err
+            }}
+            '''
+
     g = f'''
-        func {gtype}Delete(id int, tx *sql.Tx, isUnRealize bool) ({gtype}, error) {{
+        func {gtype}Delete(id int, tx *Tx, isUnRealize bool) ({gtype}, error) {{
             needCommit := false
             var err error
             var {gv} {gtype}
             if tx == nil {{
-                tx, err = db.Begin()
+                tx, err = Begin()
                 if err != nil {{
                     return {gv}, err
                 }}
@@ -1126,9 +2089,9 @@ def create_go_delete(table, keys, model):
             {gv}, err = {gtype}Get(id, tx)
             if err != nil {{
                 return {gv}, err
-            }}
-            {complex_reg}{reg_get}{rz_reg_get}{rel_delete}
-            {check_unrealize}
+            }}{create_go_period_check(table, model, gv, gv)}{references}
+            {registers}{rel_delete}
+            {check_unrealize}{audit}
             if needCommit {{
                 err = tx.Commit()
                 if err != nil {{
@@ -1152,18 +2115,14 @@ def create_go_filter_int(table, keys, model):
             WrapAuth(Get{gtype}ByFilterInt, {right})).Methods("GET")
         '''
 
-    h = f'''
-        func Get{gtype}ByFilterInt(req Req) {{
-            req.Respond({gtype}GetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
-        }}
-        '''
+    h = create_go_list_handler(f'Get{gtype}ByFilterInt', f'{gtype}GetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)', gv, gtype, table, model)
 
     g = f'''
-        func {gtype}GetByFilterInt(field string, param int, withDeleted bool, deletedOnly bool, tx *sql.Tx) ([]{gtype}, error) {{
+        func {gtype}GetByFilterInt(field string, param int, withDeleted bool, deletedOnly bool, tx *Tx) ([]{gtype}, error) {{
             {create_go_filter(table, keys, gv, gtype)}
         }}
         '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 
 def create_go_filter_str(table, keys, model):
@@ -1176,18 +2135,14 @@ def create_go_filter_str(table, keys, model):
             WrapAuth(Get{gtype}ByFilterStr, {right})).Methods("GET")
         '''
 
-    h = f'''
-        func Get{gtype}ByFilterStr(req Req) {{
-            req.Respond({gtype}GetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
-        }}
-        '''
+    h = create_go_list_handler(f'Get{gtype}ByFilterStr', f'{gtype}GetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)', gv, gtype, table, model)
 
     g = f'''
-        func {gtype}GetByFilterStr(field string, param string, withDeleted bool, deletedOnly bool, tx *sql.Tx) ([]{gtype}, error) {{
+        func {gtype}GetByFilterStr(field string, param string, withDeleted bool, deletedOnly bool, tx *Tx) ([]{gtype}, error) {{
             {create_go_filter(table, keys, gv, gtype)}
         }}
         '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 def make_field_validation(table, keys):
     gtype = to_go(table)
@@ -1210,8 +2165,8 @@ def make_field_validation(table, keys):
 
 def create_go_filter(table, keys, gv, gtype):
     f = f'''
-    if !{gtype}TestForExistingField(field) {{
-        return nil, errors.New("field not exist")
+    if !ListField(field, {gtype}TestForExistingField) {{
+        return nil, ListQueryError{{"field not exist " + field}}
     }}
     var err error
     query := fmt.Sprintf("SELECT * FROM {table} WHERE %s=?", field)
@@ -1257,8 +2212,21 @@ def create_go_sum_before(table, keys, sum_field, model):
             WrapAuth(Get{gtype}{gsum_field}SumBefore, {right})).Methods("GET")
     '''
 
+    own_check = ''
+    if is_own_doc(table, model):
+        own_check = '''
+    if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+        req.Respond(nil, ErrNotOwner)
+        return
+    }'''
+    elif own_item_parent(table, model):
+        own_check = f'''
+    if err := req.OwnDocFilter("{own_item_parent(table, model)}", req.StrParam, req.IntParam); err != nil {{
+        req.Respond(nil, err)
+        return
+    }}'''
     h = f'''
-func Get{gtype}{gsum_field}SumBefore(req Req) {{
+func Get{gtype}{gsum_field}SumBefore(req Req) {{{own_check}
     req.Respond({gtype}{gsum_field}GetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }}
     '''
@@ -1292,8 +2260,24 @@ def create_go_sum_filter(table, keys, sum_field, model):
             WrapAuth(Get{gtype}SumByFilter, {right})).Methods("GET")
     '''
 
+    own_check = ''
+    if is_own_doc(table, model):
+        own_check = '''
+    if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+        !(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+        req.Respond(nil, ErrNotOwner)
+        return
+    }'''
+    elif own_item_parent(table, model):
+        parent = own_item_parent(table, model)
+        own_check = f'''
+    if err := req.OwnDocFilter("{parent}", req.StrParam, req.IntParam); err != nil &&
+        req.OwnDocFilter("{parent}", req.Str2Param, req.Int2Param) != nil {{
+        req.Respond(nil, err)
+        return
+    }}'''
     h = f'''
-func Get{gtype}SumByFilter(req Req) {{
+func Get{gtype}SumByFilter(req Req) {{{own_check}
     req.Respond({gtype}GetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }}
     '''
@@ -1343,11 +2327,7 @@ def create_go_between(table, keys, between, model):
             WrapAuth(Get{gtype}Between{gbetween}, {right})).Methods("GET")
     '''
 
-    h = f'''
-func Get{gtype}Between{gbetween}(req Req) {{
-    req.Respond({gtype}GetBetween{gbetween}({between_param1}, {between_param2}, req.WithDeleted, req.DeletedOnly))
-}}
-    '''
+    h = create_go_list_handler(f'Get{gtype}Between{gbetween}', f'{gtype}GetBetween{gbetween}({between_param1}, {between_param2}, req.WithDeleted, req.DeletedOnly)', gv, gtype, table, model)
 
     g = f'''
 
@@ -1377,7 +2357,7 @@ func {gtype}GetBetween{gbetween}({between}1, {between}2 {between_type}, withDele
     return res, nil
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 def create_joins(table, find):
     if len(find.keys()) == 1:
@@ -1499,11 +2479,7 @@ def create_go_find(table, keys, find, model):
             WrapAuth(Get{gtype}FindBy{gfind}, {right})).Methods("GET")
     '''
 
-    h = f'''
-func Get{gtype}FindBy{gfind}(req Req) {{
-    req.Respond({gtype}FindBy{gfind}(req.StrParam))
-}}
-    '''
+    h = create_go_list_handler(f'Get{gtype}FindBy{gfind}', f'{gtype}FindBy{gfind}(req.StrParam)', gv, gtype, table, model)
 
     func_name = f'{gtype}FindBy{gfind}'
     find_query = finds[func_name]
@@ -1535,7 +2511,7 @@ func {func_name}(fs string) ([]{gtype}, error) {{
     return res, nil
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 
 # -------------------------WWWWWWWWWWWWWWWWWW-----------------------------
@@ -1609,6 +2585,10 @@ func GetW{gtype}(req Req) {{
     req.Respond(W{gtype}Get(req.IntParam))
 }}
     '''
+    if is_own_doc(table, model):
+        h = create_go_own_get_handler(f'GetW{gtype}', f'W{gtype}Get(req.IntParam)', gv)
+    elif own_item_parent(table, model):
+        h = create_go_own_get_handler(f'GetW{gtype}', f'W{gtype}Get(req.IntParam)', gv, own_item_parent(table, model))
     add_sel, add_join = create_add_joins(table, keys)
 
     g = f'''
@@ -1635,11 +2615,8 @@ def create_go_gets_w(table, keys, model):
             WrapAuth(GetW{gtype}All, {right})).Methods("GET")
     '''
 
-    h = f'''
-func GetW{gtype}All(req Req) {{
-    req.Respond(W{gtype}GetAll(req.WithDeleted, req.DeletedOnly))
-}}
-    '''
+    head = create_go_list_query(table, model, f'W{gtype}GetByQuery(q)')
+    h = create_go_list_handler(f'GetW{gtype}All', f'W{gtype}GetAll(req.WithDeleted, req.DeletedOnly)', gv, 'W' + gtype, table, model, head)
     add_sel, add_join = create_add_joins(table, keys)
     g = f'''
 
@@ -1668,8 +2645,42 @@ func W{gtype}GetAll(withDeleted bool, deletedOnly bool) ([]W{gtype}, error) {{
     }}
     return res, nil
 }}
+
+// W{gtype}GetByQuery returns the page of the list and the number of all found rows
+func W{gtype}GetByQuery(q ListQuery) ([]W{gtype}, int, error) {{
+    where, args, err := q.Where("{table}", {gtype}TestForExistingField)
+    if err != nil {{
+        return nil, 0, err
+    }}
+    tail, err := q.Tail("{table}", {gtype}TestForExistingField)
+    if err != nil {{
+        return nil, 0, err
+    }}
+    var total int
+    err = db.QueryRow("SELECT count(*) FROM {table}"+where, args...).Scan(&total)
+    if err != nil {{
+        return nil, 0, err
+    }}
+    query := `SELECT {table}.*{add_sel} FROM {table}{add_join}`
+    rows, err := db.Query(query+where+tail, args...)
+    if err != nil {{
+        return nil, 0, err
+    }}
+    defer rows.Close()
+    res := []W{gtype}{{}}
+    for rows.Next() {{
+        var {gv} W{gtype}
+        if err := rows.Scan(
+    {list_of_pointers_w(keys, gv)}
+        ); err != nil {{
+            return nil, 0, err
+        }}
+        res = append(res, {gv})
+    }}
+    return res, total, nil
+}}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 
 def create_go_between_w(table, keys, between, model):
@@ -1696,11 +2707,7 @@ def create_go_between_w(table, keys, between, model):
             WrapAuth(GetW{gtype}Between{gbetween}, {right})).Methods("GET")
     '''
 
-    h = f'''
-func GetW{gtype}Between{gbetween}(req Req) {{
-    req.Respond(W{gtype}GetBetween{gbetween}({between_param1}, {between_param2}, req.WithDeleted, req.DeletedOnly))
-}}
-    '''
+    h = create_go_list_handler(f'GetW{gtype}Between{gbetween}', f'W{gtype}GetBetween{gbetween}({between_param1}, {between_param2}, req.WithDeleted, req.DeletedOnly)', gv, 'W' + gtype, table, model)
     add_sel, add_join = create_add_joins(table, keys)
     g = f'''
 
@@ -1730,7 +2737,7 @@ func W{gtype}GetBetween{gbetween}({between}1, {between}2 {between_type}, withDel
     return res, nil
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 
 def create_go_between_up_w(table, keys, between_up, model):
@@ -1758,11 +2765,7 @@ def create_go_between_up_w(table, keys, between_up, model):
             WrapAuth(GetW{gtype}BetweenUp{gbetween}, {right})).Methods("GET")
     '''
 
-    h = f'''
-func GetW{gtype}BetweenUp{gbetween}(req Req) {{
-    req.Respond(W{gtype}GetBetweenUp{gbetween}({between_param1}, {between_param2}, req.WithDeleted, req.DeletedOnly))
-}}
-    '''
+    h = create_go_list_handler(f'GetW{gtype}BetweenUp{gbetween}', f'W{gtype}GetBetweenUp{gbetween}({between_param1}, {between_param2}, req.WithDeleted, req.DeletedOnly)', gv, 'W' + gtype, table, model)
     add_sel, add_join = create_add_joins(table, keys)
     g = f'''
 
@@ -1793,7 +2796,7 @@ func W{gtype}GetBetweenUp{gbetween}({between}1, {between}2 {between_type}, withD
     return res, nil
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 
 
@@ -1807,11 +2810,7 @@ def create_go_filter_w_int(table, keys, model):
             WrapAuth(GetW{gtype}ByFilterInt, {right})).Methods("GET")
     '''
 
-    h = f'''
-func GetW{gtype}ByFilterInt(req Req) {{
-    req.Respond(W{gtype}GetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
-}}
-    '''
+    h = create_go_list_handler(f'GetW{gtype}ByFilterInt', f'W{gtype}GetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)', gv, 'W' + gtype, table, model)
     add_sel, add_join = create_add_joins(table, keys)
     g = f'''
 
@@ -1819,7 +2818,7 @@ func W{gtype}GetByFilterInt(field string, param int, withDeleted bool, deletedOn
     {create_go_filter_w(table, keys, gv, gtype)}
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 def create_go_filter_w_str(table, keys, model):
     right = model['models'][table]['rights'] + '_READ'
@@ -1831,11 +2830,7 @@ def create_go_filter_w_str(table, keys, model):
             WrapAuth(GetW{gtype}ByFilterStr, {right})).Methods("GET")
     '''
 
-    h = f'''
-func GetW{gtype}ByFilterStr(req Req) {{
-    req.Respond(W{gtype}GetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
-}}
-    '''
+    h = create_go_list_handler(f'GetW{gtype}ByFilterStr', f'W{gtype}GetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)', gv, 'W' + gtype, table, model)
     add_sel, add_join = create_add_joins(table, keys)
     g = f'''
 
@@ -1843,13 +2838,13 @@ func W{gtype}GetByFilterStr(field string, param string, withDeleted bool, delete
     {create_go_filter_w(table, keys, gv, gtype)}
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 def create_go_filter_w(table, keys, gv, gtype):
     add_sel, add_join = create_add_joins(table, keys)
     f = f'''
-    if !{gtype}TestForExistingField(field) {{
-        return nil, errors.New("field not exist")
+    if !ListField(field, {gtype}TestForExistingField) {{
+        return nil, ListQueryError{{"field not exist " + field}}
     }}
     query := fmt.Sprintf(`SELECT {table}.*{add_sel} FROM {table}{add_join} WHERE {table}.%s=?`, field)
     if deletedOnly {{
@@ -1915,11 +2910,7 @@ def create_go_find_w(table, keys, find, model):
             WrapAuth(GetW{gtype}FindBy{gfind}, {right})).Methods("GET")
     '''
 
-    h = f'''
-func GetW{gtype}FindBy{gfind}(req Req) {{
-    req.Respond(W{gtype}FindBy{gfind}(req.StrParam))
-}}
-    '''
+    h = create_go_list_handler(f'GetW{gtype}FindBy{gfind}', f'W{gtype}FindBy{gfind}(req.StrParam)', gv, 'W' + gtype, table, model)
     add_sel, add_join = create_add_joins_for_find(table, keys, [find.keys()])
     func_name = f'W{gtype}FindBy{gfind}'
     find_query = finds[func_name]
@@ -1950,7 +2941,7 @@ func {func_name}(fs string) ([]W{gtype}, error) {{
     return res, nil
 }}
 '''
-    return g, h, m
+    return create_go_each(g), h, m
 
 if __name__ == '__main__':
     with open ('models.json', "r") as f:
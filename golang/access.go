@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// additional access constants, bits of User.AddAccess
+// bits 0..15 of add_access were filled with the default base mask (1171)
+// by older versions, so the registry starts from bit 16.
+// ADD_CHANGE_PRICE guards fields listed in "add_access" of models.json,
+// ADD_SEE_PROFIT is checked by the client to show profit columns,
+// ADD_CLOSED_PERIOD allows to change documents of closed periods
+const (
+	ADD_UNREALIZE = 1 << (iota + 16)
+	ADD_RESTORE_BASE
+	ADD_CHANGE_PRICE
+	ADD_SEE_PROFIT
+	ADD_CLOSED_PERIOD
+)
+
+var ErrAccessDenied = errors.New("access denied")
+
+type AccessName struct {
+	Bit  uint64 `json:"bit"`
+	Name string `json:"name"`
+	Hum  string `json:"hum"`
+}
+
+var baseAccessNames = []AccessName{
+	{LOGOUT, "LOGOUT", "Вхід в систему"},
+	{USER_READ, "USER_READ", "Користувачі: перегляд"},
+	{USER_CREATE, "USER_CREATE", "Користувачі: створення"},
+	{USER_UPDATE, "USER_UPDATE", "Користувачі: зміна"},
+	{USER_DELETE, "USER_DELETE", "Користувачі: видалення"},
+	{CONTRAGENT_READ, "CONTRAGENT_READ", "Контрагенти: перегляд"},
+	{CONTRAGENT_CREATE, "CONTRAGENT_CREATE", "Контрагенти: створення"},
+	{CONTRAGENT_UPDATE, "CONTRAGENT_UPDATE", "Контрагенти: зміна"},
+	{CONTRAGENT_DELETE, "CONTRAGENT_DELETE", "Контрагенти: видалення"},
+	{DOC_READ, "DOC_READ", "Документи: перегляд"},
+	{DOC_OWNREAD, "DOC_OWNREAD", "Документи: перегляд власних"},
+	{DOC_CREATE, "DOC_CREATE", "Документи: створення"},
+	{DOC_UPDATE, "DOC_UPDATE", "Документи: зміна"},
+	{DOC_OWNUPDATE, "DOC_OWNUPDATE", "Документи: зміна власних"},
+	{DOC_DELETE, "DOC_DELETE", "Документи: видалення"},
+	{CATALOG_READ, "CATALOG_READ", "Довідники: перегляд"},
+	{CATALOG_CREATE, "CATALOG_CREATE", "Довідники: створення"},
+	{CATALOG_UPDATE, "CATALOG_UPDATE", "Довідники: зміна"},
+	{CATALOG_DELETE, "CATALOG_DELETE", "Довідники: видалення"},
+	{OWNER_READ, "OWNER_READ", "Власник: перегляд"},
+	{OWNER_CREATE, "OWNER_CREATE", "Власник: створення"},
+	{OWNER_UPDATE, "OWNER_UPDATE", "Власник: зміна"},
+	{OWNER_DELETE, "OWNER_DELETE", "Власник: видалення"},
+	{WS_CONNECT, "WS_CONNECT", "Чат"},
+	{ADMIN, "ADMIN", "Адміністрування"},
+}
+
+var addAccessNames = []AccessName{
+	{ADD_UNREALIZE, "ADD_UNREALIZE", "Скасування проведення документів"},
+	{ADD_RESTORE_BASE, "ADD_RESTORE_BASE", "Відновлення бази з резервної копії"},
+	{ADD_CHANGE_PRICE, "ADD_CHANGE_PRICE", "Зміна цін"},
+	{ADD_SEE_PROFIT, "ADD_SEE_PROFIT", "Перегляд прибутку"},
+	{ADD_CLOSED_PERIOD, "ADD_CLOSED_PERIOD", "Зміна документів закритого періоду"},
+}
+
+// WrapAuthAdd works as WrapAuth and also requires addAccess bit in User.AddAccess
+func WrapAuthAdd(handler func(req Req), access uint64, addAccess uint64) func(w http.ResponseWriter, r *http.Request) {
+	return WrapAuth(func(req Req) {
+		if !req.HasAddAccess(addAccess) {
+			log.Print("try to do some without additional access", addAccess)
+			req.Respond(nil, ErrAccessDenied)
+			return
+		}
+		handler(req)
+	}, access)
+}
+
+func GetAccessNames(r Req) {
+	r.Respond(map[string][]AccessName{
+		"base_access": baseAccessNames,
+		"add_access":  addAccessNames,
+	}, nil)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/securecookie"
+)
+
+// Audit trail: generated Create, Update, Delete and Realized functions
+// write the changed fields into audit_log in their transaction.
+// Records get the user and batch_id of their Tx, Req.Begin gives it
+// the user of the request, all records of one transaction share batch_id.
+
+type AuditLog struct {
+	Id        int             `json:"id"`
+	BatchId   string          `json:"batch_id"`
+	UserId    int             `json:"user_id"`
+	Entity    string          `json:"entity"`
+	EntityId  int             `json:"entity_id"`
+	Action    string          `json:"action"`
+	Diff      json.RawMessage `json:"diff"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type AuditDiff struct {
+	Before map[string]interface{} `json:"before"`
+	After  map[string]interface{} `json:"after"`
+}
+
+// Tx is the transaction with the request which started it:
+// audit records written in it get its user and batch,
+// guards of documents check its access
+type Tx struct {
+	*sql.Tx
+	UserId    int
+	BatchId   string
+	AddAccess uint64
+	transit   bool // ordering is changed by OrderingTransit
+}
+
+func newBatchId() string {
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(10)), "=")
+}
+
+// Begin starts the transaction without a request, its audit records get user 0
+func Begin() (*Tx, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, BatchId: newBatchId()}, nil
+}
+
+// Begin starts the transaction of the request,
+// audit records written in it get the user of the request
+func (r Req) Begin() (*Tx, error) {
+	tx, err := Begin()
+	if err != nil {
+		return nil, err
+	}
+	tx.UserId = r.UserId
+	tx.AddAccess = r.AddAccess
+	return tx, nil
+}
+
+// RespondTx commits tx if there is no error, rolls it back otherwise, and responds
+func (r Req) RespondTx(tx *Tx, payload interface{}, err error) {
+	if err != nil {
+		tx.Rollback()
+		r.Respond(nil, err)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	r.Respond(payload, nil)
+}
+
+func toFields(v interface{}) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	if v == nil {
+		return res, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// Audit writes changed fields of the entity into audit_log in tx,
+// before is nil for created entity
+func Audit(tx *Tx, entity string, id int, action string, before, after interface{}) error {
+	b, err := toFields(before)
+	if err != nil {
+		return err
+	}
+	a, err := toFields(after)
+	if err != nil {
+		return err
+	}
+	diff := AuditDiff{map[string]interface{}{}, map[string]interface{}{}}
+	for k, v := range a {
+		bv, ok := b[k]
+		if !ok || !reflect.DeepEqual(bv, v) {
+			if ok {
+				diff.Before[k] = bv
+			}
+			diff.After[k] = v
+		}
+	}
+	if len(diff.After) == 0 && action == "update" {
+		return nil
+	}
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO audit_log (batch_id, user_id, entity, entity_id, action, diff, created_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		tx.BatchId, tx.UserId, entity, id, action, string(data), time.Now().Format("2006-01-02T15:04:05"))
+	return err
+}
+
+type AuditFilter struct {
+	Entity    string
+	EntityId  int
+	UserId    int
+	Action    string
+	BatchId   string
+	CreatedAt string
+	CreatedTo string
+	Limit     int
+}
+
+func AuditLogGetByFilter(f AuditFilter) ([]AuditLog, error) {
+	query := "SELECT id, batch_id, user_id, entity, entity_id, action, diff, created_at FROM audit_log WHERE 1=1"
+	args := []interface{}{}
+	if f.Entity != "" {
+		query += " AND entity = ?"
+		args = append(args, f.Entity)
+	}
+	if f.EntityId != 0 {
+		query += " AND entity_id = ?"
+		args = append(args, f.EntityId)
+	}
+	if f.UserId != 0 {
+		query += " AND user_id = ?"
+		args = append(args, f.UserId)
+	}
+	if f.Action != "" {
+		query += " AND action = ?"
+		args = append(args, f.Action)
+	}
+	if f.BatchId != "" {
+		query += " AND batch_id = ?"
+		args = append(args, f.BatchId)
+	}
+	if f.CreatedAt != "" {
+		query += " AND created_at >= ?"
+		args = append(args, f.CreatedAt)
+	}
+	if f.CreatedTo != "" {
+		query += " AND created_at <= ?"
+		args = append(args, f.CreatedTo)
+	}
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(f.Limit)
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []AuditLog{}
+	for rows.Next() {
+		var a AuditLog
+		var diff string
+		if err := rows.Scan(&a.Id, &a.BatchId, &a.UserId, &a.Entity, &a.EntityId, &a.Action, &diff, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Diff = json.RawMessage(diff)
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// GetAuditLog filters audit_log by query parameters
+// entity, entity_id, user_id, action, batch_id, from, to (dates) and limit
+func GetAuditLog(r Req) {
+	q := r.R.URL.Query()
+	f := AuditFilter{
+		Entity:    q.Get("entity"),
+		Action:    q.Get("action"),
+		BatchId:   q.Get("batch_id"),
+		CreatedAt: q.Get("from"),
+		CreatedTo: q.Get("to"),
+	}
+	var err error
+	for name, p := range map[string]*int{"entity_id": &f.EntityId, "user_id": &f.UserId, "limit": &f.Limit} {
+		if v := q.Get(name); v != "" {
+			*p, err = strconv.Atoi(v)
+			if err != nil {
+				r.Respond(nil, errors.New("invalid integer parameter "+name))
+				return
+			}
+		}
+	}
+	r.Respond(AuditLogGetByFilter(f))
+}
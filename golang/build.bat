@@ -1 +1 @@
-go build -ldflags="-H windowsgui"
\ No newline at end of file
+go build -tags sqlite_fts5 -ldflags="-H windowsgui"
\ No newline at end of file
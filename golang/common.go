@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -12,12 +13,15 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
 	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // withDeleted http://127.0.0.1:8080/product_get_all?all
@@ -53,11 +57,40 @@ const (
 )
 
 var clients = make(map[*websocket.Conn]WsClient)
+var clientsMu sync.Mutex
 var broadcast = make(chan Message)
 
 type WsClient struct {
-	UserId   int    `json:"user_id"`
-	Username string `json:"username"`
+	UserId    int    `json:"user_id"`
+	Username  string `json:"username"`
+	SessionId string `json:"-"`
+}
+
+// WsSessions returns ids of sessions with connected websocket
+func WsSessions() map[string]bool {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	res := map[string]bool{}
+	for _, c := range clients {
+		if c.SessionId != "" {
+			res[c.SessionId] = true
+		}
+	}
+	return res
+}
+
+// WsCloseSessions closes websockets of the sessions,
+// the reading loop of the connection removes the client
+func WsCloseSessions(ids []string) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	for wsConn, c := range clients {
+		for _, id := range ids {
+			if c.SessionId == id {
+				wsConn.Close()
+			}
+		}
+	}
 }
 
 type Message struct {
@@ -89,10 +122,15 @@ func UpgradeWS(r Req) {
 		fmt.Println(err)
 		return
 	}
-	clients[wsConn] = WsClient{userid, username}
+	client := WsClient{UserId: userid, Username: username}
+	if sess, err := Store.Get(r.R, "sess"); err == nil {
+		client.SessionId = sess.ID
+	}
+	clientsMu.Lock()
+	clients[wsConn] = client
+	clientsMu.Unlock()
 	// clients[wsConn] = r.UserId
 	fmt.Println("Connected", userid)
-	fmt.Println("Clients", clients)
 	// Read messages from the client.
 
 	for {
@@ -100,9 +138,11 @@ func UpgradeWS(r Req) {
 		err := wsConn.ReadJSON(&msg)
 		fmt.Println("'userid'", userid)
 		if err != nil {
-			fmt.Println("OnReadMessage>>", clients[wsConn], err)
-			msg = Message{clients[wsConn].UserId, clients[wsConn].Username, "виходить з чату"}
+			fmt.Println("OnReadMessage>>", client.UserId, client.Username, err)
+			msg = Message{client.UserId, client.Username, "виходить з чату"}
+			clientsMu.Lock()
 			delete(clients, wsConn)
+			clientsMu.Unlock()
 			broadcast <- msg
 			return
 		}
@@ -115,19 +155,18 @@ func handleMessages() {
 	for {
 		msg := <-broadcast
 		fmt.Printf("%s >> %s\n", msg.Username, msg.Message)
-		fmt.Println("OnWriteMessage>>", clients)
-		for wsConn := range clients {
-			if msg.UserId != clients[wsConn].UserId {
+		clientsMu.Lock()
+		for wsConn, c := range clients {
+			if msg.UserId != c.UserId {
 				err := wsConn.WriteJSON(msg)
 				if err != nil {
-					fmt.Println("OnWriteMessage>>", clients[wsConn], err)
+					// the reading loop of the connection fails too and sends the leaving message
+					fmt.Println("OnWriteMessage>>", c.UserId, c.Username, err)
 					wsConn.Close()
-					msg = Message{clients[wsConn].UserId, clients[wsConn].Username, "виходить з чату"}
-					delete(clients, wsConn)
-					broadcast <- msg
 				}
 			}
 		}
+		clientsMu.Unlock()
 	}
 }
 
@@ -135,6 +174,9 @@ type Req struct {
 	W           http.ResponseWriter
 	R           *http.Request
 	UserId      int
+	Granted     uint64
+	BaseAccess  uint64
+	AddAccess   uint64
 	IntParam    int
 	StrParam    string
 	Int2Param   int
@@ -143,6 +185,85 @@ type Req struct {
 	DeletedOnly bool
 }
 
+// OwnOnly reports whether the request was allowed only by DOC_OWNREAD
+// or DOC_OWNUPDATE, so it may touch only documents with user_id = UserId
+func (r Req) OwnOnly() bool {
+	return r.Granted == DOC_OWNREAD || r.Granted == DOC_OWNUPDATE
+}
+
+// HasAddAccess reports whether the caller has the additional access bit
+func (r Req) HasAddAccess(accessMask uint64) bool {
+	return r.AddAccess&accessMask != 0
+}
+
+var ErrNotOwner = errors.New("access denied: document belongs to another user")
+
+// OwnDocCheck returns ErrNotOwner if the request is own-only and the document
+// of the table belongs to another user
+func (r Req) OwnDocCheck(table string, id int) error {
+	if !r.OwnOnly() {
+		return nil
+	}
+	var userId int
+	err := db.QueryRow("SELECT user_id FROM "+table+" WHERE id=?", id).Scan(&userId)
+	if err != nil {
+		return err
+	}
+	if userId != r.UserId {
+		return ErrNotOwner
+	}
+	return nil
+}
+
+// OwnDocFilter returns ErrNotOwner if the own-only request filters items not
+// by the field of its own document of the table
+func (r Req) OwnDocFilter(table, field string, id int) error {
+	if !r.OwnOnly() {
+		return nil
+	}
+	if field != table+"_id" {
+		return ErrNotOwner
+	}
+	return r.OwnDocCheck(table, id)
+}
+
+// OwnDocs returns ids of documents of the table of the own-only request,
+// nil if the request may see all documents
+func (r Req) OwnDocs(table string) (map[int]bool, error) {
+	if !r.OwnOnly() {
+		return nil, nil
+	}
+	return OwnDocIds(table, r.UserId)
+}
+
+// OwnDocIds returns ids of documents of the table of the user
+func OwnDocIds(table string, userId int) (map[int]bool, error) {
+	rows, err := db.Query("SELECT id FROM "+table+" WHERE user_id=?", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// ConflictError is returned by update of a stale version,
+// Respond sends it with status 409 and the current row as value
+type ConflictError struct {
+	Current interface{}
+}
+
+func (e ConflictError) Error() string {
+	return "conflict: the record was changed by another user"
+}
+
 type Result struct {
 	Value interface{} `json:"value"`
 	Error string      `json:"error"`
@@ -154,7 +275,31 @@ func (r Req) Respond(payload interface{}, err error) {
 	res := Result{payload, ""}
 	code := http.StatusOK
 
-	if err != nil {
+	var conflict ConflictError
+	var closed PeriodClosedError
+	var referenced ReferencedError
+	var fkey ForeignKeyError
+	var blocked TransitionBlockedError
+	var badQuery ListQueryError
+	if errors.As(err, &conflict) {
+		res = Result{conflict.Current, err.Error()}
+		code = http.StatusConflict
+	} else if errors.As(err, &referenced) {
+		res = Result{referenced.References, err.Error()}
+		code = http.StatusConflict
+	} else if errors.As(err, &blocked) {
+		res = Result{TransitionCheck{false, blocked.Reasons}, err.Error()}
+		code = http.StatusConflict
+	} else if errors.As(err, &fkey) || errors.As(err, &badQuery) {
+		res = Result{nil, err.Error()}
+		code = http.StatusBadRequest
+	} else if errors.As(err, &closed) {
+		res = Result{nil, err.Error()}
+		code = http.StatusForbidden
+	} else if errors.Is(err, ErrSessionStore) {
+		res = Result{nil, err.Error()}
+		code = http.StatusNotImplemented
+	} else if err != nil {
 		res = Result{nil, err.Error()}
 		code = http.StatusInternalServerError
 	}
@@ -169,6 +314,18 @@ func (r Req) Respond(payload interface{}, err error) {
 	r.W.Write(response)
 }
 
+// RespondFile sends the file, an error is sent as by Respond
+func (r Req) RespondFile(name, contentType string, data []byte, err error) {
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	r.W.Header().Set("Content-Type", contentType)
+	r.W.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	r.W.WriteHeader(http.StatusOK)
+	r.W.Write(data)
+}
+
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
@@ -269,10 +426,32 @@ func WrapAuth(handler func(req Req), access uint64) func(w http.ResponseWriter,
 	h := func(w http.ResponseWriter, r *http.Request) {
 		req := Req{R: r, W: w}
 
-		if (!IsLoggedIn(r) || !HasBaseAccess(access, r)) && access != LOGIN {
-			log.Print("try to do some without auth", access)
-			req.Respond(nil, errors.New("access denied"))
-			return
+		if access != LOGIN {
+			if token, ok := BearerToken(r); ok {
+				t, err := ApiTokenCheck(token)
+				if err == nil {
+					req.UserId = t.UserId
+					req.BaseAccess = UserBaseAccess(t.UserId) & t.Scope
+					req.Granted = grantedAccess(access, req.BaseAccess)
+					req.AddAccess = UserAddAccess(t.UserId) & t.AddScope
+				}
+			} else if IsLoggedIn(r) {
+				req.Granted = GrantedBaseAccess(access, r)
+				if req.Granted != 0 && access != LOGOUT && TotpSetupPending(r) {
+					req.Respond(nil, ErrTotpSetup)
+					return
+				}
+				if req.Granted != 0 {
+					req.UserId, _, _ = CurrentUser(r)
+					req.BaseAccess = UserBaseAccess(req.UserId)
+					req.AddAccess = UserAddAccess(req.UserId)
+				}
+			}
+			if req.Granted == 0 {
+				log.Print("try to do some without auth", access)
+				req.Respond(nil, errors.New("access denied"))
+				return
+			}
 		}
 
 		q := r.URL.Query()
@@ -333,42 +512,123 @@ func WrapAuth(handler func(req Req), access uint64) func(w http.ResponseWriter,
 // }
 
 // User access functions for session
-func UserPassword(login string) (int, string, error) {
+func UserPassword(login string) (int, string, string, error) {
 	var pass string
 	var id int
 	var is_active bool
 	var name string
 	err := db.QueryRow("SELECT id, password, is_active, name FROM user WHERE login=?", login).Scan(&id, &pass, &is_active, &name)
 	if err != nil {
-		return id, pass, err
+		return id, pass, name, err
 	}
 	if !is_active {
-		return id, "", nil
+		return id, "", name, nil
 	}
-	msg := Message{id, name, fmt.Sprintf("%s приєднався до чату", name)}
-	broadcast <- msg
+	return id, pass, name, nil
+}
 
-	return id, pass, nil
+// Password hashing. Passwords are stored as bcrypt hashes, rows that still
+// hold a plaintext password are rehashed on the next successful login.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
 }
 
-func UserBaseAccess(id int) uint64 {
-	var a uint64
-	err := db.QueryRow("SELECT base_access FROM user WHERE id=?", id).Scan(&a)
+func IsPasswordHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+// CheckPassword returns true if password matches the stored one
+// and legacy = true if the stored one is plaintext yet
+func CheckPassword(stored, password string) (ok bool, legacy bool) {
+	if stored == "" {
+		return false, false
+	}
+	if IsPasswordHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil, false
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1, true
+}
+
+func UserRehashPassword(id int, password string) error {
+	hash, err := HashPassword(password)
 	if err != nil {
-		a = 0
+		return err
 	}
-	return a
+	_, err = db.Exec("UPDATE user SET password=? WHERE id=?", hash, id)
+	return err
 }
 
-func UserAddAccess(id int) uint64 {
+// UserHashPassword is the create/update hook of User.
+// Empty password (or the stored hash itself) keeps the stored password.
+func UserHashPassword(u *User, tx *Tx) error {
+	var stored string
+	if u.Id != 0 {
+		err := tx.QueryRow("SELECT password FROM user WHERE id=?", u.Id).Scan(&stored)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	if u.Password == "" || u.Password == stored {
+		u.Password = stored
+		return nil
+	}
+	hash, err := HashPassword(u.Password)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
+
+// Password never goes to JSON responses
+type jsonUser User
+
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonUser
+		Password string `json:"password,omitempty"`
+	}{jsonUser: jsonUser(u)})
+}
+
+type jsonWUser WUser
+
+func (u WUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonWUser
+		Password string `json:"password,omitempty"`
+	}{jsonWUser: jsonWUser(u)})
+}
+
+// userAccess returns the mask from column of the user,
+// 0 means the mask is inherited from the user group or its parent groups
+func userAccess(id int, column string) uint64 {
 	var a uint64
-	err := db.QueryRow("SELECT add_access FROM user WHERE id=?", id).Scan(&a)
+	var groupId int
+	err := db.QueryRow("SELECT "+column+", user_group_id FROM user WHERE id=?", id).Scan(&a, &groupId)
 	if err != nil {
-		a = 0
+		return 0
+	}
+	visited := map[int]bool{}
+	for a == 0 && groupId != 0 && !visited[groupId] {
+		visited[groupId] = true
+		err = db.QueryRow("SELECT "+column+", user_group_id FROM user_group WHERE id=? AND is_active=1", groupId).Scan(&a, &groupId)
+		if err != nil {
+			return 0
+		}
 	}
 	return a
 }
 
+func UserBaseAccess(id int) uint64 {
+	return userAccess(id, "base_access")
+}
+
+func UserAddAccess(id int) uint64 {
+	return userAccess(id, "add_access")
+}
+
 // Users handlers for login and logout
 
 func Login(r Req) {
@@ -391,38 +651,89 @@ func Login(r Req) {
 	login := usr.Login
 	pass := usr.Password
 	//fmt.Println("login pass decomposition")
-	bdid, bdpass, err := UserPassword(login)
-	//fmt.Println("userPassword works")
+	addr := RemoteAddr(r.R)
+	release, err := LoginBegin(login, addr)
 	if err != nil {
+		log.Print("Login throttled ", login, " from ", addr)
+		r.Respond(nil, err)
+		return
+	}
+	defer release()
+	bdid, bdpass, name, err := UserPassword(login)
+	//fmt.Println("userPassword works")
+	if err != nil && err != sql.ErrNoRows {
 		r.Respond(nil, err)
+		return
 	}
 
-	fmt.Printf("login: %s pass %s bdpass %s\n", login, pass, bdpass)
-	if bdpass == pass && bdpass != "" {
-		sess.Values["loggedin"] = "true"
-		sess.Values["username"] = login
-		sess.Values["userid"] = bdid
-		sess.Save(r.R, r.W)
-		log.Print("user ", login, " is authenticated")
-		//fmt.Fprintf(w, "%s", login)
-
-		//jstr, err := json.Marshal(map[string]string{"user": login})
-		//if err != nil {
-		//  fmt.Println(err)
-		//}
-		//fmt.Println(jstr)
-		r.Respond(map[string]string{"user": login}, nil)
+	ok, legacy := CheckPassword(bdpass, pass)
+	if err == nil && ok {
+		if legacy {
+			err = UserRehashPassword(bdid, pass)
+			if err != nil {
+				log.Println("Error rehashing password of", login, err)
+			}
+		}
+		if TotpEnabled(bdid) {
+			if err := SessionRenew(sess); err != nil {
+				log.Println("Error renewing session of", login, err)
+			}
+			delete(sess.Values, "userid")
+			delete(sess.Values, "username")
+			sess.Values["loggedin"] = "false"
+			sess.Values["totp_userid"] = bdid
+			sess.Values["totp_at"] = time.Now().Unix()
+			sess.Save(r.R, r.W)
+			log.Print("user ", login, " entered password, waiting for totp code")
+			r.Respond(map[string]string{"user": login, "totp": "required"}, nil)
+			return
+		}
+		err = LoginSucceeded(login)
+		if err != nil {
+			log.Println("Error clearing login failures of", login, err)
+		}
+		r.Respond(startSession(r, sess, bdid, login, name), nil)
 		return
 	}
-	log.Print("Invalid user " + login)
+	log.Print("Invalid user " + login + " from " + addr)
+	err = LoginFailed(login, addr)
+	if err != nil {
+		log.Println("Error recording login failure", err)
+	}
 	r.Respond(nil, errors.New("невірний логін та/або пароль - спробуйте ще раз"))
 }
 
+// startSession marks the session with a new id as logged in,
+// the session can only set up totp if it is required but not enabled
+func startSession(r Req, sess *sessions.Session, id int, login string, name string) map[string]string {
+	if err := SessionRenew(sess); err != nil {
+		log.Println("Error renewing session of", login, err)
+	}
+	delete(sess.Values, "totp_userid")
+	delete(sess.Values, "totp_at")
+	sess.Values["loggedin"] = "true"
+	sess.Values["username"] = login
+	sess.Values["userid"] = id
+	res := map[string]string{"user": login}
+	if TotpRequired(id) && !TotpEnabled(id) {
+		sess.Values["totp_setup"] = true
+		res["totp"] = "setup"
+	} else {
+		delete(sess.Values, "totp_setup")
+	}
+	sess.Save(r.R, r.W)
+	log.Print("user ", login, " is authenticated")
+	msg := Message{id, name, fmt.Sprintf("%s приєднався до чату", name)}
+	broadcast <- msg
+	return res
+}
+
 func Logout(r Req) {
 	sess, err := Store.Get(r.R, "sess")
 	if err == nil {
 		if sess.Values["loggedin"] != "false" {
 			sess.Values["loggedin"] = "false"
+			sess.Options.MaxAge = -1
 			sess.Save(r.R, r.W)
 			log.Print("User ", sess.Values["username"], " is logout.")
 		}
@@ -432,6 +743,38 @@ func Logout(r Req) {
 	}
 }
 
+// GetSessionAll lists active sessions, of the user if id is in query
+func GetSessionAll(r Req) {
+	userId := 0
+	if v := r.R.URL.Query().Get("user_id"); v != "" {
+		var err error
+		userId, err = strconv.Atoi(v)
+		if err != nil {
+			r.Respond(nil, errors.New("invalid integer parameter user_id"))
+			return
+		}
+	}
+	r.Respond(SessionGetAll(userId))
+}
+
+func TerminateSession(r Req) {
+	n, err := SessionTerminate(r.IntParam)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	r.Respond(map[string]int64{"terminated": n}, nil)
+}
+
+func RevokeSessions(r Req) {
+	n, err := SessionsRevoke(r.IntParam)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	r.Respond(map[string]int64{"revoked": n}, nil)
+}
+
 func UploadFile(req Req) {
 	o, err := OrderingGet(req.IntParam, nil)
 	if err != nil {
@@ -507,20 +850,30 @@ func GetBackupBases(r Req) {
 	r.Respond(map[string][]string{"base_names": res}, nil)
 }
 
+// RestoreBaseFromBackup replaces the base with the backup and migrates it
+// to the server version, a backup newer than the server is refused
 func RestoreBaseFromBackup(r Req) {
-	err := DbClose()
+	base_name := r.StrParam
+	oldPath := filepath.Join(Cfg.BckpPath, base_name)
+	v, err := SchemaVersionOf(oldPath)
+	if err == nil {
+		err = checkSchemaVersion(v)
+	}
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	err = DbClose()
 	if err != nil {
 		r.Respond(nil, err)
 		return
 	}
-	base_name := r.StrParam
-	oldPath := filepath.Join(Cfg.BckpPath, base_name)
 	err = copyFile(oldPath, Cfg.DBFile)
 	if err != nil {
 		r.Respond(nil, err)
 		return
 	}
-	err = DBconnect(Cfg.DBFile)
+	err = openBase(Cfg.DBFile, false)
 	if err != nil {
 		r.Respond(nil, err)
 		return
@@ -644,7 +997,7 @@ func GetModels(r Req) {
 	r.Respond(res, nil)
 }
 
-func CreateMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *sql.Tx) error {
+func CreateMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *Tx) error {
 	var sql_reg string
 	var wmc_number WmcNumber
 
@@ -662,6 +1015,7 @@ func CreateMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *sql.Tx) error {
 		&wmc_number.ColorId,
 		&wmc_number.Total,
 		&wmc_number.IsActive,
+		&wmc_number.Version,
 	)
 	if err != nil {
 		wmc_number.Id = 0
@@ -685,7 +1039,7 @@ func CreateMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *sql.Tx) error {
 
 }
 
-func DeleteMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *sql.Tx) error {
+func DeleteMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *Tx) error {
 	var sql_reg string
 	var wmc_number WmcNumber
 
@@ -703,6 +1057,7 @@ func DeleteMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *sql.Tx) error {
 		&wmc_number.ColorId,
 		&wmc_number.Total,
 		&wmc_number.IsActive,
+		&wmc_number.Version,
 	)
 	if err != nil {
 		return err
@@ -717,7 +1072,7 @@ func DeleteMatherialToWhsInToNumber(m *MatherialToWhsIn, tx *sql.Tx) error {
 
 }
 
-func UpdateMatherialToWhsInToNumber(m *MatherialToWhsIn, old_number float64, tx *sql.Tx) error {
+func UpdateMatherialToWhsInToNumber(m *MatherialToWhsIn, old_number float64, tx *Tx) error {
 	var sql_reg string
 	var wmc_number WmcNumber
 
@@ -735,6 +1090,7 @@ func UpdateMatherialToWhsInToNumber(m *MatherialToWhsIn, old_number float64, tx
 		&wmc_number.ColorId,
 		&wmc_number.Total,
 		&wmc_number.IsActive,
+		&wmc_number.Version,
 	)
 	if err != nil {
 		return err
@@ -749,7 +1105,7 @@ func UpdateMatherialToWhsInToNumber(m *MatherialToWhsIn, old_number float64, tx
 
 }
 
-func CreateMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *sql.Tx) error {
+func CreateMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *Tx) error {
 	var sql_reg string
 	var wmc_number WmcNumber
 
@@ -767,6 +1123,7 @@ func CreateMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *sql.Tx) error {
 		&wmc_number.ColorId,
 		&wmc_number.Total,
 		&wmc_number.IsActive,
+		&wmc_number.Version,
 	)
 	if err != nil {
 		wmc_number.Id = 0
@@ -790,7 +1147,7 @@ func CreateMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *sql.Tx) error {
 
 }
 
-func DeleteMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *sql.Tx) error {
+func DeleteMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *Tx) error {
 	var sql_reg string
 	var wmc_number WmcNumber
 
@@ -808,6 +1165,7 @@ func DeleteMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *sql.Tx) error {
 		&wmc_number.ColorId,
 		&wmc_number.Total,
 		&wmc_number.IsActive,
+		&wmc_number.Version,
 	)
 	if err != nil {
 		return err
@@ -822,7 +1180,7 @@ func DeleteMatherialToWhsOutToNumber(m *MatherialToWhsOut, tx *sql.Tx) error {
 
 }
 
-func UpdateMatherialToWhsOutToNumber(m *MatherialToWhsOut, old_number float64, tx *sql.Tx) error {
+func UpdateMatherialToWhsOutToNumber(m *MatherialToWhsOut, old_number float64, tx *Tx) error {
 	var sql_reg string
 	var wmc_number WmcNumber
 
@@ -840,6 +1198,7 @@ func UpdateMatherialToWhsOutToNumber(m *MatherialToWhsOut, old_number float64, t
 		&wmc_number.ColorId,
 		&wmc_number.Total,
 		&wmc_number.IsActive,
+		&wmc_number.Version,
 	)
 	if err != nil {
 		return err
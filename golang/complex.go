@@ -171,12 +171,12 @@ func ProductDeepGet(productId int, counter *int) (ProductDeep, error) {
 	return pd, nil
 }
 
-func ProductToOrderingCreateDefault(p ProductToOrdering, isCopyCenter bool) (ProductToOrdering, error) {
-	p, err := ProductToOrderingCreate(p, nil)
+func ProductToOrderingCreateDefault(p ProductToOrdering, isCopyCenter bool, tx *Tx) (ProductToOrdering, error) {
+	p, err := ProductToOrderingCreate(p, tx)
 	if err != nil {
 		return p, err
 	}
-	m2ps, err := MatherialToProductGetByFilterInt("product_id", p.ProductId, false, false, nil)
+	m2ps, err := MatherialToProductGetByFilterInt("product_id", p.ProductId, false, false, tx)
 	if err != nil {
 		return p, err
 	}
@@ -189,7 +189,7 @@ func ProductToOrderingCreateDefault(p ProductToOrdering, isCopyCenter bool) (Pro
 		m2oLength := 0.0
 		m2oPieces := 1
 		if p.Width > 0 {
-			m, err := MatherialGet(m2p.MatherialId, nil)
+			m, err := MatherialGet(m2p.MatherialId, tx)
 			if err != nil {
 				return p, err
 			}
@@ -224,12 +224,12 @@ func ProductToOrderingCreateDefault(p ProductToOrdering, isCopyCenter bool) (Pro
 			IsActive:            true,
 		}
 
-		_, err = MatherialToOrderingCreate(m2o, nil)
+		_, err = MatherialToOrderingCreate(m2o, tx)
 		if err != nil {
 			return p, err
 		}
 	}
-	o2ps, err := OperationToProductGetByFilterInt("product_id", p.ProductId, false, false, nil)
+	o2ps, err := OperationToProductGetByFilterInt("product_id", p.ProductId, false, false, tx)
 	if err != nil {
 		return p, err
 	}
@@ -237,7 +237,7 @@ func ProductToOrderingCreateDefault(p ProductToOrdering, isCopyCenter bool) (Pro
 		if o2p.ListName != "default" {
 			continue
 		}
-		o, err := OperationGet(o2p.OperationId, nil)
+		o, err := OperationGet(o2p.OperationId, tx)
 		if err != nil {
 			return p, err
 		}
@@ -269,7 +269,7 @@ func ProductToOrderingCreateDefault(p ProductToOrdering, isCopyCenter bool) (Pro
 			IsActive:            true,
 		}
 
-		_, err = OperationToOrderingCreate(o2o, nil)
+		_, err = OperationToOrderingCreate(o2o, tx)
 		if err != nil {
 			return p, err
 		}
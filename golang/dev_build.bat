@@ -1 +1 @@
-go build
+go build -tags sqlite_fts5
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Document numbering: the sequence of a document table and owner (0 for all
+// owners) names new documents by its pattern, e.g. INV-{owner}-{yyyy}-{seq:05}.
+// Numbers go on per table, owner and year of created_at, the owner is
+// of the document if the pattern has {owner} and of the sequence otherwise,
+// so owners sharing the default pattern share its numbers.
+// They are taken in the create transaction. Documents without a sequence
+// are named "name-id" as before.
+
+type DocSequence struct {
+	DocTable  string `json:"doc_table"`
+	OwnerId   int    `json:"owner_id"`
+	Pattern   string `json:"pattern"`
+	UserId    int    `json:"user_id"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type DocSequenceNext struct {
+	DocTable string `json:"doc_table"`
+	OwnerId  int    `json:"owner_id"`
+	Number   string `json:"number"`
+}
+
+// {owner}, {yyyy}, {yy}, {mm} and {seq}, a width pads with zeros: {seq:05}
+var docPatternRe = regexp.MustCompile(`\{(owner|yyyy|yy|mm|seq)(?::([0-9]{1,2}))?\}`)
+
+func numberedTableKnown(table string) bool {
+	for _, t := range numberedTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+func docPatternValidate(pattern string) error {
+	if !strings.Contains(pattern, "{seq") {
+		return errors.New("pattern must have {seq}")
+	}
+	if rest := docPatternRe.ReplaceAllString(pattern, ""); strings.ContainsAny(rest, "{}") {
+		return errors.New("pattern may have only {owner}, {yyyy}, {yy}, {mm} and {seq} with optional width like {seq:05}")
+	}
+	return nil
+}
+
+// docNumberFormat fills the pattern, createdAt is "2006-01-02T15:04:05"
+func docNumberFormat(pattern string, ownerId int, createdAt string, seq int) string {
+	return docPatternRe.ReplaceAllStringFunc(pattern, func(m string) string {
+		p := docPatternRe.FindStringSubmatch(m)
+		var v int
+		switch p[1] {
+		case "owner":
+			v = ownerId
+		case "yyyy":
+			return createdAt[:4]
+		case "yy":
+			return createdAt[2:4]
+		case "mm":
+			return createdAt[5:7]
+		case "seq":
+			v = seq
+		}
+		width, _ := strconv.Atoi(p[2])
+		return fmt.Sprintf("%0*d", width, v)
+	})
+}
+
+// docSequencePattern returns the pattern for documents of the owner, "" if there is no sequence,
+// and the owner the counter of its numbers is kept for
+func docSequencePattern(table string, ownerId int, tx *Tx) (string, int, error) {
+	query := `SELECT pattern, owner_id FROM doc_sequence WHERE doc_table=? AND owner_id IN (?, 0)
+		ORDER BY owner_id DESC LIMIT 1`
+	var pattern string
+	var seqOwnerId int
+	var err error
+	if tx != nil {
+		err = tx.QueryRow(query, table, ownerId).Scan(&pattern, &seqOwnerId)
+	} else {
+		err = db.QueryRow(query, table, ownerId).Scan(&pattern, &seqOwnerId)
+	}
+	if err == sql.ErrNoRows {
+		return "", 0, nil
+	}
+	if strings.Contains(pattern, "{owner") {
+		seqOwnerId = ownerId
+	}
+	return pattern, seqOwnerId, err
+}
+
+// DocNumber returns the name of the new document, it takes the next number of its sequence
+func DocNumber(table, name string, id, ownerId int, createdAt string, tx *Tx) (string, error) {
+	pattern, seqOwnerId, err := docSequencePattern(table, ownerId, tx)
+	if err != nil || pattern == "" {
+		return fmt.Sprintf("%s-%d", name, id), err
+	}
+	var seq int
+	err = tx.QueryRow(`INSERT INTO doc_sequence_counter (doc_table, owner_id, year, last) VALUES(?, ?, ?, 1)
+		ON CONFLICT(doc_table, owner_id, year) DO UPDATE SET last=last+1
+		RETURNING last`, table, seqOwnerId, createdAt[:4]).Scan(&seq)
+	if err != nil {
+		return name, err
+	}
+	return docNumberFormat(pattern, ownerId, createdAt, seq), nil
+}
+
+// DocNumberNext returns the number the next document of the owner created at the date will get
+func DocNumberNext(table string, ownerId int, date string) (DocSequenceNext, error) {
+	res := DocSequenceNext{DocTable: table, OwnerId: ownerId}
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	date = dateOf(date)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return res, errors.New("date must be like 2006-01-02")
+	}
+	pattern, seqOwnerId, err := docSequencePattern(table, ownerId, nil)
+	if err != nil {
+		return res, err
+	}
+	if pattern == "" {
+		return res, errors.New("no sequence of " + table)
+	}
+	var last int
+	err = db.QueryRow("SELECT ifnull(max(last), 0) FROM doc_sequence_counter WHERE doc_table=? AND owner_id=? AND year=?",
+		table, seqOwnerId, date[:4]).Scan(&last)
+	if err != nil {
+		return res, err
+	}
+	res.Number = docNumberFormat(pattern, ownerId, date+"T00:00:00", last+1)
+	return res, nil
+}
+
+func DocSequenceGetAll() ([]DocSequence, error) {
+	rows, err := db.Query("SELECT doc_table, owner_id, pattern, user_id, updated_at FROM doc_sequence ORDER BY doc_table, owner_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []DocSequence{}
+	for rows.Next() {
+		var s DocSequence
+		if err = rows.Scan(&s.DocTable, &s.OwnerId, &s.Pattern, &s.UserId, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// DocSequenceSet sets the pattern of the table and owner (0 for all), empty pattern removes the sequence,
+// numbers are kept
+func DocSequenceSet(s DocSequence, tx *Tx) (DocSequence, error) {
+	if !numberedTableKnown(s.DocTable) {
+		return s, errors.New("unknown document " + s.DocTable)
+	}
+	if s.Pattern != "" {
+		if err := docPatternValidate(s.Pattern); err != nil {
+			return s, err
+		}
+	}
+	if s.OwnerId != 0 {
+		exists, active, err := rowActive("owner", s.OwnerId, tx)
+		if err != nil {
+			return s, err
+		}
+		if !active {
+			return s, ForeignKeyError{"doc_sequence", "owner_id", "owner", s.OwnerId, exists}
+		}
+	}
+	before := DocSequence{DocTable: s.DocTable, OwnerId: s.OwnerId}
+	err := tx.QueryRow("SELECT pattern, user_id, updated_at FROM doc_sequence WHERE doc_table=? AND owner_id=?",
+		s.DocTable, s.OwnerId).Scan(&before.Pattern, &before.UserId, &before.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return s, err
+	}
+	s.UserId = tx.UserId
+	s.UpdatedAt = time.Now().Format("2006-01-02T15:04:05")
+	if s.Pattern == "" {
+		_, err = tx.Exec("DELETE FROM doc_sequence WHERE doc_table=? AND owner_id=?", s.DocTable, s.OwnerId)
+	} else {
+		_, err = tx.Exec(`INSERT INTO doc_sequence (doc_table, owner_id, pattern, user_id, updated_at) VALUES(?, ?, ?, ?, ?)
+			ON CONFLICT(doc_table, owner_id) DO UPDATE SET pattern=excluded.pattern,
+				user_id=excluded.user_id, updated_at=excluded.updated_at`,
+			s.DocTable, s.OwnerId, s.Pattern, s.UserId, s.UpdatedAt)
+	}
+	if err != nil {
+		return s, err
+	}
+	err = Audit(tx, "doc_sequence", s.OwnerId, "update", before, s)
+	return s, err
+}
+
+// Document numbering handlers
+
+func GetDocSequenceAll(r Req) {
+	r.Respond(DocSequenceGetAll())
+}
+
+func SetDocSequence(r Req) {
+	var s DocSequence
+	decoder := json.NewDecoder(r.R.Body)
+	defer r.R.Body.Close()
+	err := decoder.Decode(&s)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	s, err = DocSequenceSet(s, tx)
+	r.RespondTx(tx, s, err)
+}
+
+// GetDocSequenceNext responds the next number of the document table,
+// query parameters: owner_id and date (today by default)
+func GetDocSequenceNext(r Req) {
+	if !numberedTableKnown(r.StrParam) {
+		r.Respond(nil, errors.New("unknown document "+r.StrParam))
+		return
+	}
+	q := r.R.URL.Query()
+	ownerId := 0
+	if v := q.Get("owner_id"); v != "" {
+		var err error
+		ownerId, err = strconv.Atoi(v)
+		if err != nil {
+			r.Respond(nil, errors.New("invalid integer parameter owner_id"))
+			return
+		}
+	}
+	r.Respond(DocNumberNext(r.StrParam, ownerId, q.Get("date")))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDocPatternValidate(t *testing.T) {
+	tests := []struct {
+		pattern string
+		ok      bool
+	}{
+		{"INV-{owner}-{yyyy}-{seq:05}", true},
+		{"{yy}{mm}/{seq}", true},
+		{"INV-{yyyy}", false},
+		{"INV-{day}-{seq}", false},
+		{"INV-{seq:123}", false},
+	}
+	for _, tt := range tests {
+		if err := docPatternValidate(tt.pattern); (err == nil) != tt.ok {
+			t.Errorf("docPatternValidate(%q) = %v, want ok %v", tt.pattern, err, tt.ok)
+		}
+	}
+}
+
+func TestDocNumber(t *testing.T) {
+	testBase(t)
+	owners := []int{}
+	for _, name := range []string{"first", "second", "third"} {
+		o, err := OwnerCreate(Owner{Name: name, IsActive: true}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		owners = append(owners, o.Id)
+	}
+	tx, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	sequences := []DocSequence{
+		{DocTable: "invoice", Pattern: "INV-{yyyy}-{seq:03}"},
+		{DocTable: "invoice", OwnerId: owners[2], Pattern: "T{owner}-{seq}"},
+		{DocTable: "whs_in", Pattern: "W{owner}-{seq}"},
+	}
+	for _, s := range sequences {
+		if _, err = DocSequenceSet(s, tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name      string
+		table     string
+		ownerId   int
+		createdAt string
+		want      string
+	}{
+		{"default pattern", "invoice", owners[0], "2026-03-01T10:00:00", "INV-2026-001"},
+		{"default pattern shared by owners", "invoice", owners[1], "2026-03-01T10:00:00", "INV-2026-002"},
+		{"default pattern next year", "invoice", owners[1], "2027-01-02T10:00:00", "INV-2027-001"},
+		{"own pattern", "invoice", owners[2], "2026-03-01T10:00:00", fmt.Sprintf("T%d-1", owners[2])},
+		{"default pattern with owner", "whs_in", owners[0], "2026-03-01T10:00:00", fmt.Sprintf("W%d-1", owners[0])},
+		{"default pattern with other owner", "whs_in", owners[1], "2026-03-01T10:00:00", fmt.Sprintf("W%d-1", owners[1])},
+		{"no sequence", "cash_in", 0, "2026-03-01T10:00:00", "name-7"},
+	}
+	for _, tt := range tests {
+		got, err := DocNumber(tt.table, "name", 7, tt.ownerId, tt.createdAt, tx)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: DocNumber = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err = DocSequenceSet(DocSequence{DocTable: "invoice", Pattern: "INV-{yyyy}"}, tx); err == nil {
+		t.Error("pattern without {seq} is set")
+	}
+	if _, err = DocSequenceSet(DocSequence{DocTable: "ordering", Pattern: "{seq}"}, tx); err == nil {
+		t.Error("pattern of not numbered table is set")
+	}
+}
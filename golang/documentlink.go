@@ -0,0 +1,237 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Document links: based_on of a document ("ordering.12") is kept as typed
+// parent/child row of document_link on create and update. Realize, unrealize
+// and delete of the parent find the children by the links.
+
+var basedOnRe = regexp.MustCompile(`^([a-z_]+)\.([0-9]+)$`)
+
+type DocumentNode struct {
+	Table      string         `json:"table"`
+	Id         int            `json:"id"`
+	Name       string         `json:"name"`
+	CreatedAt  string         `json:"created_at"`
+	IsRealized bool           `json:"is_realized"`
+	IsActive   bool           `json:"is_active"`
+	Children   []DocumentNode `json:"children"`
+}
+
+// ParseBasedOn returns the parent of based_on, ok is false for empty or free text
+func ParseBasedOn(basedOn string) (table string, id int, ok bool) {
+	m := basedOnRe.FindStringSubmatch(basedOn)
+	if m == nil {
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(m[2])
+	return m[1], id, err == nil
+}
+
+// DocumentLinkSet links the child document to the parent of its based_on
+func DocumentLinkSet(childTable string, childId int, basedOn string, tx *Tx) error {
+	parentTable, parentId, ok := ParseBasedOn(basedOn)
+	if !ok {
+		_, err := tx.Exec("DELETE FROM document_link WHERE child_table=? AND child_id=?", childTable, childId)
+		return err
+	}
+	_, err := tx.Exec(`INSERT INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+		VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(child_table, child_id) DO UPDATE SET parent_table=excluded.parent_table, parent_id=excluded.parent_id
+		WHERE parent_table != excluded.parent_table OR parent_id != excluded.parent_id`,
+		parentTable, parentId, childTable, childId, time.Now().Format("2006-01-02T15:04:05"))
+	return err
+}
+
+// DocumentChildIds returns ids of active children of the document in childTable
+func DocumentChildIds(parentTable string, parentId int, childTable string, tx *Tx) ([]int, error) {
+	query := fmt.Sprintf(`SELECT l.child_id FROM document_link l JOIN %s c ON c.id = l.child_id
+		WHERE l.parent_table=? AND l.parent_id=? AND l.child_table=? AND c.is_active=1
+		ORDER BY l.child_id`, childTable)
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(query, parentTable, parentId, childTable)
+	} else {
+		rows, err = db.Query(query, parentTable, parentId, childTable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []int{}
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
+
+func documentTableKnown(table string) bool {
+	for _, t := range append(documentTables, basedOnTables...) {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+func documentNodeGet(table string, id int) (DocumentNode, error) {
+	n := DocumentNode{Table: table, Id: id, Children: []DocumentNode{}}
+	realized := "0"
+	for _, t := range documentTables {
+		if t == table {
+			realized = "is_realized"
+		}
+	}
+	err := db.QueryRow(fmt.Sprintf("SELECT name, created_at, %s, is_active FROM %s WHERE id=?", realized, table), id).
+		Scan(&n.Name, &n.CreatedAt, &n.IsRealized, &n.IsActive)
+	return n, err
+}
+
+// documentOwned reports whether the document belongs to the user,
+// documents without user_id and any document for userId 0 do
+func documentOwned(table string, id, userId int) (bool, error) {
+	if userId == 0 {
+		return true, nil
+	}
+	for _, t := range ownTables {
+		if t == table {
+			var owner int
+			err := db.QueryRow(fmt.Sprintf("SELECT user_id FROM %s WHERE id=?", table), id).Scan(&owner)
+			return owner == userId, err
+		}
+	}
+	return true, nil
+}
+
+func documentParent(table string, id int) (string, int, error) {
+	var parentTable string
+	var parentId int
+	err := db.QueryRow("SELECT parent_table, parent_id FROM document_link WHERE child_table=? AND child_id=?", table, id).
+		Scan(&parentTable, &parentId)
+	return parentTable, parentId, err
+}
+
+func documentChildren(n *DocumentNode, visited map[string]bool, userId int) error {
+	rows, err := db.Query("SELECT child_table, child_id FROM document_link WHERE parent_table=? AND parent_id=? ORDER BY id",
+		n.Table, n.Id)
+	if err != nil {
+		return err
+	}
+	type link struct {
+		table string
+		id    int
+	}
+	links := []link{}
+	for rows.Next() {
+		var l link
+		if err = rows.Scan(&l.table, &l.id); err != nil {
+			rows.Close()
+			return err
+		}
+		links = append(links, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, l := range links {
+		key := fmt.Sprintf("%s.%d", l.table, l.id)
+		if visited[key] || !documentTableKnown(l.table) {
+			continue
+		}
+		visited[key] = true
+		child, err := documentNodeGet(l.table, l.id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		owned, err := documentOwned(l.table, l.id, userId)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			continue
+		}
+		err = documentChildren(&child, visited, userId)
+		if err != nil {
+			return err
+		}
+		n.Children = append(n.Children, child)
+	}
+	return nil
+}
+
+// DocumentTree returns the whole chain of the document: its root parent with all descendants,
+// deleted documents are included with is_active false. If userId is not 0 the chain
+// has only documents of the user, the document itself must be of the user
+func DocumentTree(table string, id int, userId int) (DocumentNode, error) {
+	if !documentTableKnown(table) {
+		return DocumentNode{}, errors.New("unknown document " + table)
+	}
+	owned, err := documentOwned(table, id, userId)
+	if err != nil {
+		return DocumentNode{}, err
+	}
+	if !owned {
+		return DocumentNode{}, ErrNotOwner
+	}
+	visited := map[string]bool{fmt.Sprintf("%s.%d", table, id): true}
+	for {
+		parentTable, parentId, err := documentParent(table, id)
+		if err == sql.ErrNoRows || (err == nil && !documentTableKnown(parentTable)) {
+			break
+		}
+		if err != nil {
+			return DocumentNode{}, err
+		}
+		key := fmt.Sprintf("%s.%d", parentTable, parentId)
+		if visited[key] {
+			break
+		}
+		if _, err = documentNodeGet(parentTable, parentId); err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return DocumentNode{}, err
+		}
+		owned, err = documentOwned(parentTable, parentId, userId)
+		if err != nil {
+			return DocumentNode{}, err
+		}
+		if !owned {
+			break
+		}
+		visited[key] = true
+		table, id = parentTable, parentId
+	}
+	root, err := documentNodeGet(table, id)
+	if err != nil {
+		return root, err
+	}
+	err = documentChildren(&root, map[string]bool{fmt.Sprintf("%s.%d", table, id): true}, userId)
+	return root, err
+}
+
+// Document link handlers
+
+func GetDocumentTree(r Req) {
+	userId := 0
+	if r.OwnOnly() {
+		userId = r.UserId
+	}
+	r.Respond(DocumentTree(r.StrParam, r.IntParam, userId))
+}
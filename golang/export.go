@@ -0,0 +1,424 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Export of lists: _get_all, filter, between, find and w_ lists with
+// ?format=csv or ?format=xlsx respond a file instead of JSON. Headers are hum
+// names of models.json, numbers, bools and dates (columns *_at) are typed
+// cells, w_ lists have names of joined rows instead of their ids. Rows are
+// written to the response while they are read from the base and flushed, the
+// list is never built in memory. CSV is UTF-8 with BOM, ?sep=semicolon (tab,
+// comma or one encoded character) changes the separator. Secrets hidden from
+// JSON (password) are never exported.
+
+type exportColumn struct {
+	index int
+	hum   string
+	kind  reflect.Kind
+	date  bool
+}
+
+// flush the response every exportFlushRows rows
+const exportFlushRows = 500
+
+// columns of secrets, MarshalJSON of their models hides them too
+var exportHidden = map[string]bool{"password": true}
+
+func exportJsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// exportColumns returns columns of the row type, technical version and ids
+// of joined names are not exported
+func exportColumns(table string, t reflect.Type) []exportColumn {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		names[exportJsonName(t.Field(i))] = true
+	}
+	res := []exportColumn{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := exportJsonName(f)
+		if name == "" || name == "-" || name == "version" || exportHidden[name] {
+			continue
+		}
+		if strings.HasSuffix(name, "_id") && names[strings.TrimSuffix(name, "_id")] {
+			continue
+		}
+		hum := columnHums[table][name]
+		if hum == "" {
+			hum = name
+		}
+		kind := f.Type.Kind()
+		res = append(res, exportColumn{i, hum, kind, kind == reflect.String && strings.HasSuffix(name, "_at")})
+	}
+	return res
+}
+
+// exportTime parses dates of the base, "2006-01-02T15:04:05" or shorter
+func exportTime(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func exportFlush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type exportWriter interface {
+	row(v reflect.Value) error
+	close() error
+}
+
+type csvExport struct {
+	w    http.ResponseWriter
+	cw   *csv.Writer
+	cols []exportColumn
+	n    int
+}
+
+func newCsvExport(w http.ResponseWriter, sep rune, cols []exportColumn) (*csvExport, error) {
+	// BOM makes spreadsheets read UTF-8
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	e := &csvExport{w: w, cw: csv.NewWriter(w), cols: cols}
+	e.cw.Comma = sep
+	record := make([]string, len(cols))
+	for i, c := range cols {
+		record[i] = c.hum
+	}
+	return e, e.cw.Write(record)
+}
+
+func (e *csvExport) row(v reflect.Value) error {
+	record := make([]string, len(e.cols))
+	for i, c := range e.cols {
+		f := v.Field(c.index)
+		switch c.kind {
+		case reflect.Float64:
+			record[i] = strconv.FormatFloat(f.Float(), 'f', -1, 64)
+		case reflect.Int:
+			record[i] = strconv.FormatInt(f.Int(), 10)
+		case reflect.Bool:
+			record[i] = "0"
+			if f.Bool() {
+				record[i] = "1"
+			}
+		default:
+			record[i] = f.String()
+			if c.date {
+				record[i] = strings.Replace(record[i], "T", " ", 1)
+			}
+		}
+	}
+	if err := e.cw.Write(record); err != nil {
+		return err
+	}
+	e.n++
+	if e.n%exportFlushRows == 0 {
+		e.cw.Flush()
+		exportFlush(e.w)
+	}
+	return e.cw.Error()
+}
+
+func (e *csvExport) close() error {
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// styles: 1 bold header, 2 date and time, 3 date
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="dd.mm.yyyy hh:mm"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+// xlsxColumn returns the letters of the column: 0 is A, 26 is AA
+func xlsxColumn(i int) string {
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
+
+// xlsxSerial returns the spreadsheet serial number of the time
+func xlsxSerial(t time.Time) float64 {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return t.Sub(base).Hours() / 24
+}
+
+type xlsxSheet struct {
+	w   io.Writer
+	err error
+}
+
+func (s *xlsxSheet) write(format string, args ...interface{}) {
+	if s.err == nil {
+		_, s.err = fmt.Fprintf(s.w, format, args...)
+	}
+}
+
+func (s *xlsxSheet) text(ref, text string, style int) {
+	s.write(`<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">`, ref, style)
+	if s.err == nil {
+		s.err = xml.EscapeText(s.w, []byte(text))
+	}
+	s.write(`</t></is></c>`)
+}
+
+type xlsxExport struct {
+	w    http.ResponseWriter
+	zw   *zip.Writer
+	s    *xlsxSheet
+	cols []exportColumn
+	refs []string
+	n    int
+}
+
+func newXlsxExport(w http.ResponseWriter, sheet string, cols []exportColumn) (*xlsxExport, error) {
+	zw := zip.NewWriter(w)
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(sheet))
+	for _, part := range []struct{ name, data string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, buf.String())},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = io.WriteString(f, part.data); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxExport{w: w, zw: zw, s: &xlsxSheet{w: f}, cols: cols, refs: make([]string, len(cols))}
+	e.s.write(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1">`)
+	for i, c := range cols {
+		e.refs[i] = xlsxColumn(i)
+		e.s.text(e.refs[i]+"1", c.hum, 1)
+	}
+	e.s.write(`</row>`)
+	return e, e.s.err
+}
+
+func (e *xlsxExport) row(v reflect.Value) error {
+	s := e.s
+	r := strconv.Itoa(e.n + 2)
+	s.write(`<row r="%s">`, r)
+	for i, c := range e.cols {
+		f := v.Field(c.index)
+		ref := e.refs[i] + r
+		switch c.kind {
+		case reflect.Float64:
+			s.write(`<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(f.Float(), 'f', -1, 64))
+		case reflect.Int:
+			s.write(`<c r="%s"><v>%d</v></c>`, ref, f.Int())
+		case reflect.Bool:
+			b := 0
+			if f.Bool() {
+				b = 1
+			}
+			s.write(`<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		default:
+			str := f.String()
+			if c.date {
+				if t, ok := exportTime(str); ok {
+					style := 2
+					if len(str) == len("2006-01-02") {
+						style = 3
+					}
+					s.write(`<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(xlsxSerial(t), 'f', -1, 64))
+					continue
+				}
+			}
+			if str != "" {
+				s.text(ref, str, 0)
+			}
+		}
+	}
+	s.write(`</row>`)
+	e.n++
+	if e.n%exportFlushRows == 0 && s.err == nil {
+		s.err = e.zw.Flush()
+		exportFlush(e.w)
+	}
+	return s.err
+}
+
+func (e *xlsxExport) close() error {
+	e.s.write(`</sheetData></worksheet>`)
+	if e.s.err != nil {
+		return e.s.err
+	}
+	return e.zw.Close()
+}
+
+// Exporting reports whether the list is asked as the file of ?format=csv|xlsx
+func (r Req) Exporting() bool {
+	return r.R.URL.Query().Get("format") != ""
+}
+
+// exportParams checks format and sep of the request
+func (r Req) exportParams() (string, rune, error) {
+	q := r.R.URL.Query()
+	format := q.Get("format")
+	if format != "csv" && format != "xlsx" {
+		return "", 0, errors.New("format must be csv or xlsx")
+	}
+	sep := ','
+	switch v := q.Get("sep"); v {
+	case "", "comma":
+	case "semicolon":
+		sep = ';'
+	case "tab":
+		sep = '\t'
+	default:
+		sep, _ = utf8.DecodeRuneInString(v)
+		if utf8.RuneCountInString(v) != 1 || sep == 0 || sep == utf8.RuneError || sep == '"' || sep == '\r' || sep == '\n' {
+			return "", 0, errors.New("sep must be comma, semicolon, tab or one character, not quote or newline")
+		}
+	}
+	return format, sep, nil
+}
+
+// Export responds the file of ?format=csv|xlsx with columns of the type of
+// row, rows gives rows to add one by one. The file starts with the first row,
+// errors before it are responded as JSON, after it they break the file.
+func (r Req) Export(table string, row interface{}, rows func(add func(interface{}) error) error) {
+	format, sep, err := r.exportParams()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	cols := exportColumns(table, reflect.TypeOf(row))
+	var e exportWriter
+	start := func() error {
+		contentType := "text/csv; charset=utf-8"
+		if format == "xlsx" {
+			contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		}
+		r.W.Header().Set("Content-Type", contentType)
+		r.W.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", table+"."+format))
+		r.W.WriteHeader(http.StatusOK)
+		var err error
+		if format == "csv" {
+			e, err = newCsvExport(r.W, sep, cols)
+		} else {
+			e, err = newXlsxExport(r.W, table, cols)
+		}
+		return err
+	}
+	started := false
+	err = rows(func(v interface{}) error {
+		if !started {
+			started = true
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return e.row(reflect.ValueOf(v))
+	})
+	if err == nil && !started {
+		started = true
+		err = start()
+	}
+	if err == nil {
+		err = e.close()
+	}
+	if err != nil && !started {
+		r.Respond(nil, err)
+		return
+	}
+	// the status is sent already, the client gets a broken file
+	if err != nil {
+		log.Println("export of", table, err)
+	}
+}
+
+// RespondRows responds the list as JSON, or as the file of ?format=csv|xlsx
+// with columns of the table
+func (r Req) RespondRows(table string, payload interface{}, err error) {
+	if !r.Exporting() || err != nil {
+		r.Respond(payload, err)
+		return
+	}
+	rows := reflect.ValueOf(payload)
+	if rows.Kind() != reflect.Slice || rows.Type().Elem().Kind() != reflect.Struct {
+		r.Respond(nil, errors.New("the response is not a list"))
+		return
+	}
+	r.Export(table, reflect.Zero(rows.Type().Elem()).Interface(), func(add func(interface{}) error) error {
+		for i := 0; i < rows.Len(); i++ {
+			if err := add(rows.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func exportReq(url string) (Req, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	return Req{W: w, R: httptest.NewRequest("GET", url, nil)}, w
+}
+
+func TestExportColumnsHidePassword(t *testing.T) {
+	for _, row := range []interface{}{User{}, WUser{}} {
+		for _, c := range exportColumns("user", reflect.TypeOf(row)) {
+			if c.hum == columnHums["user"]["password"] || c.hum == "password" {
+				t.Errorf("%T exports the password column", row)
+			}
+		}
+	}
+}
+
+func TestExportCsvHeader(t *testing.T) {
+	req, w := exportReq("/user_get_all?format=csv&sep=semicolon")
+	req.RespondRows("user", []User{{Id: 1, Login: "admin", Password: "secret-hash", IsActive: true}}, nil)
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "secret-hash") {
+		t.Error("the password is exported")
+	}
+	header, _ := bufio.NewReader(strings.NewReader(body)).ReadString('\n')
+	header = strings.TrimPrefix(strings.TrimSpace(header), "\ufeff")
+	want := []string{}
+	for _, name := range []string{"id", "name", "full_name", "user_group_id", "cash_id", "phone", "email", "comm", "login", "base_access", "add_access", "is_active"} {
+		want = append(want, columnHums["user"][name])
+	}
+	if header != strings.Join(want, ";") {
+		t.Errorf("header %q, want %q", header, strings.Join(want, ";"))
+	}
+}
+
+func TestExportParamsChecked(t *testing.T) {
+	for _, url := range []string{
+		"/user_get_all?format=pdf",
+		"/user_get_all?format=csv&sep=%22",
+		"/user_get_all?format=csv&sep=%0A",
+		"/user_get_all?format=csv&sep=%0D",
+		"/user_get_all?format=csv&sep=ab",
+	} {
+		req, w := exportReq(url)
+		req.Export("user", User{}, func(add func(interface{}) error) error {
+			t.Errorf("%s: rows are read", url)
+			return nil
+		})
+		if w.Code == 200 || w.Header().Get("Content-Disposition") != "" {
+			t.Errorf("%s: status %d, file %q", url, w.Code, w.Header().Get("Content-Disposition"))
+		}
+	}
+}
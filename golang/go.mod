@@ -6,13 +6,13 @@ replace asm13sam/tg => ../tg
 
 require (
 	asm13sam/tg v0.0.0-00010101000000-000000000000
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/securecookie v1.1.2
 	github.com/gorilla/sessions v1.2.2
 	github.com/gorilla/websocket v1.5.1
 	github.com/mattn/go-sqlite3 v1.14.17
+	golang.org/x/crypto v0.17.0
 )
 
-require (
-	github.com/gorilla/securecookie v1.1.2 // indirect
-	golang.org/x/net v0.17.0 // indirect
-)
+require golang.org/x/net v0.17.0 // indirect
@@ -19,7 +19,13 @@ func CreateProductToOrderingDefault(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToOrderingCreateDefault(p, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToOrderingCreateDefault(p, false, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func CreateProductToOrderingDefaultCC(req Req) {
@@ -28,7 +34,13 @@ func CreateProductToOrderingDefaultCC(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToOrderingCreateDefault(p, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToOrderingCreateDefault(p, true, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetMeasure(req Req) {
@@ -36,7 +48,35 @@ func GetMeasure(req Req) {
 }
 
 func GetMeasureAll(req Req) {
-	req.Respond(MeasureGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("measure", Measure{}, func(add func(interface{}) error) error {
+				_, err := MeasureGetByQueryEach(q, nil, func(m Measure) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MeasureGetByQuery(q, nil)
+		req.RespondList("measure", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("measure", Measure{}, func(add func(interface{}) error) error {
+			return MeasureGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m Measure) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MeasureGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("measure", m, err)
 }
 
 func CreateMeasure(req Req) {
@@ -45,7 +85,13 @@ func CreateMeasure(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MeasureCreate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MeasureCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMeasure(req Req) {
@@ -54,19 +100,64 @@ func UpdateMeasure(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MeasureUpdate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MeasureUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMeasure(req Req) {
-	req.Respond(MeasureDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("measure", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	m, err := MeasureDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMeasure(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MeasureRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMeasureByFilterInt(req Req) {
-	req.Respond(MeasureGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("measure", Measure{}, func(add func(interface{}) error) error {
+			return MeasureGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m Measure) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MeasureGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("measure", m, err)
 }
 
 func GetMeasureByFilterStr(req Req) {
-	req.Respond(MeasureGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("measure", Measure{}, func(add func(interface{}) error) error {
+			return MeasureGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m Measure) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MeasureGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("measure", m, err)
 }
 
 func DecodeMeasure(req Req) (Measure, error) {
@@ -82,7 +173,35 @@ func GetCountType(req Req) {
 }
 
 func GetCountTypeAll(req Req) {
-	req.Respond(CountTypeGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("count_type", CountType{}, func(add func(interface{}) error) error {
+				_, err := CountTypeGetByQueryEach(q, nil, func(c CountType) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := CountTypeGetByQuery(q, nil)
+		req.RespondList("count_type", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("count_type", CountType{}, func(add func(interface{}) error) error {
+			return CountTypeGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c CountType) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CountTypeGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("count_type", c, err)
 }
 
 func CreateCountType(req Req) {
@@ -91,7 +210,13 @@ func CreateCountType(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CountTypeCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CountTypeCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateCountType(req Req) {
@@ -100,19 +225,64 @@ func UpdateCountType(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CountTypeUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CountTypeUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteCountType(req Req) {
-	req.Respond(CountTypeDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("count_type", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := CountTypeDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreCountType(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CountTypeRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCountTypeByFilterInt(req Req) {
-	req.Respond(CountTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("count_type", CountType{}, func(add func(interface{}) error) error {
+			return CountTypeGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c CountType) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CountTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("count_type", c, err)
 }
 
 func GetCountTypeByFilterStr(req Req) {
-	req.Respond(CountTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("count_type", CountType{}, func(add func(interface{}) error) error {
+			return CountTypeGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c CountType) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CountTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("count_type", c, err)
 }
 
 func DecodeCountType(req Req) (CountType, error) {
@@ -128,7 +298,35 @@ func GetColorGroup(req Req) {
 }
 
 func GetColorGroupAll(req Req) {
-	req.Respond(ColorGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("color_group", ColorGroup{}, func(add func(interface{}) error) error {
+				_, err := ColorGroupGetByQueryEach(q, nil, func(c ColorGroup) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := ColorGroupGetByQuery(q, nil)
+		req.RespondList("color_group", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("color_group", ColorGroup{}, func(add func(interface{}) error) error {
+			return ColorGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c ColorGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ColorGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("color_group", c, err)
 }
 
 func CreateColorGroup(req Req) {
@@ -137,7 +335,13 @@ func CreateColorGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ColorGroupCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ColorGroupCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateColorGroup(req Req) {
@@ -146,19 +350,64 @@ func UpdateColorGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ColorGroupUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ColorGroupUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteColorGroup(req Req) {
-	req.Respond(ColorGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("color_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := ColorGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreColorGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := ColorGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetColorGroupByFilterInt(req Req) {
-	req.Respond(ColorGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("color_group", ColorGroup{}, func(add func(interface{}) error) error {
+			return ColorGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c ColorGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ColorGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("color_group", c, err)
 }
 
 func GetColorGroupByFilterStr(req Req) {
-	req.Respond(ColorGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("color_group", ColorGroup{}, func(add func(interface{}) error) error {
+			return ColorGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c ColorGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ColorGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("color_group", c, err)
 }
 
 func DecodeColorGroup(req Req) (ColorGroup, error) {
@@ -174,7 +423,35 @@ func GetColor(req Req) {
 }
 
 func GetColorAll(req Req) {
-	req.Respond(ColorGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("color", Color{}, func(add func(interface{}) error) error {
+				_, err := ColorGetByQueryEach(q, nil, func(c Color) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := ColorGetByQuery(q, nil)
+		req.RespondList("color", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("color", Color{}, func(add func(interface{}) error) error {
+			return ColorGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c Color) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ColorGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("color", c, err)
 }
 
 func CreateColor(req Req) {
@@ -183,7 +460,13 @@ func CreateColor(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ColorCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ColorCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateColor(req Req) {
@@ -192,19 +475,64 @@ func UpdateColor(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ColorUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ColorUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteColor(req Req) {
-	req.Respond(ColorDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("color", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := ColorDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreColor(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := ColorRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetColorByFilterInt(req Req) {
-	req.Respond(ColorGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("color", Color{}, func(add func(interface{}) error) error {
+			return ColorGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c Color) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ColorGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("color", c, err)
 }
 
 func GetColorByFilterStr(req Req) {
-	req.Respond(ColorGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("color", Color{}, func(add func(interface{}) error) error {
+			return ColorGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c Color) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ColorGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("color", c, err)
 }
 
 func DecodeColor(req Req) (Color, error) {
@@ -220,7 +548,35 @@ func GetMatherialGroup(req Req) {
 }
 
 func GetMatherialGroupAll(req Req) {
-	req.Respond(MatherialGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_group", MatherialGroup{}, func(add func(interface{}) error) error {
+				_, err := MatherialGroupGetByQueryEach(q, nil, func(m MatherialGroup) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialGroupGetByQuery(q, nil)
+		req.RespondList("matherial_group", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_group", MatherialGroup{}, func(add func(interface{}) error) error {
+			return MatherialGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialGroup) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_group", m, err)
 }
 
 func CreateMatherialGroup(req Req) {
@@ -229,7 +585,13 @@ func CreateMatherialGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialGroupCreate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialGroupCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherialGroup(req Req) {
@@ -238,19 +600,64 @@ func UpdateMatherialGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialGroupUpdate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialGroupUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherialGroup(req Req) {
-	req.Respond(MatherialGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("matherial_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	m, err := MatherialGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherialGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialGroupByFilterInt(req Req) {
-	req.Respond(MatherialGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_group", MatherialGroup{}, func(add func(interface{}) error) error {
+			return MatherialGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialGroup) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_group", m, err)
 }
 
 func GetMatherialGroupByFilterStr(req Req) {
-	req.Respond(MatherialGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_group", MatherialGroup{}, func(add func(interface{}) error) error {
+			return MatherialGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialGroup) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_group", m, err)
 }
 
 func DecodeMatherialGroup(req Req) (MatherialGroup, error) {
@@ -266,7 +673,35 @@ func GetMatherial(req Req) {
 }
 
 func GetMatherialAll(req Req) {
-	req.Respond(MatherialGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial", Matherial{}, func(add func(interface{}) error) error {
+				_, err := MatherialGetByQueryEach(q, nil, func(m Matherial) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialGetByQuery(q, nil)
+		req.RespondList("matherial", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial", Matherial{}, func(add func(interface{}) error) error {
+			return MatherialGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m Matherial) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial", m, err)
 }
 
 func CreateMatherial(req Req) {
@@ -275,7 +710,17 @@ func CreateMatherial(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialCreate(m, nil))
+	if m.Price != 0 && !req.HasAddAccess(ADD_CHANGE_PRICE) {
+		req.Respond(nil, ErrAccessDenied)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherial(req Req) {
@@ -284,19 +729,73 @@ func UpdateMatherial(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialUpdate(m, nil))
+	prev, err := MatherialGet(m.Id, nil)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	if prev.Price != m.Price && !req.HasAddAccess(ADD_CHANGE_PRICE) {
+		req.Respond(nil, ErrAccessDenied)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherial(req Req) {
-	req.Respond(MatherialDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("matherial", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	m, err := MatherialDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherial(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialByFilterInt(req Req) {
-	req.Respond(MatherialGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial", Matherial{}, func(add func(interface{}) error) error {
+			return MatherialGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m Matherial) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial", m, err)
 }
 
 func GetMatherialByFilterStr(req Req) {
-	req.Respond(MatherialGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial", Matherial{}, func(add func(interface{}) error) error {
+			return MatherialGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m Matherial) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial", m, err)
 }
 
 func DecodeMatherial(req Req) (Matherial, error) {
@@ -312,7 +811,35 @@ func GetCash(req Req) {
 }
 
 func GetCashAll(req Req) {
-	req.Respond(CashGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("cash", Cash{}, func(add func(interface{}) error) error {
+				_, err := CashGetByQueryEach(q, nil, func(c Cash) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := CashGetByQuery(q, nil)
+		req.RespondList("cash", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cash", Cash{}, func(add func(interface{}) error) error {
+			return CashGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c Cash) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("cash", c, err)
 }
 
 func CreateCash(req Req) {
@@ -321,7 +848,13 @@ func CreateCash(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CashCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CashCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateCash(req Req) {
@@ -330,19 +863,64 @@ func UpdateCash(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CashUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CashUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteCash(req Req) {
-	req.Respond(CashDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("cash", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := CashDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreCash(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCashByFilterInt(req Req) {
-	req.Respond(CashGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cash", Cash{}, func(add func(interface{}) error) error {
+			return CashGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c Cash) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("cash", c, err)
 }
 
 func GetCashByFilterStr(req Req) {
-	req.Respond(CashGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cash", Cash{}, func(add func(interface{}) error) error {
+			return CashGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c Cash) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("cash", c, err)
 }
 
 func DecodeCash(req Req) (Cash, error) {
@@ -358,7 +936,35 @@ func GetUserGroup(req Req) {
 }
 
 func GetUserGroupAll(req Req) {
-	req.Respond(UserGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("user_group", UserGroup{}, func(add func(interface{}) error) error {
+				_, err := UserGroupGetByQueryEach(q, nil, func(u UserGroup) error {
+					return add(u)
+				})
+				return err
+			})
+			return
+		}
+		u, total, err := UserGroupGetByQuery(q, nil)
+		req.RespondList("user_group", u, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("user_group", UserGroup{}, func(add func(interface{}) error) error {
+			return UserGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(u UserGroup) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := UserGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("user_group", u, err)
 }
 
 func CreateUserGroup(req Req) {
@@ -367,7 +973,13 @@ func CreateUserGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(UserGroupCreate(u, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	u, err = UserGroupCreate(u, tx)
+	req.RespondTx(tx, u, err)
 }
 
 func UpdateUserGroup(req Req) {
@@ -376,19 +988,64 @@ func UpdateUserGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(UserGroupUpdate(u, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	u, err = UserGroupUpdateVersioned(u, tx)
+	req.RespondTx(tx, u, err)
 }
 
 func DeleteUserGroup(req Req) {
-	req.Respond(UserGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("user_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	u, err := UserGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, u, err)
+}
+
+func RestoreUserGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	u, err := UserGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, u, err)
 }
 
 func GetUserGroupByFilterInt(req Req) {
-	req.Respond(UserGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("user_group", UserGroup{}, func(add func(interface{}) error) error {
+			return UserGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(u UserGroup) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := UserGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("user_group", u, err)
 }
 
 func GetUserGroupByFilterStr(req Req) {
-	req.Respond(UserGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("user_group", UserGroup{}, func(add func(interface{}) error) error {
+			return UserGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(u UserGroup) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := UserGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("user_group", u, err)
 }
 
 func DecodeUserGroup(req Req) (UserGroup, error) {
@@ -404,7 +1061,35 @@ func GetUser(req Req) {
 }
 
 func GetUserAll(req Req) {
-	req.Respond(UserGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("user", User{}, func(add func(interface{}) error) error {
+				_, err := UserGetByQueryEach(q, nil, func(u User) error {
+					return add(u)
+				})
+				return err
+			})
+			return
+		}
+		u, total, err := UserGetByQuery(q, nil)
+		req.RespondList("user", u, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("user", User{}, func(add func(interface{}) error) error {
+			return UserGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(u User) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := UserGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("user", u, err)
 }
 
 func CreateUser(req Req) {
@@ -413,7 +1098,13 @@ func CreateUser(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(UserCreate(u, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	u, err = UserCreate(u, tx)
+	req.RespondTx(tx, u, err)
 }
 
 func UpdateUser(req Req) {
@@ -422,19 +1113,64 @@ func UpdateUser(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(UserUpdate(u, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	u, err = UserUpdateVersioned(u, tx)
+	req.RespondTx(tx, u, err)
 }
 
 func DeleteUser(req Req) {
-	req.Respond(UserDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("user", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	u, err := UserDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, u, err)
+}
+
+func RestoreUser(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	u, err := UserRestore(req.IntParam, tx)
+	req.RespondTx(tx, u, err)
 }
 
 func GetUserByFilterInt(req Req) {
-	req.Respond(UserGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("user", User{}, func(add func(interface{}) error) error {
+			return UserGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(u User) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := UserGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("user", u, err)
 }
 
 func GetUserByFilterStr(req Req) {
-	req.Respond(UserGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("user", User{}, func(add func(interface{}) error) error {
+			return UserGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(u User) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := UserGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("user", u, err)
 }
 
 func DecodeUser(req Req) (User, error) {
@@ -450,7 +1186,35 @@ func GetEquipmentGroup(req Req) {
 }
 
 func GetEquipmentGroupAll(req Req) {
-	req.Respond(EquipmentGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("equipment_group", EquipmentGroup{}, func(add func(interface{}) error) error {
+				_, err := EquipmentGroupGetByQueryEach(q, nil, func(e EquipmentGroup) error {
+					return add(e)
+				})
+				return err
+			})
+			return
+		}
+		e, total, err := EquipmentGroupGetByQuery(q, nil)
+		req.RespondList("equipment_group", e, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("equipment_group", EquipmentGroup{}, func(add func(interface{}) error) error {
+			return EquipmentGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(e EquipmentGroup) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := EquipmentGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("equipment_group", e, err)
 }
 
 func CreateEquipmentGroup(req Req) {
@@ -459,7 +1223,13 @@ func CreateEquipmentGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(EquipmentGroupCreate(e, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	e, err = EquipmentGroupCreate(e, tx)
+	req.RespondTx(tx, e, err)
 }
 
 func UpdateEquipmentGroup(req Req) {
@@ -468,19 +1238,64 @@ func UpdateEquipmentGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(EquipmentGroupUpdate(e, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	e, err = EquipmentGroupUpdateVersioned(e, tx)
+	req.RespondTx(tx, e, err)
 }
 
 func DeleteEquipmentGroup(req Req) {
-	req.Respond(EquipmentGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("equipment_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	e, err := EquipmentGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, e, err)
+}
+
+func RestoreEquipmentGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	e, err := EquipmentGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, e, err)
 }
 
 func GetEquipmentGroupByFilterInt(req Req) {
-	req.Respond(EquipmentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("equipment_group", EquipmentGroup{}, func(add func(interface{}) error) error {
+			return EquipmentGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(e EquipmentGroup) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := EquipmentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("equipment_group", e, err)
 }
 
 func GetEquipmentGroupByFilterStr(req Req) {
-	req.Respond(EquipmentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("equipment_group", EquipmentGroup{}, func(add func(interface{}) error) error {
+			return EquipmentGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(e EquipmentGroup) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := EquipmentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("equipment_group", e, err)
 }
 
 func DecodeEquipmentGroup(req Req) (EquipmentGroup, error) {
@@ -496,7 +1311,35 @@ func GetEquipment(req Req) {
 }
 
 func GetEquipmentAll(req Req) {
-	req.Respond(EquipmentGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("equipment", Equipment{}, func(add func(interface{}) error) error {
+				_, err := EquipmentGetByQueryEach(q, nil, func(e Equipment) error {
+					return add(e)
+				})
+				return err
+			})
+			return
+		}
+		e, total, err := EquipmentGetByQuery(q, nil)
+		req.RespondList("equipment", e, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("equipment", Equipment{}, func(add func(interface{}) error) error {
+			return EquipmentGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(e Equipment) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := EquipmentGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("equipment", e, err)
 }
 
 func CreateEquipment(req Req) {
@@ -505,7 +1348,13 @@ func CreateEquipment(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(EquipmentCreate(e, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	e, err = EquipmentCreate(e, tx)
+	req.RespondTx(tx, e, err)
 }
 
 func UpdateEquipment(req Req) {
@@ -514,19 +1363,64 @@ func UpdateEquipment(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(EquipmentUpdate(e, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	e, err = EquipmentUpdateVersioned(e, tx)
+	req.RespondTx(tx, e, err)
 }
 
 func DeleteEquipment(req Req) {
-	req.Respond(EquipmentDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("equipment", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	e, err := EquipmentDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, e, err)
+}
+
+func RestoreEquipment(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	e, err := EquipmentRestore(req.IntParam, tx)
+	req.RespondTx(tx, e, err)
 }
 
 func GetEquipmentByFilterInt(req Req) {
-	req.Respond(EquipmentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("equipment", Equipment{}, func(add func(interface{}) error) error {
+			return EquipmentGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(e Equipment) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := EquipmentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("equipment", e, err)
 }
 
 func GetEquipmentByFilterStr(req Req) {
-	req.Respond(EquipmentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("equipment", Equipment{}, func(add func(interface{}) error) error {
+			return EquipmentGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(e Equipment) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := EquipmentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("equipment", e, err)
 }
 
 func DecodeEquipment(req Req) (Equipment, error) {
@@ -542,7 +1436,35 @@ func GetOperationGroup(req Req) {
 }
 
 func GetOperationGroupAll(req Req) {
-	req.Respond(OperationGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("operation_group", OperationGroup{}, func(add func(interface{}) error) error {
+				_, err := OperationGroupGetByQueryEach(q, nil, func(o OperationGroup) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OperationGroupGetByQuery(q, nil)
+		req.RespondList("operation_group", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation_group", OperationGroup{}, func(add func(interface{}) error) error {
+			return OperationGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o OperationGroup) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation_group", o, err)
 }
 
 func CreateOperationGroup(req Req) {
@@ -551,7 +1473,13 @@ func CreateOperationGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationGroupCreate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationGroupCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOperationGroup(req Req) {
@@ -560,19 +1488,64 @@ func UpdateOperationGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationGroupUpdate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationGroupUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOperationGroup(req Req) {
-	req.Respond(OperationGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("operation_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	o, err := OperationGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOperationGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OperationGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOperationGroupByFilterInt(req Req) {
-	req.Respond(OperationGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation_group", OperationGroup{}, func(add func(interface{}) error) error {
+			return OperationGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o OperationGroup) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation_group", o, err)
 }
 
 func GetOperationGroupByFilterStr(req Req) {
-	req.Respond(OperationGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation_group", OperationGroup{}, func(add func(interface{}) error) error {
+			return OperationGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o OperationGroup) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation_group", o, err)
 }
 
 func DecodeOperationGroup(req Req) (OperationGroup, error) {
@@ -588,7 +1561,35 @@ func GetOperation(req Req) {
 }
 
 func GetOperationAll(req Req) {
-	req.Respond(OperationGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("operation", Operation{}, func(add func(interface{}) error) error {
+				_, err := OperationGetByQueryEach(q, nil, func(o Operation) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OperationGetByQuery(q, nil)
+		req.RespondList("operation", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation", Operation{}, func(add func(interface{}) error) error {
+			return OperationGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o Operation) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation", o, err)
 }
 
 func CreateOperation(req Req) {
@@ -597,7 +1598,21 @@ func CreateOperation(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationCreate(o, nil))
+	if o.Price != 0 && !req.HasAddAccess(ADD_CHANGE_PRICE) {
+		req.Respond(nil, ErrAccessDenied)
+		return
+	}
+	if o.EquipmentPrice != 0 && !req.HasAddAccess(ADD_CHANGE_PRICE) {
+		req.Respond(nil, ErrAccessDenied)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOperation(req Req) {
@@ -606,19 +1621,77 @@ func UpdateOperation(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationUpdate(o, nil))
+	prev, err := OperationGet(o.Id, nil)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	if prev.Price != o.Price && !req.HasAddAccess(ADD_CHANGE_PRICE) {
+		req.Respond(nil, ErrAccessDenied)
+		return
+	}
+	if prev.EquipmentPrice != o.EquipmentPrice && !req.HasAddAccess(ADD_CHANGE_PRICE) {
+		req.Respond(nil, ErrAccessDenied)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOperation(req Req) {
-	req.Respond(OperationDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("operation", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	o, err := OperationDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOperation(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OperationRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOperationByFilterInt(req Req) {
-	req.Respond(OperationGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation", Operation{}, func(add func(interface{}) error) error {
+			return OperationGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o Operation) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation", o, err)
 }
 
 func GetOperationByFilterStr(req Req) {
-	req.Respond(OperationGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation", Operation{}, func(add func(interface{}) error) error {
+			return OperationGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o Operation) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation", o, err)
 }
 
 func DecodeOperation(req Req) (Operation, error) {
@@ -634,7 +1707,35 @@ func GetProductGroup(req Req) {
 }
 
 func GetProductGroupAll(req Req) {
-	req.Respond(ProductGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product_group", ProductGroup{}, func(add func(interface{}) error) error {
+				_, err := ProductGroupGetByQueryEach(q, nil, func(p ProductGroup) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProductGroupGetByQuery(q, nil)
+		req.RespondList("product_group", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_group", ProductGroup{}, func(add func(interface{}) error) error {
+			return ProductGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProductGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_group", p, err)
 }
 
 func CreateProductGroup(req Req) {
@@ -643,7 +1744,13 @@ func CreateProductGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductGroupCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductGroupCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProductGroup(req Req) {
@@ -652,19 +1759,64 @@ func UpdateProductGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductGroupUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductGroupUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProductGroup(req Req) {
-	req.Respond(ProductGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("product_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	p, err := ProductGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProductGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProductGroupByFilterInt(req Req) {
-	req.Respond(ProductGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_group", ProductGroup{}, func(add func(interface{}) error) error {
+			return ProductGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProductGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_group", p, err)
 }
 
 func GetProductGroupByFilterStr(req Req) {
-	req.Respond(ProductGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_group", ProductGroup{}, func(add func(interface{}) error) error {
+			return ProductGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProductGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_group", p, err)
 }
 
 func DecodeProductGroup(req Req) (ProductGroup, error) {
@@ -680,7 +1832,35 @@ func GetProduct(req Req) {
 }
 
 func GetProductAll(req Req) {
-	req.Respond(ProductGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product", Product{}, func(add func(interface{}) error) error {
+				_, err := ProductGetByQueryEach(q, nil, func(p Product) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProductGetByQuery(q, nil)
+		req.RespondList("product", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product", Product{}, func(add func(interface{}) error) error {
+			return ProductGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p Product) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product", p, err)
 }
 
 func CreateProduct(req Req) {
@@ -689,7 +1869,13 @@ func CreateProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProduct(req Req) {
@@ -698,19 +1884,64 @@ func UpdateProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProduct(req Req) {
-	req.Respond(ProductDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("product", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	p, err := ProductDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProduct(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProductByFilterInt(req Req) {
-	req.Respond(ProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product", Product{}, func(add func(interface{}) error) error {
+			return ProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p Product) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product", p, err)
 }
 
 func GetProductByFilterStr(req Req) {
-	req.Respond(ProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product", Product{}, func(add func(interface{}) error) error {
+			return ProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p Product) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product", p, err)
 }
 
 func DecodeProduct(req Req) (Product, error) {
@@ -726,7 +1957,35 @@ func GetContragentGroup(req Req) {
 }
 
 func GetContragentGroupAll(req Req) {
-	req.Respond(ContragentGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("contragent_group", ContragentGroup{}, func(add func(interface{}) error) error {
+				_, err := ContragentGroupGetByQueryEach(q, nil, func(c ContragentGroup) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := ContragentGroupGetByQuery(q, nil)
+		req.RespondList("contragent_group", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("contragent_group", ContragentGroup{}, func(add func(interface{}) error) error {
+			return ContragentGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c ContragentGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contragent_group", c, err)
 }
 
 func CreateContragentGroup(req Req) {
@@ -735,7 +1994,13 @@ func CreateContragentGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ContragentGroupCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ContragentGroupCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateContragentGroup(req Req) {
@@ -744,19 +2009,64 @@ func UpdateContragentGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ContragentGroupUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ContragentGroupUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteContragentGroup(req Req) {
-	req.Respond(ContragentGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("contragent_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := ContragentGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreContragentGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := ContragentGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetContragentGroupByFilterInt(req Req) {
-	req.Respond(ContragentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("contragent_group", ContragentGroup{}, func(add func(interface{}) error) error {
+			return ContragentGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c ContragentGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contragent_group", c, err)
 }
 
 func GetContragentGroupByFilterStr(req Req) {
-	req.Respond(ContragentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("contragent_group", ContragentGroup{}, func(add func(interface{}) error) error {
+			return ContragentGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c ContragentGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contragent_group", c, err)
 }
 
 func DecodeContragentGroup(req Req) (ContragentGroup, error) {
@@ -772,7 +2082,35 @@ func GetContragent(req Req) {
 }
 
 func GetContragentAll(req Req) {
-	req.Respond(ContragentGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("contragent", Contragent{}, func(add func(interface{}) error) error {
+				_, err := ContragentGetByQueryEach(q, nil, func(c Contragent) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := ContragentGetByQuery(q, nil)
+		req.RespondList("contragent", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("contragent", Contragent{}, func(add func(interface{}) error) error {
+			return ContragentGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c Contragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contragent", c, err)
 }
 
 func CreateContragent(req Req) {
@@ -781,7 +2119,13 @@ func CreateContragent(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ContragentCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ContragentCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateContragent(req Req) {
@@ -790,19 +2134,64 @@ func UpdateContragent(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ContragentUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ContragentUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteContragent(req Req) {
-	req.Respond(ContragentDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("contragent", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := ContragentDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
 }
 
-func GetContragentByFilterInt(req Req) {
-	req.Respond(ContragentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+func RestoreContragent(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := ContragentRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
+}
+
+func GetContragentByFilterInt(req Req) {
+	if req.Exporting() {
+		req.Export("contragent", Contragent{}, func(add func(interface{}) error) error {
+			return ContragentGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c Contragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contragent", c, err)
 }
 
 func GetContragentByFilterStr(req Req) {
-	req.Respond(ContragentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("contragent", Contragent{}, func(add func(interface{}) error) error {
+			return ContragentGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c Contragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contragent", c, err)
 }
 
 func DecodeContragent(req Req) (Contragent, error) {
@@ -814,7 +2203,16 @@ func DecodeContragent(req Req) (Contragent, error) {
 }
 
 func GetContragentFindByContragentSearchContactSearch(req Req) {
-	req.Respond(ContragentFindByContragentSearchContactSearch(req.StrParam))
+	if req.Exporting() {
+		req.Export("contragent", Contragent{}, func(add func(interface{}) error) error {
+			return ContragentFindByContragentSearchContactSearchEach(req.StrParam, func(c Contragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContragentFindByContragentSearchContactSearch(req.StrParam)
+	req.RespondRows("contragent", c, err)
 }
 
 func GetLegal(req Req) {
@@ -822,7 +2220,35 @@ func GetLegal(req Req) {
 }
 
 func GetLegalAll(req Req) {
-	req.Respond(LegalGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("legal", Legal{}, func(add func(interface{}) error) error {
+				_, err := LegalGetByQueryEach(q, nil, func(l Legal) error {
+					return add(l)
+				})
+				return err
+			})
+			return
+		}
+		l, total, err := LegalGetByQuery(q, nil)
+		req.RespondList("legal", l, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("legal", Legal{}, func(add func(interface{}) error) error {
+			return LegalGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(l Legal) error {
+				return add(l)
+			})
+		})
+		return
+	}
+	l, err := LegalGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("legal", l, err)
 }
 
 func CreateLegal(req Req) {
@@ -831,7 +2257,13 @@ func CreateLegal(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(LegalCreate(l, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	l, err = LegalCreate(l, tx)
+	req.RespondTx(tx, l, err)
 }
 
 func UpdateLegal(req Req) {
@@ -840,19 +2272,64 @@ func UpdateLegal(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(LegalUpdate(l, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	l, err = LegalUpdateVersioned(l, tx)
+	req.RespondTx(tx, l, err)
 }
 
 func DeleteLegal(req Req) {
-	req.Respond(LegalDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("legal", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	l, err := LegalDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, l, err)
+}
+
+func RestoreLegal(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	l, err := LegalRestore(req.IntParam, tx)
+	req.RespondTx(tx, l, err)
 }
 
 func GetLegalByFilterInt(req Req) {
-	req.Respond(LegalGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("legal", Legal{}, func(add func(interface{}) error) error {
+			return LegalGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(l Legal) error {
+				return add(l)
+			})
+		})
+		return
+	}
+	l, err := LegalGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("legal", l, err)
 }
 
 func GetLegalByFilterStr(req Req) {
-	req.Respond(LegalGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("legal", Legal{}, func(add func(interface{}) error) error {
+			return LegalGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(l Legal) error {
+				return add(l)
+			})
+		})
+		return
+	}
+	l, err := LegalGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("legal", l, err)
 }
 
 func DecodeLegal(req Req) (Legal, error) {
@@ -868,7 +2345,35 @@ func GetContact(req Req) {
 }
 
 func GetContactAll(req Req) {
-	req.Respond(ContactGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("contact", Contact{}, func(add func(interface{}) error) error {
+				_, err := ContactGetByQueryEach(q, nil, func(c Contact) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := ContactGetByQuery(q, nil)
+		req.RespondList("contact", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("contact", Contact{}, func(add func(interface{}) error) error {
+			return ContactGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c Contact) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContactGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contact", c, err)
 }
 
 func CreateContact(req Req) {
@@ -877,7 +2382,13 @@ func CreateContact(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ContactCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ContactCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateContact(req Req) {
@@ -886,19 +2397,64 @@ func UpdateContact(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ContactUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = ContactUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteContact(req Req) {
-	req.Respond(ContactDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("contact", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := ContactDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreContact(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := ContactRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetContactByFilterInt(req Req) {
-	req.Respond(ContactGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("contact", Contact{}, func(add func(interface{}) error) error {
+			return ContactGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c Contact) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContactGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contact", c, err)
 }
 
 func GetContactByFilterStr(req Req) {
-	req.Respond(ContactGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("contact", Contact{}, func(add func(interface{}) error) error {
+			return ContactGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c Contact) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := ContactGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("contact", c, err)
 }
 
 func DecodeContact(req Req) (Contact, error) {
@@ -914,7 +2470,35 @@ func GetOrderingStatus(req Req) {
 }
 
 func GetOrderingStatusAll(req Req) {
-	req.Respond(OrderingStatusGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("ordering_status", OrderingStatus{}, func(add func(interface{}) error) error {
+				_, err := OrderingStatusGetByQueryEach(q, nil, func(o OrderingStatus) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OrderingStatusGetByQuery(q, nil)
+		req.RespondList("ordering_status", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("ordering_status", OrderingStatus{}, func(add func(interface{}) error) error {
+			return OrderingStatusGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o OrderingStatus) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingStatusGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("ordering_status", o, err)
 }
 
 func CreateOrderingStatus(req Req) {
@@ -923,7 +2507,13 @@ func CreateOrderingStatus(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OrderingStatusCreate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OrderingStatusCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOrderingStatus(req Req) {
@@ -932,19 +2522,64 @@ func UpdateOrderingStatus(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OrderingStatusUpdate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OrderingStatusUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOrderingStatus(req Req) {
-	req.Respond(OrderingStatusDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("ordering_status", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	o, err := OrderingStatusDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOrderingStatus(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OrderingStatusRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOrderingStatusByFilterInt(req Req) {
-	req.Respond(OrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("ordering_status", OrderingStatus{}, func(add func(interface{}) error) error {
+			return OrderingStatusGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o OrderingStatus) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("ordering_status", o, err)
 }
 
 func GetOrderingStatusByFilterStr(req Req) {
-	req.Respond(OrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("ordering_status", OrderingStatus{}, func(add func(interface{}) error) error {
+			return OrderingStatusGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o OrderingStatus) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("ordering_status", o, err)
 }
 
 func DecodeOrderingStatus(req Req) (OrderingStatus, error) {
@@ -960,7 +2595,35 @@ func GetOrderingState(req Req) {
 }
 
 func GetOrderingStateAll(req Req) {
-	req.Respond(OrderingStateGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("ordering_state", OrderingState{}, func(add func(interface{}) error) error {
+				_, err := OrderingStateGetByQueryEach(q, nil, func(o OrderingState) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OrderingStateGetByQuery(q, nil)
+		req.RespondList("ordering_state", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("ordering_state", OrderingState{}, func(add func(interface{}) error) error {
+			return OrderingStateGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o OrderingState) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingStateGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("ordering_state", o, err)
 }
 
 func CreateOrderingState(req Req) {
@@ -969,7 +2632,13 @@ func CreateOrderingState(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OrderingStateCreate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OrderingStateCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOrderingState(req Req) {
@@ -978,19 +2647,64 @@ func UpdateOrderingState(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OrderingStateUpdate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OrderingStateUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOrderingState(req Req) {
-	req.Respond(OrderingStateDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("ordering_state", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	o, err := OrderingStateDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOrderingState(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OrderingStateRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOrderingStateByFilterInt(req Req) {
-	req.Respond(OrderingStateGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("ordering_state", OrderingState{}, func(add func(interface{}) error) error {
+			return OrderingStateGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o OrderingState) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingStateGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("ordering_state", o, err)
 }
 
 func GetOrderingStateByFilterStr(req Req) {
-	req.Respond(OrderingStateGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("ordering_state", OrderingState{}, func(add func(interface{}) error) error {
+			return OrderingStateGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o OrderingState) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingStateGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("ordering_state", o, err)
 }
 
 func DecodeOrderingState(req Req) (OrderingState, error) {
@@ -1002,11 +2716,53 @@ func DecodeOrderingState(req Req) (OrderingState, error) {
 }
 
 func GetOrdering(req Req) {
-	req.Respond(OrderingGet(req.IntParam, nil))
+	o, err := OrderingGet(req.IntParam, nil)
+	if err == nil && req.OwnOnly() && o.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(o, err)
 }
 
 func GetOrderingAll(req Req) {
-	req.Respond(OrderingGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("ordering", Ordering{}, func(add func(interface{}) error) error {
+				_, err := OrderingGetByQueryEach(q, nil, func(o Ordering) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OrderingGetByQuery(q, nil)
+		req.RespondList("ordering", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("ordering", Ordering{}, func(add func(interface{}) error) error {
+			return OrderingGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o Ordering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		o = OrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func CreateOrdering(req Req) {
@@ -1015,7 +2771,13 @@ func CreateOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OrderingCreate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OrderingCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOrdering(req Req) {
@@ -1024,23 +2786,92 @@ func UpdateOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OrderingUpdate(o, nil))
+	if req.OwnOnly() {
+		prev, err := OrderingGet(o.Id, nil)
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if prev.UserId != req.UserId || o.UserId != req.UserId {
+			req.Respond(nil, ErrNotOwner)
+			return
+		}
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OrderingUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UnRealizeOrdering(req Req) {
-	req.Respond(OrderingDelete(req.IntParam, nil, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OrderingDelete(req.IntParam, tx, true)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOrdering(req Req) {
-	req.Respond(OrderingDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OrderingDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOrdering(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OrderingRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOrderingByFilterInt(req Req) {
-	req.Respond(OrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("ordering", Ordering{}, func(add func(interface{}) error) error {
+			return OrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o Ordering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		o = OrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetOrderingByFilterStr(req Req) {
-	req.Respond(OrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("ordering", Ordering{}, func(add func(interface{}) error) error {
+			return OrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o Ordering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		o = OrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func DecodeOrdering(req Req) (Ordering, error) {
@@ -1052,22 +2883,67 @@ func DecodeOrdering(req Req) (Ordering, error) {
 }
 
 func RealizedOrdering(req Req) {
-	req.Respond(OrderingRealized(req.IntParam, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OrderingRealized(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOrderingBetweenCreatedAt(req Req) {
-	req.Respond(OrderingGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering", Ordering{}, func(add func(interface{}) error) error {
+			return OrderingGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o Ordering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = OrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetOrderingBetweenDeadlineAt(req Req) {
-	req.Respond(OrderingGetBetweenDeadlineAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering", Ordering{}, func(add func(interface{}) error) error {
+			return OrderingGetBetweenDeadlineAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o Ordering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OrderingGetBetweenDeadlineAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = OrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetOrderingCashSumSumBefore(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(OrderingCashSumGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetOrderingSumByFilter(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+		!(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(OrderingGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
@@ -1076,7 +2952,35 @@ func GetOwner(req Req) {
 }
 
 func GetOwnerAll(req Req) {
-	req.Respond(OwnerGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("owner", Owner{}, func(add func(interface{}) error) error {
+				_, err := OwnerGetByQueryEach(q, nil, func(o Owner) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OwnerGetByQuery(q, nil)
+		req.RespondList("owner", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("owner", Owner{}, func(add func(interface{}) error) error {
+			return OwnerGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o Owner) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OwnerGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("owner", o, err)
 }
 
 func CreateOwner(req Req) {
@@ -1085,7 +2989,13 @@ func CreateOwner(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OwnerCreate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OwnerCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOwner(req Req) {
@@ -1094,19 +3004,64 @@ func UpdateOwner(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OwnerUpdate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OwnerUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOwner(req Req) {
-	req.Respond(OwnerDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("owner", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	o, err := OwnerDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOwner(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OwnerRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOwnerByFilterInt(req Req) {
-	req.Respond(OwnerGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("owner", Owner{}, func(add func(interface{}) error) error {
+			return OwnerGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o Owner) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OwnerGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("owner", o, err)
 }
 
 func GetOwnerByFilterStr(req Req) {
-	req.Respond(OwnerGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("owner", Owner{}, func(add func(interface{}) error) error {
+			return OwnerGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o Owner) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OwnerGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("owner", o, err)
 }
 
 func DecodeOwner(req Req) (Owner, error) {
@@ -1118,11 +3073,53 @@ func DecodeOwner(req Req) (Owner, error) {
 }
 
 func GetInvoice(req Req) {
-	req.Respond(InvoiceGet(req.IntParam, nil))
+	i, err := InvoiceGet(req.IntParam, nil)
+	if err == nil && req.OwnOnly() && i.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(i, err)
 }
 
 func GetInvoiceAll(req Req) {
-	req.Respond(InvoiceGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("invoice", Invoice{}, func(add func(interface{}) error) error {
+				_, err := InvoiceGetByQueryEach(q, nil, func(i Invoice) error {
+					return add(i)
+				})
+				return err
+			})
+			return
+		}
+		i, total, err := InvoiceGetByQuery(q, nil)
+		req.RespondList("invoice", i, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("invoice", Invoice{}, func(add func(interface{}) error) error {
+			return InvoiceGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(i Invoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := InvoiceGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		i = InvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func CreateInvoice(req Req) {
@@ -1131,7 +3128,13 @@ func CreateInvoice(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(InvoiceCreate(i, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err = InvoiceCreate(i, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func UpdateInvoice(req Req) {
@@ -1140,23 +3143,92 @@ func UpdateInvoice(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(InvoiceUpdate(i, nil))
+	if req.OwnOnly() {
+		prev, err := InvoiceGet(i.Id, nil)
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if prev.UserId != req.UserId || i.UserId != req.UserId {
+			req.Respond(nil, ErrNotOwner)
+			return
+		}
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err = InvoiceUpdateVersioned(i, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func UnRealizeInvoice(req Req) {
-	req.Respond(InvoiceDelete(req.IntParam, nil, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := InvoiceDelete(req.IntParam, tx, true)
+	req.RespondTx(tx, i, err)
 }
 
 func DeleteInvoice(req Req) {
-	req.Respond(InvoiceDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := InvoiceDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, i, err)
+}
+
+func RestoreInvoice(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := InvoiceRestore(req.IntParam, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func GetInvoiceByFilterInt(req Req) {
-	req.Respond(InvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("invoice", Invoice{}, func(add func(interface{}) error) error {
+			return InvoiceGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(i Invoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := InvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		i = InvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func GetInvoiceByFilterStr(req Req) {
-	req.Respond(InvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("invoice", Invoice{}, func(add func(interface{}) error) error {
+			return InvoiceGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(i Invoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := InvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		i = InvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func DecodeInvoice(req Req) (Invoice, error) {
@@ -1168,27 +3240,102 @@ func DecodeInvoice(req Req) (Invoice, error) {
 }
 
 func RealizedInvoice(req Req) {
-	req.Respond(InvoiceRealized(req.IntParam, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := InvoiceRealized(req.IntParam, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func GetInvoiceBetweenCreatedAt(req Req) {
-	req.Respond(InvoiceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("invoice", Invoice{}, func(add func(interface{}) error) error {
+			return InvoiceGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(i Invoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := InvoiceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i = InvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func GetInvoiceCashSumSumBefore(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(InvoiceCashSumGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetInvoiceSumByFilter(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+		!(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(InvoiceGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
 func GetItemToInvoice(req Req) {
-	req.Respond(ItemToInvoiceGet(req.IntParam, nil))
+	i, err := ItemToInvoiceGet(req.IntParam, nil)
+	if err == nil {
+		err = req.OwnDocCheck("invoice", i.InvoiceId)
+	}
+	req.Respond(i, err)
 }
 
 func GetItemToInvoiceAll(req Req) {
-	req.Respond(ItemToInvoiceGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("invoice_id", "invoice", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("item_to_invoice", ItemToInvoice{}, func(add func(interface{}) error) error {
+				_, err := ItemToInvoiceGetByQueryEach(q, nil, func(i ItemToInvoice) error {
+					return add(i)
+				})
+				return err
+			})
+			return
+		}
+		i, total, err := ItemToInvoiceGetByQuery(q, nil)
+		req.RespondList("item_to_invoice", i, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("item_to_invoice", ItemToInvoice{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("invoice")
+			if err != nil {
+				return err
+			}
+			return ItemToInvoiceGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(i ItemToInvoice) error {
+				if owned != nil && !owned[i.InvoiceId] {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := ItemToInvoiceGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		i, err = ItemToInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("item_to_invoice", i, err)
 }
 
 func CreateItemToInvoice(req Req) {
@@ -1197,7 +3344,18 @@ func CreateItemToInvoice(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ItemToInvoiceCreate(i, nil))
+	err = req.OwnDocCheck("invoice", i.InvoiceId)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err = ItemToInvoiceCreate(i, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func UpdateItemToInvoice(req Req) {
@@ -1206,19 +3364,95 @@ func UpdateItemToInvoice(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ItemToInvoiceUpdate(i, nil))
+	err = ItemToInvoiceOwnCheck(req, i.Id)
+	if err == nil {
+		err = req.OwnDocCheck("invoice", i.InvoiceId)
+	}
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err = ItemToInvoiceUpdateVersioned(i, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func DeleteItemToInvoice(req Req) {
-	req.Respond(ItemToInvoiceDelete(req.IntParam, nil, false))
+	if err := ItemToInvoiceOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := ItemToInvoiceDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, i, err)
+}
+
+func RestoreItemToInvoice(req Req) {
+	if err := ItemToInvoiceOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := ItemToInvoiceRestore(req.IntParam, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func GetItemToInvoiceByFilterInt(req Req) {
-	req.Respond(ItemToInvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("item_to_invoice", ItemToInvoice{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("invoice")
+			if err != nil {
+				return err
+			}
+			return ItemToInvoiceGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(i ItemToInvoice) error {
+				if owned != nil && !owned[i.InvoiceId] {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := ItemToInvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		i, err = ItemToInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("item_to_invoice", i, err)
 }
 
 func GetItemToInvoiceByFilterStr(req Req) {
-	req.Respond(ItemToInvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("item_to_invoice", ItemToInvoice{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("invoice")
+			if err != nil {
+				return err
+			}
+			return ItemToInvoiceGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(i ItemToInvoice) error {
+				if owned != nil && !owned[i.InvoiceId] {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := ItemToInvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		i, err = ItemToInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("item_to_invoice", i, err)
 }
 
 func DecodeItemToInvoice(req Req) (ItemToInvoice, error) {
@@ -1230,10 +3464,19 @@ func DecodeItemToInvoice(req Req) (ItemToInvoice, error) {
 }
 
 func GetItemToInvoiceCostSumBefore(req Req) {
+	if err := req.OwnDocFilter("invoice", req.StrParam, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(ItemToInvoiceCostGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetItemToInvoiceSumByFilter(req Req) {
+	if err := req.OwnDocFilter("invoice", req.StrParam, req.IntParam); err != nil &&
+		req.OwnDocFilter("invoice", req.Str2Param, req.Int2Param) != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(ItemToInvoiceGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
@@ -1242,7 +3485,35 @@ func GetProductToOrderingStatus(req Req) {
 }
 
 func GetProductToOrderingStatusAll(req Req) {
-	req.Respond(ProductToOrderingStatusGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product_to_ordering_status", ProductToOrderingStatus{}, func(add func(interface{}) error) error {
+				_, err := ProductToOrderingStatusGetByQueryEach(q, nil, func(p ProductToOrderingStatus) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProductToOrderingStatusGetByQuery(q, nil)
+		req.RespondList("product_to_ordering_status", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_to_ordering_status", ProductToOrderingStatus{}, func(add func(interface{}) error) error {
+			return ProductToOrderingStatusGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProductToOrderingStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToOrderingStatusGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_to_ordering_status", p, err)
 }
 
 func CreateProductToOrderingStatus(req Req) {
@@ -1251,7 +3522,13 @@ func CreateProductToOrderingStatus(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToOrderingStatusCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToOrderingStatusCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProductToOrderingStatus(req Req) {
@@ -1260,19 +3537,64 @@ func UpdateProductToOrderingStatus(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToOrderingStatusUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToOrderingStatusUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProductToOrderingStatus(req Req) {
-	req.Respond(ProductToOrderingStatusDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("product_to_ordering_status", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	p, err := ProductToOrderingStatusDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProductToOrderingStatus(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductToOrderingStatusRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProductToOrderingStatusByFilterInt(req Req) {
-	req.Respond(ProductToOrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_to_ordering_status", ProductToOrderingStatus{}, func(add func(interface{}) error) error {
+			return ProductToOrderingStatusGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProductToOrderingStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToOrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_to_ordering_status", p, err)
 }
 
 func GetProductToOrderingStatusByFilterStr(req Req) {
-	req.Respond(ProductToOrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_to_ordering_status", ProductToOrderingStatus{}, func(add func(interface{}) error) error {
+			return ProductToOrderingStatusGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProductToOrderingStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToOrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_to_ordering_status", p, err)
 }
 
 func DecodeProductToOrderingStatus(req Req) (ProductToOrderingStatus, error) {
@@ -1284,11 +3606,56 @@ func DecodeProductToOrderingStatus(req Req) (ProductToOrderingStatus, error) {
 }
 
 func GetProductToOrdering(req Req) {
-	req.Respond(ProductToOrderingGet(req.IntParam, nil))
+	p, err := ProductToOrderingGet(req.IntParam, nil)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", p.OrderingId)
+	}
+	req.Respond(p, err)
 }
 
 func GetProductToOrderingAll(req Req) {
-	req.Respond(ProductToOrderingGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("ordering_id", "ordering", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("product_to_ordering", ProductToOrdering{}, func(add func(interface{}) error) error {
+				_, err := ProductToOrderingGetByQueryEach(q, nil, func(p ProductToOrdering) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProductToOrderingGetByQuery(q, nil)
+		req.RespondList("product_to_ordering", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_to_ordering", ProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return ProductToOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToOrderingGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		p, err = ProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func CreateProductToOrdering(req Req) {
@@ -1297,7 +3664,18 @@ func CreateProductToOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToOrderingCreate(p, nil))
+	err = req.OwnDocCheck("ordering", p.OrderingId)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToOrderingCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProductToOrdering(req Req) {
@@ -1306,19 +3684,95 @@ func UpdateProductToOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToOrderingUpdate(p, nil))
+	err = ProductToOrderingOwnCheck(req, p.Id)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", p.OrderingId)
+	}
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToOrderingUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProductToOrdering(req Req) {
-	req.Respond(ProductToOrderingDelete(req.IntParam, nil, false))
+	if err := ProductToOrderingOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductToOrderingDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProductToOrdering(req Req) {
+	if err := ProductToOrderingOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductToOrderingRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProductToOrderingByFilterInt(req Req) {
-	req.Respond(ProductToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_to_ordering", ProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return ProductToOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		p, err = ProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func GetProductToOrderingByFilterStr(req Req) {
-	req.Respond(ProductToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_to_ordering", ProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return ProductToOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		p, err = ProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func DecodeProductToOrdering(req Req) (ProductToOrdering, error) {
@@ -1330,19 +3784,73 @@ func DecodeProductToOrdering(req Req) (ProductToOrdering, error) {
 }
 
 func GetProductToOrderingCostSumBefore(req Req) {
+	if err := req.OwnDocFilter("ordering", req.StrParam, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(ProductToOrderingCostGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetProductToOrderingSumByFilter(req Req) {
+	if err := req.OwnDocFilter("ordering", req.StrParam, req.IntParam); err != nil &&
+		req.OwnDocFilter("ordering", req.Str2Param, req.Int2Param) != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(ProductToOrderingGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
 func GetMatherialToOrdering(req Req) {
-	req.Respond(MatherialToOrderingGet(req.IntParam, nil))
+	m, err := MatherialToOrderingGet(req.IntParam, nil)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", m.OrderingId)
+	}
+	req.Respond(m, err)
 }
 
 func GetMatherialToOrderingAll(req Req) {
-	req.Respond(MatherialToOrderingGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("ordering_id", "ordering", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_ordering", MatherialToOrdering{}, func(add func(interface{}) error) error {
+				_, err := MatherialToOrderingGetByQueryEach(q, nil, func(m MatherialToOrdering) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialToOrderingGetByQuery(q, nil)
+		req.RespondList("matherial_to_ordering", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", MatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return MatherialToOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToOrderingGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func CreateMatherialToOrdering(req Req) {
@@ -1351,7 +3859,18 @@ func CreateMatherialToOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToOrderingCreate(m, nil))
+	err = req.OwnDocCheck("ordering", m.OrderingId)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToOrderingCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherialToOrdering(req Req) {
@@ -1360,19 +3879,95 @@ func UpdateMatherialToOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToOrderingUpdate(m, nil))
+	err = MatherialToOrderingOwnCheck(req, m.Id)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", m.OrderingId)
+	}
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToOrderingUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherialToOrdering(req Req) {
-	req.Respond(MatherialToOrderingDelete(req.IntParam, nil, false))
+	if err := MatherialToOrderingOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToOrderingDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherialToOrdering(req Req) {
+	if err := MatherialToOrderingOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToOrderingRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialToOrderingByFilterInt(req Req) {
-	req.Respond(MatherialToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", MatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return MatherialToOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func GetMatherialToOrderingByFilterStr(req Req) {
-	req.Respond(MatherialToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", MatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return MatherialToOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func DecodeMatherialToOrdering(req Req) (MatherialToOrdering, error) {
@@ -1384,10 +3979,19 @@ func DecodeMatherialToOrdering(req Req) (MatherialToOrdering, error) {
 }
 
 func GetMatherialToOrderingCostSumBefore(req Req) {
+	if err := req.OwnDocFilter("ordering", req.StrParam, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(MatherialToOrderingCostGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetMatherialToOrderingSumByFilter(req Req) {
+	if err := req.OwnDocFilter("ordering", req.StrParam, req.IntParam); err != nil &&
+		req.OwnDocFilter("ordering", req.Str2Param, req.Int2Param) != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(MatherialToOrderingGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
@@ -1396,7 +4000,35 @@ func GetMatherialToProduct(req Req) {
 }
 
 func GetMatherialToProductAll(req Req) {
-	req.Respond(MatherialToProductGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_product", MatherialToProduct{}, func(add func(interface{}) error) error {
+				_, err := MatherialToProductGetByQueryEach(q, nil, func(m MatherialToProduct) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialToProductGetByQuery(q, nil)
+		req.RespondList("matherial_to_product", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_product", MatherialToProduct{}, func(add func(interface{}) error) error {
+			return MatherialToProductGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToProduct) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToProductGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_to_product", m, err)
 }
 
 func CreateMatherialToProduct(req Req) {
@@ -1405,7 +4037,13 @@ func CreateMatherialToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToProductCreate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToProductCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherialToProduct(req Req) {
@@ -1414,19 +4052,59 @@ func UpdateMatherialToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToProductUpdate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToProductUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherialToProduct(req Req) {
-	req.Respond(MatherialToProductDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToProductDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherialToProduct(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToProductRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialToProductByFilterInt(req Req) {
-	req.Respond(MatherialToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_product", MatherialToProduct{}, func(add func(interface{}) error) error {
+			return MatherialToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToProduct) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_to_product", m, err)
 }
 
 func GetMatherialToProductByFilterStr(req Req) {
-	req.Respond(MatherialToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_product", MatherialToProduct{}, func(add func(interface{}) error) error {
+			return MatherialToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToProduct) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_to_product", m, err)
 }
 
 func DecodeMatherialToProduct(req Req) (MatherialToProduct, error) {
@@ -1446,11 +4124,56 @@ func GetMatherialToProductSumByFilter(req Req) {
 }
 
 func GetOperationToOrdering(req Req) {
-	req.Respond(OperationToOrderingGet(req.IntParam, nil))
+	o, err := OperationToOrderingGet(req.IntParam, nil)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", o.OrderingId)
+	}
+	req.Respond(o, err)
 }
 
 func GetOperationToOrderingAll(req Req) {
-	req.Respond(OperationToOrderingGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("ordering_id", "ordering", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("operation_to_ordering", OperationToOrdering{}, func(add func(interface{}) error) error {
+				_, err := OperationToOrderingGetByQueryEach(q, nil, func(o OperationToOrdering) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OperationToOrderingGetByQuery(q, nil)
+		req.RespondList("operation_to_ordering", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation_to_ordering", OperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return OperationToOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o OperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationToOrderingGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		o, err = OperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func CreateOperationToOrdering(req Req) {
@@ -1459,7 +4182,18 @@ func CreateOperationToOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationToOrderingCreate(o, nil))
+	err = req.OwnDocCheck("ordering", o.OrderingId)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationToOrderingCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOperationToOrdering(req Req) {
@@ -1468,19 +4202,95 @@ func UpdateOperationToOrdering(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationToOrderingUpdate(o, nil))
+	err = OperationToOrderingOwnCheck(req, o.Id)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", o.OrderingId)
+	}
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationToOrderingUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOperationToOrdering(req Req) {
-	req.Respond(OperationToOrderingDelete(req.IntParam, nil, false))
+	if err := OperationToOrderingOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OperationToOrderingDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOperationToOrdering(req Req) {
+	if err := OperationToOrderingOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OperationToOrderingRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOperationToOrderingByFilterInt(req Req) {
-	req.Respond(OperationToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation_to_ordering", OperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return OperationToOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o OperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		o, err = OperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func GetOperationToOrderingByFilterStr(req Req) {
-	req.Respond(OperationToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation_to_ordering", OperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return OperationToOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o OperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		o, err = OperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func DecodeOperationToOrdering(req Req) (OperationToOrdering, error) {
@@ -1492,10 +4302,19 @@ func DecodeOperationToOrdering(req Req) (OperationToOrdering, error) {
 }
 
 func GetOperationToOrderingCostSumBefore(req Req) {
+	if err := req.OwnDocFilter("ordering", req.StrParam, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(OperationToOrderingCostGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetOperationToOrderingSumByFilter(req Req) {
+	if err := req.OwnDocFilter("ordering", req.StrParam, req.IntParam); err != nil &&
+		req.OwnDocFilter("ordering", req.Str2Param, req.Int2Param) != nil {
+		req.Respond(nil, err)
+		return
+	}
 	req.Respond(OperationToOrderingGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
@@ -1504,7 +4323,35 @@ func GetOperationToProduct(req Req) {
 }
 
 func GetOperationToProductAll(req Req) {
-	req.Respond(OperationToProductGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("operation_to_product", OperationToProduct{}, func(add func(interface{}) error) error {
+				_, err := OperationToProductGetByQueryEach(q, nil, func(o OperationToProduct) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := OperationToProductGetByQuery(q, nil)
+		req.RespondList("operation_to_product", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation_to_product", OperationToProduct{}, func(add func(interface{}) error) error {
+			return OperationToProductGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(o OperationToProduct) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationToProductGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation_to_product", o, err)
 }
 
 func CreateOperationToProduct(req Req) {
@@ -1513,7 +4360,13 @@ func CreateOperationToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationToProductCreate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationToProductCreate(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func UpdateOperationToProduct(req Req) {
@@ -1522,19 +4375,59 @@ func UpdateOperationToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(OperationToProductUpdate(o, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err = OperationToProductUpdateVersioned(o, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func DeleteOperationToProduct(req Req) {
-	req.Respond(OperationToProductDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OperationToProductDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, o, err)
+}
+
+func RestoreOperationToProduct(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	o, err := OperationToProductRestore(req.IntParam, tx)
+	req.RespondTx(tx, o, err)
 }
 
 func GetOperationToProductByFilterInt(req Req) {
-	req.Respond(OperationToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation_to_product", OperationToProduct{}, func(add func(interface{}) error) error {
+			return OperationToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(o OperationToProduct) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation_to_product", o, err)
 }
 
 func GetOperationToProductByFilterStr(req Req) {
-	req.Respond(OperationToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("operation_to_product", OperationToProduct{}, func(add func(interface{}) error) error {
+			return OperationToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(o OperationToProduct) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := OperationToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("operation_to_product", o, err)
 }
 
 func DecodeOperationToProduct(req Req) (OperationToProduct, error) {
@@ -1558,7 +4451,35 @@ func GetProductToProduct(req Req) {
 }
 
 func GetProductToProductAll(req Req) {
-	req.Respond(ProductToProductGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product_to_product", ProductToProduct{}, func(add func(interface{}) error) error {
+				_, err := ProductToProductGetByQueryEach(q, nil, func(p ProductToProduct) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProductToProductGetByQuery(q, nil)
+		req.RespondList("product_to_product", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_to_product", ProductToProduct{}, func(add func(interface{}) error) error {
+			return ProductToProductGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProductToProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToProductGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_to_product", p, err)
 }
 
 func CreateProductToProduct(req Req) {
@@ -1567,7 +4488,13 @@ func CreateProductToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToProductCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToProductCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProductToProduct(req Req) {
@@ -1576,19 +4503,59 @@ func UpdateProductToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProductToProductUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProductToProductUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProductToProduct(req Req) {
-	req.Respond(ProductToProductDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductToProductDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProductToProduct(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProductToProductRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProductToProductByFilterInt(req Req) {
-	req.Respond(ProductToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_to_product", ProductToProduct{}, func(add func(interface{}) error) error {
+			return ProductToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProductToProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_to_product", p, err)
 }
 
 func GetProductToProductByFilterStr(req Req) {
-	req.Respond(ProductToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("product_to_product", ProductToProduct{}, func(add func(interface{}) error) error {
+			return ProductToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProductToProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProductToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("product_to_product", p, err)
 }
 
 func DecodeProductToProduct(req Req) (ProductToProduct, error) {
@@ -1612,7 +4579,35 @@ func GetCboxCheck(req Req) {
 }
 
 func GetCboxCheckAll(req Req) {
-	req.Respond(CboxCheckGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("cbox_check", CboxCheck{}, func(add func(interface{}) error) error {
+				_, err := CboxCheckGetByQueryEach(q, nil, func(c CboxCheck) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := CboxCheckGetByQuery(q, nil)
+		req.RespondList("cbox_check", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cbox_check", CboxCheck{}, func(add func(interface{}) error) error {
+			return CboxCheckGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c CboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CboxCheckGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func CreateCboxCheck(req Req) {
@@ -1621,7 +4616,13 @@ func CreateCboxCheck(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CboxCheckCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CboxCheckCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateCboxCheck(req Req) {
@@ -1630,19 +4631,64 @@ func UpdateCboxCheck(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CboxCheckUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CboxCheckUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteCboxCheck(req Req) {
-	req.Respond(CboxCheckDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("cbox_check", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := CboxCheckDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreCboxCheck(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CboxCheckRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCboxCheckByFilterInt(req Req) {
-	req.Respond(CboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cbox_check", CboxCheck{}, func(add func(interface{}) error) error {
+			return CboxCheckGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c CboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func GetCboxCheckByFilterStr(req Req) {
-	req.Respond(CboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cbox_check", CboxCheck{}, func(add func(interface{}) error) error {
+			return CboxCheckGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c CboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func DecodeCboxCheck(req Req) (CboxCheck, error) {
@@ -1654,7 +4700,16 @@ func DecodeCboxCheck(req Req) (CboxCheck, error) {
 }
 
 func GetCboxCheckBetweenCreatedAt(req Req) {
-	req.Respond(CboxCheckGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cbox_check", CboxCheck{}, func(add func(interface{}) error) error {
+			return CboxCheckGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c CboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CboxCheckGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func GetItemToCboxCheck(req Req) {
@@ -1662,7 +4717,35 @@ func GetItemToCboxCheck(req Req) {
 }
 
 func GetItemToCboxCheckAll(req Req) {
-	req.Respond(ItemToCboxCheckGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("item_to_cbox_check", ItemToCboxCheck{}, func(add func(interface{}) error) error {
+				_, err := ItemToCboxCheckGetByQueryEach(q, nil, func(i ItemToCboxCheck) error {
+					return add(i)
+				})
+				return err
+			})
+			return
+		}
+		i, total, err := ItemToCboxCheckGetByQuery(q, nil)
+		req.RespondList("item_to_cbox_check", i, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("item_to_cbox_check", ItemToCboxCheck{}, func(add func(interface{}) error) error {
+			return ItemToCboxCheckGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(i ItemToCboxCheck) error {
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := ItemToCboxCheckGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("item_to_cbox_check", i, err)
 }
 
 func CreateItemToCboxCheck(req Req) {
@@ -1671,7 +4754,13 @@ func CreateItemToCboxCheck(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ItemToCboxCheckCreate(i, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err = ItemToCboxCheckCreate(i, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func UpdateItemToCboxCheck(req Req) {
@@ -1680,19 +4769,59 @@ func UpdateItemToCboxCheck(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ItemToCboxCheckUpdate(i, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err = ItemToCboxCheckUpdateVersioned(i, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func DeleteItemToCboxCheck(req Req) {
-	req.Respond(ItemToCboxCheckDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := ItemToCboxCheckDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, i, err)
+}
+
+func RestoreItemToCboxCheck(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	i, err := ItemToCboxCheckRestore(req.IntParam, tx)
+	req.RespondTx(tx, i, err)
 }
 
 func GetItemToCboxCheckByFilterInt(req Req) {
-	req.Respond(ItemToCboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("item_to_cbox_check", ItemToCboxCheck{}, func(add func(interface{}) error) error {
+			return ItemToCboxCheckGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(i ItemToCboxCheck) error {
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := ItemToCboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("item_to_cbox_check", i, err)
 }
 
 func GetItemToCboxCheckByFilterStr(req Req) {
-	req.Respond(ItemToCboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("item_to_cbox_check", ItemToCboxCheck{}, func(add func(interface{}) error) error {
+			return ItemToCboxCheckGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(i ItemToCboxCheck) error {
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := ItemToCboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("item_to_cbox_check", i, err)
 }
 
 func DecodeItemToCboxCheck(req Req) (ItemToCboxCheck, error) {
@@ -1712,11 +4841,53 @@ func GetItemToCboxCheckSumByFilter(req Req) {
 }
 
 func GetCashIn(req Req) {
-	req.Respond(CashInGet(req.IntParam, nil))
+	c, err := CashInGet(req.IntParam, nil)
+	if err == nil && req.OwnOnly() && c.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(c, err)
 }
 
 func GetCashInAll(req Req) {
-	req.Respond(CashInGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("cash_in", CashIn{}, func(add func(interface{}) error) error {
+				_, err := CashInGetByQueryEach(q, nil, func(c CashIn) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := CashInGetByQuery(q, nil)
+		req.RespondList("cash_in", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cash_in", CashIn{}, func(add func(interface{}) error) error {
+			return CashInGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c CashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashInGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		c = CashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func CreateCashIn(req Req) {
@@ -1725,7 +4896,13 @@ func CreateCashIn(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CashInCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CashInCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateCashIn(req Req) {
@@ -1734,23 +4911,92 @@ func UpdateCashIn(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CashInUpdate(c, nil))
+	if req.OwnOnly() {
+		prev, err := CashInGet(c.Id, nil)
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if prev.UserId != req.UserId || c.UserId != req.UserId {
+			req.Respond(nil, ErrNotOwner)
+			return
+		}
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CashInUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UnRealizeCashIn(req Req) {
-	req.Respond(CashInDelete(req.IntParam, nil, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashInDelete(req.IntParam, tx, true)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteCashIn(req Req) {
-	req.Respond(CashInDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashInDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreCashIn(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashInRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCashInByFilterInt(req Req) {
-	req.Respond(CashInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cash_in", CashIn{}, func(add func(interface{}) error) error {
+			return CashInGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c CashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		c = CashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func GetCashInByFilterStr(req Req) {
-	req.Respond(CashInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cash_in", CashIn{}, func(add func(interface{}) error) error {
+			return CashInGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c CashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		c = CashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func DecodeCashIn(req Req) (CashIn, error) {
@@ -1762,27 +5008,99 @@ func DecodeCashIn(req Req) (CashIn, error) {
 }
 
 func RealizedCashIn(req Req) {
-	req.Respond(CashInRealized(req.IntParam, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashInRealized(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCashInBetweenCreatedAt(req Req) {
-	req.Respond(CashInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_in", CashIn{}, func(add func(interface{}) error) error {
+			return CashInGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c CashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = CashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func GetCashInCashSumSumBefore(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(CashInCashSumGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetCashInSumByFilter(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+		!(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(CashInGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
 func GetCashOut(req Req) {
-	req.Respond(CashOutGet(req.IntParam, nil))
+	c, err := CashOutGet(req.IntParam, nil)
+	if err == nil && req.OwnOnly() && c.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(c, err)
 }
 
 func GetCashOutAll(req Req) {
-	req.Respond(CashOutGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("cash_out", CashOut{}, func(add func(interface{}) error) error {
+				_, err := CashOutGetByQueryEach(q, nil, func(c CashOut) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := CashOutGetByQuery(q, nil)
+		req.RespondList("cash_out", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cash_out", CashOut{}, func(add func(interface{}) error) error {
+			return CashOutGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c CashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashOutGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		c = CashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func CreateCashOut(req Req) {
@@ -1791,7 +5109,13 @@ func CreateCashOut(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CashOutCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CashOutCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateCashOut(req Req) {
@@ -1800,23 +5124,92 @@ func UpdateCashOut(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CashOutUpdate(c, nil))
+	if req.OwnOnly() {
+		prev, err := CashOutGet(c.Id, nil)
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if prev.UserId != req.UserId || c.UserId != req.UserId {
+			req.Respond(nil, ErrNotOwner)
+			return
+		}
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CashOutUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UnRealizeCashOut(req Req) {
-	req.Respond(CashOutDelete(req.IntParam, nil, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashOutDelete(req.IntParam, tx, true)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteCashOut(req Req) {
-	req.Respond(CashOutDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashOutDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreCashOut(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashOutRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCashOutByFilterInt(req Req) {
-	req.Respond(CashOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cash_out", CashOut{}, func(add func(interface{}) error) error {
+			return CashOutGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c CashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		c = CashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func GetCashOutByFilterStr(req Req) {
-	req.Respond(CashOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("cash_out", CashOut{}, func(add func(interface{}) error) error {
+			return CashOutGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c CashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		c = CashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func DecodeCashOut(req Req) (CashOut, error) {
@@ -1828,18 +5221,48 @@ func DecodeCashOut(req Req) (CashOut, error) {
 }
 
 func RealizedCashOut(req Req) {
-	req.Respond(CashOutRealized(req.IntParam, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CashOutRealized(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCashOutBetweenCreatedAt(req Req) {
-	req.Respond(CashOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_out", CashOut{}, func(add func(interface{}) error) error {
+			return CashOutGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c CashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CashOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = CashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func GetCashOutCashSumSumBefore(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(CashOutCashSumGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetCashOutSumByFilter(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+		!(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(CashOutGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
@@ -1848,7 +5271,35 @@ func GetWhs(req Req) {
 }
 
 func GetWhsAll(req Req) {
-	req.Respond(WhsGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("whs", Whs{}, func(add func(interface{}) error) error {
+				_, err := WhsGetByQueryEach(q, nil, func(w Whs) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WhsGetByQuery(q, nil)
+		req.RespondList("whs", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("whs", Whs{}, func(add func(interface{}) error) error {
+			return WhsGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(w Whs) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("whs", w, err)
 }
 
 func CreateWhs(req Req) {
@@ -1857,7 +5308,13 @@ func CreateWhs(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WhsCreate(w, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WhsCreate(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func UpdateWhs(req Req) {
@@ -1866,19 +5323,64 @@ func UpdateWhs(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WhsUpdate(w, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WhsUpdateVersioned(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func DeleteWhs(req Req) {
-	req.Respond(WhsDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("whs", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	w, err := WhsDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, w, err)
+}
+
+func RestoreWhs(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsRestore(req.IntParam, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func GetWhsByFilterInt(req Req) {
-	req.Respond(WhsGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("whs", Whs{}, func(add func(interface{}) error) error {
+			return WhsGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(w Whs) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("whs", w, err)
 }
 
 func GetWhsByFilterStr(req Req) {
-	req.Respond(WhsGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("whs", Whs{}, func(add func(interface{}) error) error {
+			return WhsGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(w Whs) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("whs", w, err)
 }
 
 func DecodeWhs(req Req) (Whs, error) {
@@ -1890,11 +5392,53 @@ func DecodeWhs(req Req) (Whs, error) {
 }
 
 func GetWhsIn(req Req) {
-	req.Respond(WhsInGet(req.IntParam, nil))
+	w, err := WhsInGet(req.IntParam, nil)
+	if err == nil && req.OwnOnly() && w.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(w, err)
 }
 
 func GetWhsInAll(req Req) {
-	req.Respond(WhsInGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("whs_in", WhsIn{}, func(add func(interface{}) error) error {
+				_, err := WhsInGetByQueryEach(q, nil, func(w WhsIn) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WhsInGetByQuery(q, nil)
+		req.RespondList("whs_in", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("whs_in", WhsIn{}, func(add func(interface{}) error) error {
+			return WhsInGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(w WhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsInGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		w = WhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func CreateWhsIn(req Req) {
@@ -1903,7 +5447,13 @@ func CreateWhsIn(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WhsInCreate(w, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WhsInCreate(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func UpdateWhsIn(req Req) {
@@ -1912,23 +5462,92 @@ func UpdateWhsIn(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WhsInUpdate(w, nil))
+	if req.OwnOnly() {
+		prev, err := WhsInGet(w.Id, nil)
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if prev.UserId != req.UserId || w.UserId != req.UserId {
+			req.Respond(nil, ErrNotOwner)
+			return
+		}
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WhsInUpdateVersioned(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func UnRealizeWhsIn(req Req) {
-	req.Respond(WhsInDelete(req.IntParam, nil, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsInDelete(req.IntParam, tx, true)
+	req.RespondTx(tx, w, err)
 }
 
 func DeleteWhsIn(req Req) {
-	req.Respond(WhsInDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsInDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, w, err)
+}
+
+func RestoreWhsIn(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsInRestore(req.IntParam, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func GetWhsInByFilterInt(req Req) {
-	req.Respond(WhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("whs_in", WhsIn{}, func(add func(interface{}) error) error {
+			return WhsInGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(w WhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		w = WhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWhsInByFilterStr(req Req) {
-	req.Respond(WhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("whs_in", WhsIn{}, func(add func(interface{}) error) error {
+			return WhsInGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(w WhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		w = WhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func DecodeWhsIn(req Req) (WhsIn, error) {
@@ -1940,31 +5559,118 @@ func DecodeWhsIn(req Req) (WhsIn, error) {
 }
 
 func RealizedWhsIn(req Req) {
-	req.Respond(WhsInRealized(req.IntParam, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsInRealized(req.IntParam, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func GetWhsInBetweenCreatedAt(req Req) {
-	req.Respond(WhsInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_in", WhsIn{}, func(add func(interface{}) error) error {
+			return WhsInGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWhsInBetweenContragentCreatedAt(req Req) {
-	req.Respond(WhsInGetBetweenContragentCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_in", WhsIn{}, func(add func(interface{}) error) error {
+			return WhsInGetBetweenContragentCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsInGetBetweenContragentCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWhsInWhsSumSumBefore(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(WhsInWhsSumGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetWhsInSumByFilter(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+		!(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(WhsInGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
 func GetWhsOut(req Req) {
-	req.Respond(WhsOutGet(req.IntParam, nil))
+	w, err := WhsOutGet(req.IntParam, nil)
+	if err == nil && req.OwnOnly() && w.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(w, err)
 }
 
 func GetWhsOutAll(req Req) {
-	req.Respond(WhsOutGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("whs_out", WhsOut{}, func(add func(interface{}) error) error {
+				_, err := WhsOutGetByQueryEach(q, nil, func(w WhsOut) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WhsOutGetByQuery(q, nil)
+		req.RespondList("whs_out", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("whs_out", WhsOut{}, func(add func(interface{}) error) error {
+			return WhsOutGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(w WhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsOutGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		w = WhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func CreateWhsOut(req Req) {
@@ -1973,7 +5679,13 @@ func CreateWhsOut(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WhsOutCreate(w, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WhsOutCreate(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func UpdateWhsOut(req Req) {
@@ -1982,23 +5694,92 @@ func UpdateWhsOut(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WhsOutUpdate(w, nil))
+	if req.OwnOnly() {
+		prev, err := WhsOutGet(w.Id, nil)
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if prev.UserId != req.UserId || w.UserId != req.UserId {
+			req.Respond(nil, ErrNotOwner)
+			return
+		}
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WhsOutUpdateVersioned(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func UnRealizeWhsOut(req Req) {
-	req.Respond(WhsOutDelete(req.IntParam, nil, true))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsOutDelete(req.IntParam, tx, true)
+	req.RespondTx(tx, w, err)
 }
 
 func DeleteWhsOut(req Req) {
-	req.Respond(WhsOutDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsOutDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, w, err)
+}
+
+func RestoreWhsOut(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsOutRestore(req.IntParam, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func GetWhsOutByFilterInt(req Req) {
-	req.Respond(WhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("whs_out", WhsOut{}, func(add func(interface{}) error) error {
+			return WhsOutGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(w WhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		w = WhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func GetWhsOutByFilterStr(req Req) {
-	req.Respond(WhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("whs_out", WhsOut{}, func(add func(interface{}) error) error {
+			return WhsOutGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(w WhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		w = WhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func DecodeWhsOut(req Req) (WhsOut, error) {
@@ -2010,27 +5791,102 @@ func DecodeWhsOut(req Req) (WhsOut, error) {
 }
 
 func RealizedWhsOut(req Req) {
-	req.Respond(WhsOutRealized(req.IntParam, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WhsOutRealized(req.IntParam, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func GetWhsOutBetweenCreatedAt(req Req) {
-	req.Respond(WhsOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_out", WhsOut{}, func(add func(interface{}) error) error {
+			return WhsOutGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WhsOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func GetWhsOutWhsSumSumBefore(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(WhsOutWhsSumGetSumBefore(req.StrParam, req.IntParam, req.Str2Param))
 }
 
 func GetWhsOutSumByFilter(req Req) {
+	if req.OwnOnly() && !(req.StrParam == "user_id" && req.IntParam == req.UserId) &&
+		!(req.Str2Param == "user_id" && req.Int2Param == req.UserId) {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
 	req.Respond(WhsOutGetSumByFilter(req.StrParam, req.IntParam, req.Str2Param, req.Int2Param))
 }
 
 func GetMatherialToWhsIn(req Req) {
-	req.Respond(MatherialToWhsInGet(req.IntParam, nil))
+	m, err := MatherialToWhsInGet(req.IntParam, nil)
+	if err == nil {
+		err = req.OwnDocCheck("whs_in", m.WhsInId)
+	}
+	req.Respond(m, err)
 }
 
 func GetMatherialToWhsInAll(req Req) {
-	req.Respond(MatherialToWhsInGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("whs_in_id", "whs_in", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_whs_in", MatherialToWhsIn{}, func(add func(interface{}) error) error {
+				_, err := MatherialToWhsInGetByQueryEach(q, nil, func(m MatherialToWhsIn) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialToWhsInGetByQuery(q, nil)
+		req.RespondList("matherial_to_whs_in", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_whs_in", MatherialToWhsIn{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_in")
+			if err != nil {
+				return err
+			}
+			return MatherialToWhsInGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToWhsIn) error {
+				if owned != nil && !owned[m.WhsInId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToWhsInGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToWhsInOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_in", m, err)
 }
 
 func CreateMatherialToWhsIn(req Req) {
@@ -2039,7 +5895,18 @@ func CreateMatherialToWhsIn(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToWhsInCreate(m, nil))
+	err = req.OwnDocCheck("whs_in", m.WhsInId)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToWhsInCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherialToWhsIn(req Req) {
@@ -2048,19 +5915,95 @@ func UpdateMatherialToWhsIn(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToWhsInUpdate(m, nil))
+	err = MatherialToWhsInOwnCheck(req, m.Id)
+	if err == nil {
+		err = req.OwnDocCheck("whs_in", m.WhsInId)
+	}
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToWhsInUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherialToWhsIn(req Req) {
-	req.Respond(MatherialToWhsInDelete(req.IntParam, nil, false))
+	if err := MatherialToWhsInOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToWhsInDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherialToWhsIn(req Req) {
+	if err := MatherialToWhsInOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToWhsInRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialToWhsInByFilterInt(req Req) {
-	req.Respond(MatherialToWhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_in", MatherialToWhsIn{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_in")
+			if err != nil {
+				return err
+			}
+			return MatherialToWhsInGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToWhsIn) error {
+				if owned != nil && !owned[m.WhsInId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToWhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToWhsInOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_in", m, err)
 }
 
 func GetMatherialToWhsInByFilterStr(req Req) {
-	req.Respond(MatherialToWhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_in", MatherialToWhsIn{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_in")
+			if err != nil {
+				return err
+			}
+			return MatherialToWhsInGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToWhsIn) error {
+				if owned != nil && !owned[m.WhsInId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToWhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToWhsInOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_in", m, err)
 }
 
 func DecodeMatherialToWhsIn(req Req) (MatherialToWhsIn, error) {
@@ -2072,11 +6015,56 @@ func DecodeMatherialToWhsIn(req Req) (MatherialToWhsIn, error) {
 }
 
 func GetMatherialToWhsOut(req Req) {
-	req.Respond(MatherialToWhsOutGet(req.IntParam, nil))
+	m, err := MatherialToWhsOutGet(req.IntParam, nil)
+	if err == nil {
+		err = req.OwnDocCheck("whs_out", m.WhsOutId)
+	}
+	req.Respond(m, err)
 }
 
 func GetMatherialToWhsOutAll(req Req) {
-	req.Respond(MatherialToWhsOutGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("whs_out_id", "whs_out", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_whs_out", MatherialToWhsOut{}, func(add func(interface{}) error) error {
+				_, err := MatherialToWhsOutGetByQueryEach(q, nil, func(m MatherialToWhsOut) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialToWhsOutGetByQuery(q, nil)
+		req.RespondList("matherial_to_whs_out", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_whs_out", MatherialToWhsOut{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_out")
+			if err != nil {
+				return err
+			}
+			return MatherialToWhsOutGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToWhsOut) error {
+				if owned != nil && !owned[m.WhsOutId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToWhsOutGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToWhsOutOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_out", m, err)
 }
 
 func CreateMatherialToWhsOut(req Req) {
@@ -2085,7 +6073,18 @@ func CreateMatherialToWhsOut(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToWhsOutCreate(m, nil))
+	err = req.OwnDocCheck("whs_out", m.WhsOutId)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToWhsOutCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherialToWhsOut(req Req) {
@@ -2094,19 +6093,95 @@ func UpdateMatherialToWhsOut(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialToWhsOutUpdate(m, nil))
+	err = MatherialToWhsOutOwnCheck(req, m.Id)
+	if err == nil {
+		err = req.OwnDocCheck("whs_out", m.WhsOutId)
+	}
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialToWhsOutUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherialToWhsOut(req Req) {
-	req.Respond(MatherialToWhsOutDelete(req.IntParam, nil, false))
+	if err := MatherialToWhsOutOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToWhsOutDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherialToWhsOut(req Req) {
+	if err := MatherialToWhsOutOwnCheck(req, req.IntParam); err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialToWhsOutRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialToWhsOutByFilterInt(req Req) {
-	req.Respond(MatherialToWhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_out", MatherialToWhsOut{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_out")
+			if err != nil {
+				return err
+			}
+			return MatherialToWhsOutGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToWhsOut) error {
+				if owned != nil && !owned[m.WhsOutId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToWhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToWhsOutOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_out", m, err)
 }
 
 func GetMatherialToWhsOutByFilterStr(req Req) {
-	req.Respond(MatherialToWhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_out", MatherialToWhsOut{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_out")
+			if err != nil {
+				return err
+			}
+			return MatherialToWhsOutGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialToWhsOut) error {
+				if owned != nil && !owned[m.WhsOutId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialToWhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	if err == nil && req.OwnOnly() {
+		m, err = MatherialToWhsOutOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_out", m, err)
 }
 
 func DecodeMatherialToWhsOut(req Req) (MatherialToWhsOut, error) {
@@ -2122,7 +6197,35 @@ func GetMatherialPart(req Req) {
 }
 
 func GetMatherialPartAll(req Req) {
-	req.Respond(MatherialPartGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_part", MatherialPart{}, func(add func(interface{}) error) error {
+				_, err := MatherialPartGetByQueryEach(q, nil, func(m MatherialPart) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialPartGetByQuery(q, nil)
+		req.RespondList("matherial_part", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_part", MatherialPart{}, func(add func(interface{}) error) error {
+			return MatherialPartGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func CreateMatherialPart(req Req) {
@@ -2131,28 +6234,79 @@ func CreateMatherialPart(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialPartCreate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialPartCreate(m, tx)
+	req.RespondTx(tx, m, err)
+}
+
+func UpdateMatherialPart(req Req) {
+	m, err := DecodeMatherialPart(req)
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialPartUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
+}
+
+func DeleteMatherialPart(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("matherial_part", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	m, err := MatherialPartDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
 }
 
-func UpdateMatherialPart(req Req) {
-	m, err := DecodeMatherialPart(req)
+func RestoreMatherialPart(req Req) {
+	tx, err := req.Begin()
 	if err != nil {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialPartUpdate(m, nil))
-}
-
-func DeleteMatherialPart(req Req) {
-	req.Respond(MatherialPartDelete(req.IntParam, nil, false))
+	m, err := MatherialPartRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialPartByFilterInt(req Req) {
-	req.Respond(MatherialPartGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_part", MatherialPart{}, func(add func(interface{}) error) error {
+			return MatherialPartGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func GetMatherialPartByFilterStr(req Req) {
-	req.Respond(MatherialPartGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_part", MatherialPart{}, func(add func(interface{}) error) error {
+			return MatherialPartGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func DecodeMatherialPart(req Req) (MatherialPart, error) {
@@ -2164,7 +6318,16 @@ func DecodeMatherialPart(req Req) (MatherialPart, error) {
 }
 
 func GetMatherialPartBetweenCreatedAt(req Req) {
-	req.Respond(MatherialPartGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part", MatherialPart{}, func(add func(interface{}) error) error {
+			return MatherialPartGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m MatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func GetMatherialPartSlice(req Req) {
@@ -2172,7 +6335,35 @@ func GetMatherialPartSlice(req Req) {
 }
 
 func GetMatherialPartSliceAll(req Req) {
-	req.Respond(MatherialPartSliceGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_part_slice", MatherialPartSlice{}, func(add func(interface{}) error) error {
+				_, err := MatherialPartSliceGetByQueryEach(q, nil, func(m MatherialPartSlice) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := MatherialPartSliceGetByQuery(q, nil)
+		req.RespondList("matherial_part_slice", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_part_slice", MatherialPartSlice{}, func(add func(interface{}) error) error {
+			return MatherialPartSliceGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(m MatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartSliceGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func CreateMatherialPartSlice(req Req) {
@@ -2181,7 +6372,13 @@ func CreateMatherialPartSlice(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialPartSliceCreate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialPartSliceCreate(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func UpdateMatherialPartSlice(req Req) {
@@ -2190,19 +6387,59 @@ func UpdateMatherialPartSlice(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(MatherialPartSliceUpdate(m, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err = MatherialPartSliceUpdateVersioned(m, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func DeleteMatherialPartSlice(req Req) {
-	req.Respond(MatherialPartSliceDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialPartSliceDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, m, err)
+}
+
+func RestoreMatherialPartSlice(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	m, err := MatherialPartSliceRestore(req.IntParam, tx)
+	req.RespondTx(tx, m, err)
 }
 
 func GetMatherialPartSliceByFilterInt(req Req) {
-	req.Respond(MatherialPartSliceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_part_slice", MatherialPartSlice{}, func(add func(interface{}) error) error {
+			return MatherialPartSliceGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartSliceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func GetMatherialPartSliceByFilterStr(req Req) {
-	req.Respond(MatherialPartSliceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("matherial_part_slice", MatherialPartSlice{}, func(add func(interface{}) error) error {
+			return MatherialPartSliceGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(m MatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartSliceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func DecodeMatherialPartSlice(req Req) (MatherialPartSlice, error) {
@@ -2214,7 +6451,16 @@ func DecodeMatherialPartSlice(req Req) (MatherialPartSlice, error) {
 }
 
 func GetMatherialPartSliceBetweenCreatedAt(req Req) {
-	req.Respond(MatherialPartSliceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part_slice", MatherialPartSlice{}, func(add func(interface{}) error) error {
+			return MatherialPartSliceGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m MatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := MatherialPartSliceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func GetProjectGroup(req Req) {
@@ -2222,7 +6468,35 @@ func GetProjectGroup(req Req) {
 }
 
 func GetProjectGroupAll(req Req) {
-	req.Respond(ProjectGroupGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project_group", ProjectGroup{}, func(add func(interface{}) error) error {
+				_, err := ProjectGroupGetByQueryEach(q, nil, func(p ProjectGroup) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProjectGroupGetByQuery(q, nil)
+		req.RespondList("project_group", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project_group", ProjectGroup{}, func(add func(interface{}) error) error {
+			return ProjectGroupGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProjectGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGroupGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_group", p, err)
 }
 
 func CreateProjectGroup(req Req) {
@@ -2231,7 +6505,13 @@ func CreateProjectGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectGroupCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectGroupCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProjectGroup(req Req) {
@@ -2240,19 +6520,64 @@ func UpdateProjectGroup(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectGroupUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectGroupUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProjectGroup(req Req) {
-	req.Respond(ProjectGroupDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("project_group", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	p, err := ProjectGroupDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProjectGroup(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProjectGroupRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProjectGroupByFilterInt(req Req) {
-	req.Respond(ProjectGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project_group", ProjectGroup{}, func(add func(interface{}) error) error {
+			return ProjectGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProjectGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_group", p, err)
 }
 
 func GetProjectGroupByFilterStr(req Req) {
-	req.Respond(ProjectGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project_group", ProjectGroup{}, func(add func(interface{}) error) error {
+			return ProjectGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProjectGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_group", p, err)
 }
 
 func DecodeProjectGroup(req Req) (ProjectGroup, error) {
@@ -2268,7 +6593,35 @@ func GetProjectStatus(req Req) {
 }
 
 func GetProjectStatusAll(req Req) {
-	req.Respond(ProjectStatusGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project_status", ProjectStatus{}, func(add func(interface{}) error) error {
+				_, err := ProjectStatusGetByQueryEach(q, nil, func(p ProjectStatus) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProjectStatusGetByQuery(q, nil)
+		req.RespondList("project_status", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project_status", ProjectStatus{}, func(add func(interface{}) error) error {
+			return ProjectStatusGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProjectStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectStatusGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_status", p, err)
 }
 
 func CreateProjectStatus(req Req) {
@@ -2277,7 +6630,13 @@ func CreateProjectStatus(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectStatusCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectStatusCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProjectStatus(req Req) {
@@ -2286,19 +6645,64 @@ func UpdateProjectStatus(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectStatusUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectStatusUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProjectStatus(req Req) {
-	req.Respond(ProjectStatusDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("project_status", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	p, err := ProjectStatusDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProjectStatus(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProjectStatusRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProjectStatusByFilterInt(req Req) {
-	req.Respond(ProjectStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project_status", ProjectStatus{}, func(add func(interface{}) error) error {
+			return ProjectStatusGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProjectStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_status", p, err)
 }
 
 func GetProjectStatusByFilterStr(req Req) {
-	req.Respond(ProjectStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project_status", ProjectStatus{}, func(add func(interface{}) error) error {
+			return ProjectStatusGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProjectStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_status", p, err)
 }
 
 func DecodeProjectStatus(req Req) (ProjectStatus, error) {
@@ -2314,7 +6718,35 @@ func GetProjectType(req Req) {
 }
 
 func GetProjectTypeAll(req Req) {
-	req.Respond(ProjectTypeGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project_type", ProjectType{}, func(add func(interface{}) error) error {
+				_, err := ProjectTypeGetByQueryEach(q, nil, func(p ProjectType) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProjectTypeGetByQuery(q, nil)
+		req.RespondList("project_type", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project_type", ProjectType{}, func(add func(interface{}) error) error {
+			return ProjectTypeGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p ProjectType) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectTypeGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_type", p, err)
 }
 
 func CreateProjectType(req Req) {
@@ -2323,7 +6755,13 @@ func CreateProjectType(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectTypeCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectTypeCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProjectType(req Req) {
@@ -2332,19 +6770,64 @@ func UpdateProjectType(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectTypeUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectTypeUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProjectType(req Req) {
-	req.Respond(ProjectTypeDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("project_type", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	p, err := ProjectTypeDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProjectType(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProjectTypeRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProjectTypeByFilterInt(req Req) {
-	req.Respond(ProjectTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project_type", ProjectType{}, func(add func(interface{}) error) error {
+			return ProjectTypeGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p ProjectType) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_type", p, err)
 }
 
 func GetProjectTypeByFilterStr(req Req) {
-	req.Respond(ProjectTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project_type", ProjectType{}, func(add func(interface{}) error) error {
+			return ProjectTypeGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p ProjectType) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project_type", p, err)
 }
 
 func DecodeProjectType(req Req) (ProjectType, error) {
@@ -2360,7 +6843,35 @@ func GetProject(req Req) {
 }
 
 func GetProjectAll(req Req) {
-	req.Respond(ProjectGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project", Project{}, func(add func(interface{}) error) error {
+				_, err := ProjectGetByQueryEach(q, nil, func(p Project) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := ProjectGetByQuery(q, nil)
+		req.RespondList("project", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project", Project{}, func(add func(interface{}) error) error {
+			return ProjectGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(p Project) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project", p, err)
 }
 
 func CreateProject(req Req) {
@@ -2369,7 +6880,13 @@ func CreateProject(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectCreate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectCreate(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func UpdateProject(req Req) {
@@ -2378,19 +6895,59 @@ func UpdateProject(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(ProjectUpdate(p, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err = ProjectUpdateVersioned(p, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func DeleteProject(req Req) {
-	req.Respond(ProjectDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProjectDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, p, err)
+}
+
+func RestoreProject(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	p, err := ProjectRestore(req.IntParam, tx)
+	req.RespondTx(tx, p, err)
 }
 
 func GetProjectByFilterInt(req Req) {
-	req.Respond(ProjectGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project", Project{}, func(add func(interface{}) error) error {
+			return ProjectGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(p Project) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project", p, err)
 }
 
 func GetProjectByFilterStr(req Req) {
-	req.Respond(ProjectGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("project", Project{}, func(add func(interface{}) error) error {
+			return ProjectGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(p Project) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("project", p, err)
 }
 
 func DecodeProject(req Req) (Project, error) {
@@ -2402,11 +6959,29 @@ func DecodeProject(req Req) (Project, error) {
 }
 
 func GetProjectFindByProjectInfoContragentNoSearchContactNoSearch(req Req) {
-	req.Respond(ProjectFindByProjectInfoContragentNoSearchContactNoSearch(req.StrParam))
+	if req.Exporting() {
+		req.Export("project", Project{}, func(add func(interface{}) error) error {
+			return ProjectFindByProjectInfoContragentNoSearchContactNoSearchEach(req.StrParam, func(p Project) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectFindByProjectInfoContragentNoSearchContactNoSearch(req.StrParam)
+	req.RespondRows("project", p, err)
 }
 
 func GetProjectBetweenCreatedAt(req Req) {
-	req.Respond(ProjectGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project", Project{}, func(add func(interface{}) error) error {
+			return ProjectGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p Project) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := ProjectGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project", p, err)
 }
 
 func GetCounter(req Req) {
@@ -2414,7 +6989,35 @@ func GetCounter(req Req) {
 }
 
 func GetCounterAll(req Req) {
-	req.Respond(CounterGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("counter", Counter{}, func(add func(interface{}) error) error {
+				_, err := CounterGetByQueryEach(q, nil, func(c Counter) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := CounterGetByQuery(q, nil)
+		req.RespondList("counter", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("counter", Counter{}, func(add func(interface{}) error) error {
+			return CounterGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(c Counter) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CounterGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("counter", c, err)
 }
 
 func CreateCounter(req Req) {
@@ -2423,7 +7026,13 @@ func CreateCounter(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CounterCreate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CounterCreate(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func UpdateCounter(req Req) {
@@ -2432,19 +7041,64 @@ func UpdateCounter(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(CounterUpdate(c, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err = CounterUpdateVersioned(c, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func DeleteCounter(req Req) {
-	req.Respond(CounterDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	err = ReferencesResolve("counter", req.IntParam, req.R.URL.Query(), tx)
+	if err != nil {
+		req.RespondTx(tx, nil, err)
+		return
+	}
+	c, err := CounterDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, c, err)
+}
+
+func RestoreCounter(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	c, err := CounterRestore(req.IntParam, tx)
+	req.RespondTx(tx, c, err)
 }
 
 func GetCounterByFilterInt(req Req) {
-	req.Respond(CounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("counter", Counter{}, func(add func(interface{}) error) error {
+			return CounterGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(c Counter) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("counter", c, err)
 }
 
 func GetCounterByFilterStr(req Req) {
-	req.Respond(CounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("counter", Counter{}, func(add func(interface{}) error) error {
+			return CounterGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(c Counter) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := CounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("counter", c, err)
 }
 
 func DecodeCounter(req Req) (Counter, error) {
@@ -2460,7 +7114,35 @@ func GetRecordToCounter(req Req) {
 }
 
 func GetRecordToCounterAll(req Req) {
-	req.Respond(RecordToCounterGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("record_to_counter", RecordToCounter{}, func(add func(interface{}) error) error {
+				_, err := RecordToCounterGetByQueryEach(q, nil, func(r RecordToCounter) error {
+					return add(r)
+				})
+				return err
+			})
+			return
+		}
+		r, total, err := RecordToCounterGetByQuery(q, nil)
+		req.RespondList("record_to_counter", r, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("record_to_counter", RecordToCounter{}, func(add func(interface{}) error) error {
+			return RecordToCounterGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(r RecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := RecordToCounterGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func CreateRecordToCounter(req Req) {
@@ -2469,7 +7151,13 @@ func CreateRecordToCounter(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(RecordToCounterCreate(r, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	r, err = RecordToCounterCreate(r, tx)
+	req.RespondTx(tx, r, err)
 }
 
 func UpdateRecordToCounter(req Req) {
@@ -2478,15 +7166,39 @@ func UpdateRecordToCounter(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(RecordToCounterUpdate(r, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	r, err = RecordToCounterUpdateVersioned(r, tx)
+	req.RespondTx(tx, r, err)
 }
 
 func GetRecordToCounterByFilterInt(req Req) {
-	req.Respond(RecordToCounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("record_to_counter", RecordToCounter{}, func(add func(interface{}) error) error {
+			return RecordToCounterGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(r RecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := RecordToCounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func GetRecordToCounterByFilterStr(req Req) {
-	req.Respond(RecordToCounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("record_to_counter", RecordToCounter{}, func(add func(interface{}) error) error {
+			return RecordToCounterGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(r RecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := RecordToCounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func DecodeRecordToCounter(req Req) (RecordToCounter, error) {
@@ -2498,7 +7210,16 @@ func DecodeRecordToCounter(req Req) (RecordToCounter, error) {
 }
 
 func GetRecordToCounterBetweenCreatedAt(req Req) {
-	req.Respond(RecordToCounterGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("record_to_counter", RecordToCounter{}, func(add func(interface{}) error) error {
+			return RecordToCounterGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(r RecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := RecordToCounterGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func GetRecordToCounterNumberSumBefore(req Req) {
@@ -2514,7 +7235,35 @@ func GetWmcNumber(req Req) {
 }
 
 func GetWmcNumberAll(req Req) {
-	req.Respond(WmcNumberGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("wmc_number", WmcNumber{}, func(add func(interface{}) error) error {
+				_, err := WmcNumberGetByQueryEach(q, nil, func(w WmcNumber) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WmcNumberGetByQuery(q, nil)
+		req.RespondList("wmc_number", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("wmc_number", WmcNumber{}, func(add func(interface{}) error) error {
+			return WmcNumberGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(w WmcNumber) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WmcNumberGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("wmc_number", w, err)
 }
 
 func CreateWmcNumber(req Req) {
@@ -2523,7 +7272,13 @@ func CreateWmcNumber(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WmcNumberCreate(w, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WmcNumberCreate(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func UpdateWmcNumber(req Req) {
@@ -2532,19 +7287,59 @@ func UpdateWmcNumber(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(WmcNumberUpdate(w, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err = WmcNumberUpdateVersioned(w, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func DeleteWmcNumber(req Req) {
-	req.Respond(WmcNumberDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WmcNumberDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, w, err)
+}
+
+func RestoreWmcNumber(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	w, err := WmcNumberRestore(req.IntParam, tx)
+	req.RespondTx(tx, w, err)
 }
 
 func GetWmcNumberByFilterInt(req Req) {
-	req.Respond(WmcNumberGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("wmc_number", WmcNumber{}, func(add func(interface{}) error) error {
+			return WmcNumberGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(w WmcNumber) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WmcNumberGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("wmc_number", w, err)
 }
 
 func GetWmcNumberByFilterStr(req Req) {
-	req.Respond(WmcNumberGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("wmc_number", WmcNumber{}, func(add func(interface{}) error) error {
+			return WmcNumberGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(w WmcNumber) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WmcNumberGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("wmc_number", w, err)
 }
 
 func DecodeWmcNumber(req Req) (WmcNumber, error) {
@@ -2560,7 +7355,35 @@ func GetNumbersToProduct(req Req) {
 }
 
 func GetNumbersToProductAll(req Req) {
-	req.Respond(NumbersToProductGetAll(req.WithDeleted, req.DeletedOnly, nil))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("numbers_to_product", NumbersToProduct{}, func(add func(interface{}) error) error {
+				_, err := NumbersToProductGetByQueryEach(q, nil, func(n NumbersToProduct) error {
+					return add(n)
+				})
+				return err
+			})
+			return
+		}
+		n, total, err := NumbersToProductGetByQuery(q, nil)
+		req.RespondList("numbers_to_product", n, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("numbers_to_product", NumbersToProduct{}, func(add func(interface{}) error) error {
+			return NumbersToProductGetAllEach(req.WithDeleted, req.DeletedOnly, nil, func(n NumbersToProduct) error {
+				return add(n)
+			})
+		})
+		return
+	}
+	n, err := NumbersToProductGetAll(req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("numbers_to_product", n, err)
 }
 
 func CreateNumbersToProduct(req Req) {
@@ -2569,7 +7392,13 @@ func CreateNumbersToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(NumbersToProductCreate(n, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	n, err = NumbersToProductCreate(n, tx)
+	req.RespondTx(tx, n, err)
 }
 
 func UpdateNumbersToProduct(req Req) {
@@ -2578,19 +7407,59 @@ func UpdateNumbersToProduct(req Req) {
 		req.Respond(nil, err)
 		return
 	}
-	req.Respond(NumbersToProductUpdate(n, nil))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	n, err = NumbersToProductUpdateVersioned(n, tx)
+	req.RespondTx(tx, n, err)
 }
 
 func DeleteNumbersToProduct(req Req) {
-	req.Respond(NumbersToProductDelete(req.IntParam, nil, false))
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	n, err := NumbersToProductDelete(req.IntParam, tx, false)
+	req.RespondTx(tx, n, err)
+}
+
+func RestoreNumbersToProduct(req Req) {
+	tx, err := req.Begin()
+	if err != nil {
+		req.Respond(nil, err)
+		return
+	}
+	n, err := NumbersToProductRestore(req.IntParam, tx)
+	req.RespondTx(tx, n, err)
 }
 
 func GetNumbersToProductByFilterInt(req Req) {
-	req.Respond(NumbersToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("numbers_to_product", NumbersToProduct{}, func(add func(interface{}) error) error {
+			return NumbersToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil, func(n NumbersToProduct) error {
+				return add(n)
+			})
+		})
+		return
+	}
+	n, err := NumbersToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("numbers_to_product", n, err)
 }
 
 func GetNumbersToProductByFilterStr(req Req) {
-	req.Respond(NumbersToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil))
+	if req.Exporting() {
+		req.Export("numbers_to_product", NumbersToProduct{}, func(add func(interface{}) error) error {
+			return NumbersToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil, func(n NumbersToProduct) error {
+				return add(n)
+			})
+		})
+		return
+	}
+	n, err := NumbersToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, nil)
+	req.RespondRows("numbers_to_product", n, err)
 }
 
 func DecodeNumbersToProduct(req Req) (NumbersToProduct, error) {
@@ -2606,15 +7475,61 @@ func GetWMeasure(req Req) {
 }
 
 func GetWMeasureAll(req Req) {
-	req.Respond(WMeasureGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("measure", WMeasure{}, func(add func(interface{}) error) error {
+				_, err := WMeasureGetByQueryEach(q, func(m WMeasure) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMeasureGetByQuery(q)
+		req.RespondList("measure", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("measure", WMeasure{}, func(add func(interface{}) error) error {
+			return WMeasureGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMeasure) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMeasureGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("measure", m, err)
 }
 
 func GetWMeasureByFilterInt(req Req) {
-	req.Respond(WMeasureGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("measure", WMeasure{}, func(add func(interface{}) error) error {
+			return WMeasureGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMeasure) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMeasureGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("measure", m, err)
 }
 
 func GetWMeasureByFilterStr(req Req) {
-	req.Respond(WMeasureGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("measure", WMeasure{}, func(add func(interface{}) error) error {
+			return WMeasureGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMeasure) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMeasureGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("measure", m, err)
 }
 
 func GetWCountType(req Req) {
@@ -2622,15 +7537,61 @@ func GetWCountType(req Req) {
 }
 
 func GetWCountTypeAll(req Req) {
-	req.Respond(WCountTypeGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("count_type", WCountType{}, func(add func(interface{}) error) error {
+				_, err := WCountTypeGetByQueryEach(q, func(c WCountType) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WCountTypeGetByQuery(q)
+		req.RespondList("count_type", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("count_type", WCountType{}, func(add func(interface{}) error) error {
+			return WCountTypeGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WCountType) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCountTypeGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("count_type", c, err)
 }
 
 func GetWCountTypeByFilterInt(req Req) {
-	req.Respond(WCountTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("count_type", WCountType{}, func(add func(interface{}) error) error {
+			return WCountTypeGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WCountType) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCountTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("count_type", c, err)
 }
 
 func GetWCountTypeByFilterStr(req Req) {
-	req.Respond(WCountTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("count_type", WCountType{}, func(add func(interface{}) error) error {
+			return WCountTypeGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCountType) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCountTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("count_type", c, err)
 }
 
 func GetWColorGroup(req Req) {
@@ -2638,15 +7599,61 @@ func GetWColorGroup(req Req) {
 }
 
 func GetWColorGroupAll(req Req) {
-	req.Respond(WColorGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("color_group", WColorGroup{}, func(add func(interface{}) error) error {
+				_, err := WColorGroupGetByQueryEach(q, func(c WColorGroup) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WColorGroupGetByQuery(q)
+		req.RespondList("color_group", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("color_group", WColorGroup{}, func(add func(interface{}) error) error {
+			return WColorGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WColorGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WColorGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("color_group", c, err)
 }
 
 func GetWColorGroupByFilterInt(req Req) {
-	req.Respond(WColorGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("color_group", WColorGroup{}, func(add func(interface{}) error) error {
+			return WColorGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WColorGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WColorGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("color_group", c, err)
 }
 
 func GetWColorGroupByFilterStr(req Req) {
-	req.Respond(WColorGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("color_group", WColorGroup{}, func(add func(interface{}) error) error {
+			return WColorGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WColorGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WColorGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("color_group", c, err)
 }
 
 func GetWColor(req Req) {
@@ -2654,15 +7661,61 @@ func GetWColor(req Req) {
 }
 
 func GetWColorAll(req Req) {
-	req.Respond(WColorGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("color", WColor{}, func(add func(interface{}) error) error {
+				_, err := WColorGetByQueryEach(q, func(c WColor) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WColorGetByQuery(q)
+		req.RespondList("color", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("color", WColor{}, func(add func(interface{}) error) error {
+			return WColorGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WColor) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WColorGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("color", c, err)
 }
 
 func GetWColorByFilterInt(req Req) {
-	req.Respond(WColorGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("color", WColor{}, func(add func(interface{}) error) error {
+			return WColorGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WColor) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WColorGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("color", c, err)
 }
 
 func GetWColorByFilterStr(req Req) {
-	req.Respond(WColorGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("color", WColor{}, func(add func(interface{}) error) error {
+			return WColorGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WColor) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WColorGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("color", c, err)
 }
 
 func GetWMatherialGroup(req Req) {
@@ -2670,15 +7723,61 @@ func GetWMatherialGroup(req Req) {
 }
 
 func GetWMatherialGroupAll(req Req) {
-	req.Respond(WMatherialGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_group", WMatherialGroup{}, func(add func(interface{}) error) error {
+				_, err := WMatherialGroupGetByQueryEach(q, func(m WMatherialGroup) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialGroupGetByQuery(q)
+		req.RespondList("matherial_group", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_group", WMatherialGroup{}, func(add func(interface{}) error) error {
+			return WMatherialGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialGroup) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_group", m, err)
 }
 
 func GetWMatherialGroupByFilterInt(req Req) {
-	req.Respond(WMatherialGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_group", WMatherialGroup{}, func(add func(interface{}) error) error {
+			return WMatherialGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialGroup) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_group", m, err)
 }
 
 func GetWMatherialGroupByFilterStr(req Req) {
-	req.Respond(WMatherialGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_group", WMatherialGroup{}, func(add func(interface{}) error) error {
+			return WMatherialGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialGroup) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_group", m, err)
 }
 
 func GetWMatherial(req Req) {
@@ -2686,15 +7785,61 @@ func GetWMatherial(req Req) {
 }
 
 func GetWMatherialAll(req Req) {
-	req.Respond(WMatherialGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial", WMatherial{}, func(add func(interface{}) error) error {
+				_, err := WMatherialGetByQueryEach(q, func(m WMatherial) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialGetByQuery(q)
+		req.RespondList("matherial", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial", WMatherial{}, func(add func(interface{}) error) error {
+			return WMatherialGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherial) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial", m, err)
 }
 
 func GetWMatherialByFilterInt(req Req) {
-	req.Respond(WMatherialGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial", WMatherial{}, func(add func(interface{}) error) error {
+			return WMatherialGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherial) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial", m, err)
 }
 
 func GetWMatherialByFilterStr(req Req) {
-	req.Respond(WMatherialGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial", WMatherial{}, func(add func(interface{}) error) error {
+			return WMatherialGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherial) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial", m, err)
 }
 
 func GetWCash(req Req) {
@@ -2702,15 +7847,61 @@ func GetWCash(req Req) {
 }
 
 func GetWCashAll(req Req) {
-	req.Respond(WCashGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("cash", WCash{}, func(add func(interface{}) error) error {
+				_, err := WCashGetByQueryEach(q, func(c WCash) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WCashGetByQuery(q)
+		req.RespondList("cash", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cash", WCash{}, func(add func(interface{}) error) error {
+			return WCashGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WCash) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cash", c, err)
 }
 
 func GetWCashByFilterInt(req Req) {
-	req.Respond(WCashGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash", WCash{}, func(add func(interface{}) error) error {
+			return WCashGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WCash) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cash", c, err)
 }
 
 func GetWCashByFilterStr(req Req) {
-	req.Respond(WCashGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash", WCash{}, func(add func(interface{}) error) error {
+			return WCashGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCash) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cash", c, err)
 }
 
 func GetWUserGroup(req Req) {
@@ -2718,15 +7909,61 @@ func GetWUserGroup(req Req) {
 }
 
 func GetWUserGroupAll(req Req) {
-	req.Respond(WUserGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("user_group", WUserGroup{}, func(add func(interface{}) error) error {
+				_, err := WUserGroupGetByQueryEach(q, func(u WUserGroup) error {
+					return add(u)
+				})
+				return err
+			})
+			return
+		}
+		u, total, err := WUserGroupGetByQuery(q)
+		req.RespondList("user_group", u, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("user_group", WUserGroup{}, func(add func(interface{}) error) error {
+			return WUserGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(u WUserGroup) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := WUserGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("user_group", u, err)
 }
 
 func GetWUserGroupByFilterInt(req Req) {
-	req.Respond(WUserGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("user_group", WUserGroup{}, func(add func(interface{}) error) error {
+			return WUserGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(u WUserGroup) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := WUserGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("user_group", u, err)
 }
 
 func GetWUserGroupByFilterStr(req Req) {
-	req.Respond(WUserGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("user_group", WUserGroup{}, func(add func(interface{}) error) error {
+			return WUserGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(u WUserGroup) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := WUserGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("user_group", u, err)
 }
 
 func GetWUser(req Req) {
@@ -2734,15 +7971,61 @@ func GetWUser(req Req) {
 }
 
 func GetWUserAll(req Req) {
-	req.Respond(WUserGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("user", WUser{}, func(add func(interface{}) error) error {
+				_, err := WUserGetByQueryEach(q, func(u WUser) error {
+					return add(u)
+				})
+				return err
+			})
+			return
+		}
+		u, total, err := WUserGetByQuery(q)
+		req.RespondList("user", u, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("user", WUser{}, func(add func(interface{}) error) error {
+			return WUserGetAllEach(req.WithDeleted, req.DeletedOnly, func(u WUser) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := WUserGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("user", u, err)
 }
 
 func GetWUserByFilterInt(req Req) {
-	req.Respond(WUserGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("user", WUser{}, func(add func(interface{}) error) error {
+			return WUserGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(u WUser) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := WUserGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("user", u, err)
 }
 
 func GetWUserByFilterStr(req Req) {
-	req.Respond(WUserGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("user", WUser{}, func(add func(interface{}) error) error {
+			return WUserGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(u WUser) error {
+				return add(u)
+			})
+		})
+		return
+	}
+	u, err := WUserGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("user", u, err)
 }
 
 func GetWEquipmentGroup(req Req) {
@@ -2750,15 +8033,61 @@ func GetWEquipmentGroup(req Req) {
 }
 
 func GetWEquipmentGroupAll(req Req) {
-	req.Respond(WEquipmentGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("equipment_group", WEquipmentGroup{}, func(add func(interface{}) error) error {
+				_, err := WEquipmentGroupGetByQueryEach(q, func(e WEquipmentGroup) error {
+					return add(e)
+				})
+				return err
+			})
+			return
+		}
+		e, total, err := WEquipmentGroupGetByQuery(q)
+		req.RespondList("equipment_group", e, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("equipment_group", WEquipmentGroup{}, func(add func(interface{}) error) error {
+			return WEquipmentGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(e WEquipmentGroup) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := WEquipmentGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("equipment_group", e, err)
 }
 
 func GetWEquipmentGroupByFilterInt(req Req) {
-	req.Respond(WEquipmentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("equipment_group", WEquipmentGroup{}, func(add func(interface{}) error) error {
+			return WEquipmentGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(e WEquipmentGroup) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := WEquipmentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("equipment_group", e, err)
 }
 
 func GetWEquipmentGroupByFilterStr(req Req) {
-	req.Respond(WEquipmentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("equipment_group", WEquipmentGroup{}, func(add func(interface{}) error) error {
+			return WEquipmentGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(e WEquipmentGroup) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := WEquipmentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("equipment_group", e, err)
 }
 
 func GetWEquipment(req Req) {
@@ -2766,15 +8095,61 @@ func GetWEquipment(req Req) {
 }
 
 func GetWEquipmentAll(req Req) {
-	req.Respond(WEquipmentGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("equipment", WEquipment{}, func(add func(interface{}) error) error {
+				_, err := WEquipmentGetByQueryEach(q, func(e WEquipment) error {
+					return add(e)
+				})
+				return err
+			})
+			return
+		}
+		e, total, err := WEquipmentGetByQuery(q)
+		req.RespondList("equipment", e, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("equipment", WEquipment{}, func(add func(interface{}) error) error {
+			return WEquipmentGetAllEach(req.WithDeleted, req.DeletedOnly, func(e WEquipment) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := WEquipmentGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("equipment", e, err)
 }
 
 func GetWEquipmentByFilterInt(req Req) {
-	req.Respond(WEquipmentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("equipment", WEquipment{}, func(add func(interface{}) error) error {
+			return WEquipmentGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(e WEquipment) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := WEquipmentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("equipment", e, err)
 }
 
 func GetWEquipmentByFilterStr(req Req) {
-	req.Respond(WEquipmentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("equipment", WEquipment{}, func(add func(interface{}) error) error {
+			return WEquipmentGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(e WEquipment) error {
+				return add(e)
+			})
+		})
+		return
+	}
+	e, err := WEquipmentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("equipment", e, err)
 }
 
 func GetWOperationGroup(req Req) {
@@ -2782,15 +8157,61 @@ func GetWOperationGroup(req Req) {
 }
 
 func GetWOperationGroupAll(req Req) {
-	req.Respond(WOperationGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("operation_group", WOperationGroup{}, func(add func(interface{}) error) error {
+				_, err := WOperationGroupGetByQueryEach(q, func(o WOperationGroup) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOperationGroupGetByQuery(q)
+		req.RespondList("operation_group", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation_group", WOperationGroup{}, func(add func(interface{}) error) error {
+			return WOperationGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOperationGroup) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation_group", o, err)
 }
 
 func GetWOperationGroupByFilterInt(req Req) {
-	req.Respond(WOperationGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_group", WOperationGroup{}, func(add func(interface{}) error) error {
+			return WOperationGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOperationGroup) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation_group", o, err)
 }
 
 func GetWOperationGroupByFilterStr(req Req) {
-	req.Respond(WOperationGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_group", WOperationGroup{}, func(add func(interface{}) error) error {
+			return WOperationGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOperationGroup) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation_group", o, err)
 }
 
 func GetWOperation(req Req) {
@@ -2798,15 +8219,61 @@ func GetWOperation(req Req) {
 }
 
 func GetWOperationAll(req Req) {
-	req.Respond(WOperationGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("operation", WOperation{}, func(add func(interface{}) error) error {
+				_, err := WOperationGetByQueryEach(q, func(o WOperation) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOperationGetByQuery(q)
+		req.RespondList("operation", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation", WOperation{}, func(add func(interface{}) error) error {
+			return WOperationGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOperation) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation", o, err)
 }
 
 func GetWOperationByFilterInt(req Req) {
-	req.Respond(WOperationGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation", WOperation{}, func(add func(interface{}) error) error {
+			return WOperationGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOperation) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation", o, err)
 }
 
 func GetWOperationByFilterStr(req Req) {
-	req.Respond(WOperationGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation", WOperation{}, func(add func(interface{}) error) error {
+			return WOperationGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOperation) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation", o, err)
 }
 
 func GetWProductGroup(req Req) {
@@ -2814,15 +8281,61 @@ func GetWProductGroup(req Req) {
 }
 
 func GetWProductGroupAll(req Req) {
-	req.Respond(WProductGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product_group", WProductGroup{}, func(add func(interface{}) error) error {
+				_, err := WProductGroupGetByQueryEach(q, func(p WProductGroup) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProductGroupGetByQuery(q)
+		req.RespondList("product_group", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_group", WProductGroup{}, func(add func(interface{}) error) error {
+			return WProductGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProductGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_group", p, err)
 }
 
 func GetWProductGroupByFilterInt(req Req) {
-	req.Respond(WProductGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_group", WProductGroup{}, func(add func(interface{}) error) error {
+			return WProductGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProductGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_group", p, err)
 }
 
 func GetWProductGroupByFilterStr(req Req) {
-	req.Respond(WProductGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_group", WProductGroup{}, func(add func(interface{}) error) error {
+			return WProductGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProductGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_group", p, err)
 }
 
 func GetWProduct(req Req) {
@@ -2830,15 +8343,61 @@ func GetWProduct(req Req) {
 }
 
 func GetWProductAll(req Req) {
-	req.Respond(WProductGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product", WProduct{}, func(add func(interface{}) error) error {
+				_, err := WProductGetByQueryEach(q, func(p WProduct) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProductGetByQuery(q)
+		req.RespondList("product", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product", WProduct{}, func(add func(interface{}) error) error {
+			return WProductGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product", p, err)
 }
 
 func GetWProductByFilterInt(req Req) {
-	req.Respond(WProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product", WProduct{}, func(add func(interface{}) error) error {
+			return WProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product", p, err)
 }
 
 func GetWProductByFilterStr(req Req) {
-	req.Respond(WProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product", WProduct{}, func(add func(interface{}) error) error {
+			return WProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product", p, err)
 }
 
 func GetWContragentGroup(req Req) {
@@ -2846,15 +8405,61 @@ func GetWContragentGroup(req Req) {
 }
 
 func GetWContragentGroupAll(req Req) {
-	req.Respond(WContragentGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("contragent_group", WContragentGroup{}, func(add func(interface{}) error) error {
+				_, err := WContragentGroupGetByQueryEach(q, func(c WContragentGroup) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WContragentGroupGetByQuery(q)
+		req.RespondList("contragent_group", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("contragent_group", WContragentGroup{}, func(add func(interface{}) error) error {
+			return WContragentGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WContragentGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contragent_group", c, err)
 }
 
 func GetWContragentGroupByFilterInt(req Req) {
-	req.Respond(WContragentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("contragent_group", WContragentGroup{}, func(add func(interface{}) error) error {
+			return WContragentGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WContragentGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contragent_group", c, err)
 }
 
 func GetWContragentGroupByFilterStr(req Req) {
-	req.Respond(WContragentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("contragent_group", WContragentGroup{}, func(add func(interface{}) error) error {
+			return WContragentGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WContragentGroup) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contragent_group", c, err)
 }
 
 func GetWContragent(req Req) {
@@ -2862,19 +8467,74 @@ func GetWContragent(req Req) {
 }
 
 func GetWContragentAll(req Req) {
-	req.Respond(WContragentGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("contragent", WContragent{}, func(add func(interface{}) error) error {
+				_, err := WContragentGetByQueryEach(q, func(c WContragent) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WContragentGetByQuery(q)
+		req.RespondList("contragent", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("contragent", WContragent{}, func(add func(interface{}) error) error {
+			return WContragentGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WContragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contragent", c, err)
 }
 
 func GetWContragentByFilterInt(req Req) {
-	req.Respond(WContragentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("contragent", WContragent{}, func(add func(interface{}) error) error {
+			return WContragentGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WContragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contragent", c, err)
 }
 
 func GetWContragentByFilterStr(req Req) {
-	req.Respond(WContragentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("contragent", WContragent{}, func(add func(interface{}) error) error {
+			return WContragentGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WContragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contragent", c, err)
 }
 
 func GetWContragentFindByContragentSearchContactSearch(req Req) {
-	req.Respond(WContragentFindByContragentSearchContactSearch(req.StrParam))
+	if req.Exporting() {
+		req.Export("contragent", WContragent{}, func(add func(interface{}) error) error {
+			return WContragentFindByContragentSearchContactSearchEach(req.StrParam, func(c WContragent) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContragentFindByContragentSearchContactSearch(req.StrParam)
+	req.RespondRows("contragent", c, err)
 }
 
 func GetWLegal(req Req) {
@@ -2882,15 +8542,61 @@ func GetWLegal(req Req) {
 }
 
 func GetWLegalAll(req Req) {
-	req.Respond(WLegalGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("legal", WLegal{}, func(add func(interface{}) error) error {
+				_, err := WLegalGetByQueryEach(q, func(l WLegal) error {
+					return add(l)
+				})
+				return err
+			})
+			return
+		}
+		l, total, err := WLegalGetByQuery(q)
+		req.RespondList("legal", l, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("legal", WLegal{}, func(add func(interface{}) error) error {
+			return WLegalGetAllEach(req.WithDeleted, req.DeletedOnly, func(l WLegal) error {
+				return add(l)
+			})
+		})
+		return
+	}
+	l, err := WLegalGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("legal", l, err)
 }
 
 func GetWLegalByFilterInt(req Req) {
-	req.Respond(WLegalGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("legal", WLegal{}, func(add func(interface{}) error) error {
+			return WLegalGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(l WLegal) error {
+				return add(l)
+			})
+		})
+		return
+	}
+	l, err := WLegalGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("legal", l, err)
 }
 
 func GetWLegalByFilterStr(req Req) {
-	req.Respond(WLegalGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("legal", WLegal{}, func(add func(interface{}) error) error {
+			return WLegalGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(l WLegal) error {
+				return add(l)
+			})
+		})
+		return
+	}
+	l, err := WLegalGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("legal", l, err)
 }
 
 func GetWContact(req Req) {
@@ -2898,15 +8604,61 @@ func GetWContact(req Req) {
 }
 
 func GetWContactAll(req Req) {
-	req.Respond(WContactGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("contact", WContact{}, func(add func(interface{}) error) error {
+				_, err := WContactGetByQueryEach(q, func(c WContact) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WContactGetByQuery(q)
+		req.RespondList("contact", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("contact", WContact{}, func(add func(interface{}) error) error {
+			return WContactGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WContact) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContactGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contact", c, err)
 }
 
 func GetWContactByFilterInt(req Req) {
-	req.Respond(WContactGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("contact", WContact{}, func(add func(interface{}) error) error {
+			return WContactGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WContact) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContactGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contact", c, err)
 }
 
 func GetWContactByFilterStr(req Req) {
-	req.Respond(WContactGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("contact", WContact{}, func(add func(interface{}) error) error {
+			return WContactGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WContact) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WContactGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("contact", c, err)
 }
 
 func GetWOrderingStatus(req Req) {
@@ -2914,15 +8666,61 @@ func GetWOrderingStatus(req Req) {
 }
 
 func GetWOrderingStatusAll(req Req) {
-	req.Respond(WOrderingStatusGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("ordering_status", WOrderingStatus{}, func(add func(interface{}) error) error {
+				_, err := WOrderingStatusGetByQueryEach(q, func(o WOrderingStatus) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOrderingStatusGetByQuery(q)
+		req.RespondList("ordering_status", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("ordering_status", WOrderingStatus{}, func(add func(interface{}) error) error {
+			return WOrderingStatusGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOrderingStatus) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingStatusGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("ordering_status", o, err)
 }
 
 func GetWOrderingStatusByFilterInt(req Req) {
-	req.Respond(WOrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering_status", WOrderingStatus{}, func(add func(interface{}) error) error {
+			return WOrderingStatusGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOrderingStatus) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("ordering_status", o, err)
 }
 
 func GetWOrderingStatusByFilterStr(req Req) {
-	req.Respond(WOrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering_status", WOrderingStatus{}, func(add func(interface{}) error) error {
+			return WOrderingStatusGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOrderingStatus) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("ordering_status", o, err)
 }
 
 func GetWOrderingState(req Req) {
@@ -2930,39 +8728,187 @@ func GetWOrderingState(req Req) {
 }
 
 func GetWOrderingStateAll(req Req) {
-	req.Respond(WOrderingStateGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("ordering_state", WOrderingState{}, func(add func(interface{}) error) error {
+				_, err := WOrderingStateGetByQueryEach(q, func(o WOrderingState) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOrderingStateGetByQuery(q)
+		req.RespondList("ordering_state", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("ordering_state", WOrderingState{}, func(add func(interface{}) error) error {
+			return WOrderingStateGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOrderingState) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingStateGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("ordering_state", o, err)
 }
 
 func GetWOrderingStateByFilterInt(req Req) {
-	req.Respond(WOrderingStateGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering_state", WOrderingState{}, func(add func(interface{}) error) error {
+			return WOrderingStateGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOrderingState) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingStateGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("ordering_state", o, err)
 }
 
 func GetWOrderingStateByFilterStr(req Req) {
-	req.Respond(WOrderingStateGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering_state", WOrderingState{}, func(add func(interface{}) error) error {
+			return WOrderingStateGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOrderingState) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingStateGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("ordering_state", o, err)
 }
 
 func GetWOrdering(req Req) {
-	req.Respond(WOrderingGet(req.IntParam))
+	o, err := WOrderingGet(req.IntParam)
+	if err == nil && req.OwnOnly() && o.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(o, err)
 }
 
 func GetWOrderingAll(req Req) {
-	req.Respond(WOrderingGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("ordering", WOrdering{}, func(add func(interface{}) error) error {
+				_, err := WOrderingGetByQueryEach(q, func(o WOrdering) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOrderingGetByQuery(q)
+		req.RespondList("ordering", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("ordering", WOrdering{}, func(add func(interface{}) error) error {
+			return WOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOrdering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = WOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetWOrderingByFilterInt(req Req) {
-	req.Respond(WOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering", WOrdering{}, func(add func(interface{}) error) error {
+			return WOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOrdering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = WOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetWOrderingByFilterStr(req Req) {
-	req.Respond(WOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering", WOrdering{}, func(add func(interface{}) error) error {
+			return WOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOrdering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = WOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetWOrderingBetweenCreatedAt(req Req) {
-	req.Respond(WOrderingGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering", WOrdering{}, func(add func(interface{}) error) error {
+			return WOrderingGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOrdering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = WOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetWOrderingBetweenDeadlineAt(req Req) {
-	req.Respond(WOrderingGetBetweenDeadlineAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("ordering", WOrdering{}, func(add func(interface{}) error) error {
+			return WOrderingGetBetweenDeadlineAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOrdering) error {
+				if req.OwnOnly() && o.UserId != req.UserId {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOrderingGetBetweenDeadlineAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o = WOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("ordering", o, err)
 }
 
 func GetWOwner(req Req) {
@@ -2970,51 +8916,267 @@ func GetWOwner(req Req) {
 }
 
 func GetWOwnerAll(req Req) {
-	req.Respond(WOwnerGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("owner", WOwner{}, func(add func(interface{}) error) error {
+				_, err := WOwnerGetByQueryEach(q, func(o WOwner) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOwnerGetByQuery(q)
+		req.RespondList("owner", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("owner", WOwner{}, func(add func(interface{}) error) error {
+			return WOwnerGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOwner) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOwnerGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("owner", o, err)
 }
 
 func GetWOwnerByFilterInt(req Req) {
-	req.Respond(WOwnerGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("owner", WOwner{}, func(add func(interface{}) error) error {
+			return WOwnerGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOwner) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOwnerGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("owner", o, err)
 }
 
 func GetWOwnerByFilterStr(req Req) {
-	req.Respond(WOwnerGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("owner", WOwner{}, func(add func(interface{}) error) error {
+			return WOwnerGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOwner) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOwnerGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("owner", o, err)
 }
 
 func GetWInvoice(req Req) {
-	req.Respond(WInvoiceGet(req.IntParam))
+	i, err := WInvoiceGet(req.IntParam)
+	if err == nil && req.OwnOnly() && i.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(i, err)
 }
 
 func GetWInvoiceAll(req Req) {
-	req.Respond(WInvoiceGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("invoice", WInvoice{}, func(add func(interface{}) error) error {
+				_, err := WInvoiceGetByQueryEach(q, func(i WInvoice) error {
+					return add(i)
+				})
+				return err
+			})
+			return
+		}
+		i, total, err := WInvoiceGetByQuery(q)
+		req.RespondList("invoice", i, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("invoice", WInvoice{}, func(add func(interface{}) error) error {
+			return WInvoiceGetAllEach(req.WithDeleted, req.DeletedOnly, func(i WInvoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WInvoiceGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i = WInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func GetWInvoiceByFilterInt(req Req) {
-	req.Respond(WInvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("invoice", WInvoice{}, func(add func(interface{}) error) error {
+			return WInvoiceGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(i WInvoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WInvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i = WInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func GetWInvoiceByFilterStr(req Req) {
-	req.Respond(WInvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("invoice", WInvoice{}, func(add func(interface{}) error) error {
+			return WInvoiceGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(i WInvoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WInvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i = WInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func GetWInvoiceBetweenCreatedAt(req Req) {
-	req.Respond(WInvoiceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("invoice", WInvoice{}, func(add func(interface{}) error) error {
+			return WInvoiceGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(i WInvoice) error {
+				if req.OwnOnly() && i.UserId != req.UserId {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WInvoiceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i = WInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("invoice", i, err)
 }
 
 func GetWItemToInvoice(req Req) {
-	req.Respond(WItemToInvoiceGet(req.IntParam))
+	i, err := WItemToInvoiceGet(req.IntParam)
+	if err == nil {
+		err = req.OwnDocCheck("invoice", i.InvoiceId)
+	}
+	req.Respond(i, err)
 }
 
 func GetWItemToInvoiceAll(req Req) {
-	req.Respond(WItemToInvoiceGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("invoice_id", "invoice", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("item_to_invoice", WItemToInvoice{}, func(add func(interface{}) error) error {
+				_, err := WItemToInvoiceGetByQueryEach(q, func(i WItemToInvoice) error {
+					return add(i)
+				})
+				return err
+			})
+			return
+		}
+		i, total, err := WItemToInvoiceGetByQuery(q)
+		req.RespondList("item_to_invoice", i, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("item_to_invoice", WItemToInvoice{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("invoice")
+			if err != nil {
+				return err
+			}
+			return WItemToInvoiceGetAllEach(req.WithDeleted, req.DeletedOnly, func(i WItemToInvoice) error {
+				if owned != nil && !owned[i.InvoiceId] {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WItemToInvoiceGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i, err = WItemToInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("item_to_invoice", i, err)
 }
 
 func GetWItemToInvoiceByFilterInt(req Req) {
-	req.Respond(WItemToInvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("item_to_invoice", WItemToInvoice{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("invoice")
+			if err != nil {
+				return err
+			}
+			return WItemToInvoiceGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(i WItemToInvoice) error {
+				if owned != nil && !owned[i.InvoiceId] {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WItemToInvoiceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i, err = WItemToInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("item_to_invoice", i, err)
 }
 
 func GetWItemToInvoiceByFilterStr(req Req) {
-	req.Respond(WItemToInvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("item_to_invoice", WItemToInvoice{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("invoice")
+			if err != nil {
+				return err
+			}
+			return WItemToInvoiceGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(i WItemToInvoice) error {
+				if owned != nil && !owned[i.InvoiceId] {
+					return nil
+				}
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WItemToInvoiceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		i, err = WItemToInvoiceOwnedBy(i, req.UserId)
+	}
+	req.RespondRows("item_to_invoice", i, err)
 }
 
 func GetWProductToOrderingStatus(req Req) {
@@ -3022,55 +9184,305 @@ func GetWProductToOrderingStatus(req Req) {
 }
 
 func GetWProductToOrderingStatusAll(req Req) {
-	req.Respond(WProductToOrderingStatusGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product_to_ordering_status", WProductToOrderingStatus{}, func(add func(interface{}) error) error {
+				_, err := WProductToOrderingStatusGetByQueryEach(q, func(p WProductToOrderingStatus) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProductToOrderingStatusGetByQuery(q)
+		req.RespondList("product_to_ordering_status", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_to_ordering_status", WProductToOrderingStatus{}, func(add func(interface{}) error) error {
+			return WProductToOrderingStatusGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProductToOrderingStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingStatusGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_to_ordering_status", p, err)
 }
 
 func GetWProductToOrderingStatusByFilterInt(req Req) {
-	req.Respond(WProductToOrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_ordering_status", WProductToOrderingStatus{}, func(add func(interface{}) error) error {
+			return WProductToOrderingStatusGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProductToOrderingStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_to_ordering_status", p, err)
 }
 
 func GetWProductToOrderingStatusByFilterStr(req Req) {
-	req.Respond(WProductToOrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_ordering_status", WProductToOrderingStatus{}, func(add func(interface{}) error) error {
+			return WProductToOrderingStatusGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProductToOrderingStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_to_ordering_status", p, err)
 }
 
 func GetWProductToOrdering(req Req) {
-	req.Respond(WProductToOrderingGet(req.IntParam))
+	p, err := WProductToOrderingGet(req.IntParam)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", p.OrderingId)
+	}
+	req.Respond(p, err)
 }
 
 func GetWProductToOrderingAll(req Req) {
-	req.Respond(WProductToOrderingGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("ordering_id", "ordering", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("product_to_ordering", WProductToOrdering{}, func(add func(interface{}) error) error {
+				_, err := WProductToOrderingGetByQueryEach(q, func(p WProductToOrdering) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProductToOrderingGetByQuery(q)
+		req.RespondList("product_to_ordering", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_to_ordering", WProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WProductToOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		p, err = WProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func GetWProductToOrderingByFilterInt(req Req) {
-	req.Respond(WProductToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_ordering", WProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WProductToOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		p, err = WProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func GetWProductToOrderingByFilterStr(req Req) {
-	req.Respond(WProductToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_ordering", WProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WProductToOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		p, err = WProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func GetWProductToOrderingBetweenUpCreatedAt(req Req) {
-	req.Respond(WProductToOrderingGetBetweenUpCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_ordering", WProductToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WProductToOrderingGetBetweenUpCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProductToOrdering) error {
+				if owned != nil && !owned[p.OrderingId] {
+					return nil
+				}
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToOrderingGetBetweenUpCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		p, err = WProductToOrderingOwnedBy(p, req.UserId)
+	}
+	req.RespondRows("product_to_ordering", p, err)
 }
 
 func GetWMatherialToOrdering(req Req) {
-	req.Respond(WMatherialToOrderingGet(req.IntParam))
+	m, err := WMatherialToOrderingGet(req.IntParam)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", m.OrderingId)
+	}
+	req.Respond(m, err)
 }
 
 func GetWMatherialToOrderingAll(req Req) {
-	req.Respond(WMatherialToOrderingGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("ordering_id", "ordering", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_ordering", WMatherialToOrdering{}, func(add func(interface{}) error) error {
+				_, err := WMatherialToOrderingGetByQueryEach(q, func(m WMatherialToOrdering) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialToOrderingGetByQuery(q)
+		req.RespondList("matherial_to_ordering", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", WMatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WMatherialToOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToOrderingGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func GetWMatherialToOrderingByFilterInt(req Req) {
-	req.Respond(WMatherialToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", WMatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WMatherialToOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func GetWMatherialToOrderingByFilterStr(req Req) {
-	req.Respond(WMatherialToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", WMatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WMatherialToOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func GetWMatherialToOrderingBetweenUpCreatedAt(req Req) {
-	req.Respond(WMatherialToOrderingGetBetweenUpCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_ordering", WMatherialToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WMatherialToOrderingGetBetweenUpCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialToOrdering) error {
+				if owned != nil && !owned[m.OrderingId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToOrderingGetBetweenUpCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToOrderingOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_ordering", m, err)
 }
 
 func GetWMatherialToProduct(req Req) {
@@ -3078,35 +9490,183 @@ func GetWMatherialToProduct(req Req) {
 }
 
 func GetWMatherialToProductAll(req Req) {
-	req.Respond(WMatherialToProductGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_product", WMatherialToProduct{}, func(add func(interface{}) error) error {
+				_, err := WMatherialToProductGetByQueryEach(q, func(m WMatherialToProduct) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialToProductGetByQuery(q)
+		req.RespondList("matherial_to_product", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_product", WMatherialToProduct{}, func(add func(interface{}) error) error {
+			return WMatherialToProductGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialToProduct) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToProductGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_to_product", m, err)
 }
 
 func GetWMatherialToProductByFilterInt(req Req) {
-	req.Respond(WMatherialToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_product", WMatherialToProduct{}, func(add func(interface{}) error) error {
+			return WMatherialToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialToProduct) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_to_product", m, err)
 }
 
 func GetWMatherialToProductByFilterStr(req Req) {
-	req.Respond(WMatherialToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_product", WMatherialToProduct{}, func(add func(interface{}) error) error {
+			return WMatherialToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialToProduct) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_to_product", m, err)
 }
 
 func GetWOperationToOrdering(req Req) {
-	req.Respond(WOperationToOrderingGet(req.IntParam))
+	o, err := WOperationToOrderingGet(req.IntParam)
+	if err == nil {
+		err = req.OwnDocCheck("ordering", o.OrderingId)
+	}
+	req.Respond(o, err)
 }
 
 func GetWOperationToOrderingAll(req Req) {
-	req.Respond(WOperationToOrderingGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("ordering_id", "ordering", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("operation_to_ordering", WOperationToOrdering{}, func(add func(interface{}) error) error {
+				_, err := WOperationToOrderingGetByQueryEach(q, func(o WOperationToOrdering) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOperationToOrderingGetByQuery(q)
+		req.RespondList("operation_to_ordering", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation_to_ordering", WOperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WOperationToOrderingGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToOrderingGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o, err = WOperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func GetWOperationToOrderingByFilterInt(req Req) {
-	req.Respond(WOperationToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_to_ordering", WOperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WOperationToOrderingGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToOrderingGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o, err = WOperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func GetWOperationToOrderingByFilterStr(req Req) {
-	req.Respond(WOperationToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_to_ordering", WOperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WOperationToOrderingGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToOrderingGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o, err = WOperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func GetWOperationToOrderingBetweenUpCreatedAt(req Req) {
-	req.Respond(WOperationToOrderingGetBetweenUpCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_to_ordering", WOperationToOrdering{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("ordering")
+			if err != nil {
+				return err
+			}
+			return WOperationToOrderingGetBetweenUpCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOperationToOrdering) error {
+				if owned != nil && !owned[o.OrderingId] {
+					return nil
+				}
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToOrderingGetBetweenUpCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		o, err = WOperationToOrderingOwnedBy(o, req.UserId)
+	}
+	req.RespondRows("operation_to_ordering", o, err)
 }
 
 func GetWOperationToProduct(req Req) {
@@ -3114,15 +9674,61 @@ func GetWOperationToProduct(req Req) {
 }
 
 func GetWOperationToProductAll(req Req) {
-	req.Respond(WOperationToProductGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("operation_to_product", WOperationToProduct{}, func(add func(interface{}) error) error {
+				_, err := WOperationToProductGetByQueryEach(q, func(o WOperationToProduct) error {
+					return add(o)
+				})
+				return err
+			})
+			return
+		}
+		o, total, err := WOperationToProductGetByQuery(q)
+		req.RespondList("operation_to_product", o, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("operation_to_product", WOperationToProduct{}, func(add func(interface{}) error) error {
+			return WOperationToProductGetAllEach(req.WithDeleted, req.DeletedOnly, func(o WOperationToProduct) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToProductGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation_to_product", o, err)
 }
 
 func GetWOperationToProductByFilterInt(req Req) {
-	req.Respond(WOperationToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_to_product", WOperationToProduct{}, func(add func(interface{}) error) error {
+			return WOperationToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(o WOperationToProduct) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation_to_product", o, err)
 }
 
 func GetWOperationToProductByFilterStr(req Req) {
-	req.Respond(WOperationToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("operation_to_product", WOperationToProduct{}, func(add func(interface{}) error) error {
+			return WOperationToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(o WOperationToProduct) error {
+				return add(o)
+			})
+		})
+		return
+	}
+	o, err := WOperationToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("operation_to_product", o, err)
 }
 
 func GetWProductToProduct(req Req) {
@@ -3130,15 +9736,61 @@ func GetWProductToProduct(req Req) {
 }
 
 func GetWProductToProductAll(req Req) {
-	req.Respond(WProductToProductGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("product_to_product", WProductToProduct{}, func(add func(interface{}) error) error {
+				_, err := WProductToProductGetByQueryEach(q, func(p WProductToProduct) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProductToProductGetByQuery(q)
+		req.RespondList("product_to_product", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("product_to_product", WProductToProduct{}, func(add func(interface{}) error) error {
+			return WProductToProductGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProductToProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToProductGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_to_product", p, err)
 }
 
 func GetWProductToProductByFilterInt(req Req) {
-	req.Respond(WProductToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_product", WProductToProduct{}, func(add func(interface{}) error) error {
+			return WProductToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProductToProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_to_product", p, err)
 }
 
 func GetWProductToProductByFilterStr(req Req) {
-	req.Respond(WProductToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("product_to_product", WProductToProduct{}, func(add func(interface{}) error) error {
+			return WProductToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProductToProduct) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProductToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("product_to_product", p, err)
 }
 
 func GetWCboxCheck(req Req) {
@@ -3146,19 +9798,74 @@ func GetWCboxCheck(req Req) {
 }
 
 func GetWCboxCheckAll(req Req) {
-	req.Respond(WCboxCheckGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("cbox_check", WCboxCheck{}, func(add func(interface{}) error) error {
+				_, err := WCboxCheckGetByQueryEach(q, func(c WCboxCheck) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WCboxCheckGetByQuery(q)
+		req.RespondList("cbox_check", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cbox_check", WCboxCheck{}, func(add func(interface{}) error) error {
+			return WCboxCheckGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WCboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCboxCheckGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func GetWCboxCheckByFilterInt(req Req) {
-	req.Respond(WCboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cbox_check", WCboxCheck{}, func(add func(interface{}) error) error {
+			return WCboxCheckGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WCboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func GetWCboxCheckByFilterStr(req Req) {
-	req.Respond(WCboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cbox_check", WCboxCheck{}, func(add func(interface{}) error) error {
+			return WCboxCheckGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func GetWCboxCheckBetweenCreatedAt(req Req) {
-	req.Respond(WCboxCheckGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cbox_check", WCboxCheck{}, func(add func(interface{}) error) error {
+			return WCboxCheckGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCboxCheck) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCboxCheckGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("cbox_check", c, err)
 }
 
 func GetWItemToCboxCheck(req Req) {
@@ -3166,55 +9873,275 @@ func GetWItemToCboxCheck(req Req) {
 }
 
 func GetWItemToCboxCheckAll(req Req) {
-	req.Respond(WItemToCboxCheckGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("item_to_cbox_check", WItemToCboxCheck{}, func(add func(interface{}) error) error {
+				_, err := WItemToCboxCheckGetByQueryEach(q, func(i WItemToCboxCheck) error {
+					return add(i)
+				})
+				return err
+			})
+			return
+		}
+		i, total, err := WItemToCboxCheckGetByQuery(q)
+		req.RespondList("item_to_cbox_check", i, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("item_to_cbox_check", WItemToCboxCheck{}, func(add func(interface{}) error) error {
+			return WItemToCboxCheckGetAllEach(req.WithDeleted, req.DeletedOnly, func(i WItemToCboxCheck) error {
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WItemToCboxCheckGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("item_to_cbox_check", i, err)
 }
 
 func GetWItemToCboxCheckByFilterInt(req Req) {
-	req.Respond(WItemToCboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("item_to_cbox_check", WItemToCboxCheck{}, func(add func(interface{}) error) error {
+			return WItemToCboxCheckGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(i WItemToCboxCheck) error {
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WItemToCboxCheckGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("item_to_cbox_check", i, err)
 }
 
 func GetWItemToCboxCheckByFilterStr(req Req) {
-	req.Respond(WItemToCboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("item_to_cbox_check", WItemToCboxCheck{}, func(add func(interface{}) error) error {
+			return WItemToCboxCheckGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(i WItemToCboxCheck) error {
+				return add(i)
+			})
+		})
+		return
+	}
+	i, err := WItemToCboxCheckGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("item_to_cbox_check", i, err)
 }
 
 func GetWCashIn(req Req) {
-	req.Respond(WCashInGet(req.IntParam))
+	c, err := WCashInGet(req.IntParam)
+	if err == nil && req.OwnOnly() && c.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(c, err)
 }
 
 func GetWCashInAll(req Req) {
-	req.Respond(WCashInGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("cash_in", WCashIn{}, func(add func(interface{}) error) error {
+				_, err := WCashInGetByQueryEach(q, func(c WCashIn) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WCashInGetByQuery(q)
+		req.RespondList("cash_in", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cash_in", WCashIn{}, func(add func(interface{}) error) error {
+			return WCashInGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WCashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashInGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func GetWCashInByFilterInt(req Req) {
-	req.Respond(WCashInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_in", WCashIn{}, func(add func(interface{}) error) error {
+			return WCashInGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WCashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func GetWCashInByFilterStr(req Req) {
-	req.Respond(WCashInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_in", WCashIn{}, func(add func(interface{}) error) error {
+			return WCashInGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func GetWCashInBetweenCreatedAt(req Req) {
-	req.Respond(WCashInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_in", WCashIn{}, func(add func(interface{}) error) error {
+			return WCashInGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCashIn) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashInOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_in", c, err)
 }
 
 func GetWCashOut(req Req) {
-	req.Respond(WCashOutGet(req.IntParam))
+	c, err := WCashOutGet(req.IntParam)
+	if err == nil && req.OwnOnly() && c.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(c, err)
 }
 
 func GetWCashOutAll(req Req) {
-	req.Respond(WCashOutGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("cash_out", WCashOut{}, func(add func(interface{}) error) error {
+				_, err := WCashOutGetByQueryEach(q, func(c WCashOut) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WCashOutGetByQuery(q)
+		req.RespondList("cash_out", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("cash_out", WCashOut{}, func(add func(interface{}) error) error {
+			return WCashOutGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WCashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashOutGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func GetWCashOutByFilterInt(req Req) {
-	req.Respond(WCashOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_out", WCashOut{}, func(add func(interface{}) error) error {
+			return WCashOutGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WCashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func GetWCashOutByFilterStr(req Req) {
-	req.Respond(WCashOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_out", WCashOut{}, func(add func(interface{}) error) error {
+			return WCashOutGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func GetWCashOutBetweenCreatedAt(req Req) {
-	req.Respond(WCashOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("cash_out", WCashOut{}, func(add func(interface{}) error) error {
+			return WCashOutGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCashOut) error {
+				if req.OwnOnly() && c.UserId != req.UserId {
+					return nil
+				}
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCashOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		c = WCashOutOwnedBy(c, req.UserId)
+	}
+	req.RespondRows("cash_out", c, err)
 }
 
 func GetWWhs(req Req) {
@@ -3222,91 +10149,492 @@ func GetWWhs(req Req) {
 }
 
 func GetWWhsAll(req Req) {
-	req.Respond(WWhsGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("whs", WWhs{}, func(add func(interface{}) error) error {
+				_, err := WWhsGetByQueryEach(q, func(w WWhs) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WWhsGetByQuery(q)
+		req.RespondList("whs", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("whs", WWhs{}, func(add func(interface{}) error) error {
+			return WWhsGetAllEach(req.WithDeleted, req.DeletedOnly, func(w WWhs) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("whs", w, err)
 }
 
 func GetWWhsByFilterInt(req Req) {
-	req.Respond(WWhsGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs", WWhs{}, func(add func(interface{}) error) error {
+			return WWhsGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(w WWhs) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("whs", w, err)
 }
 
 func GetWWhsByFilterStr(req Req) {
-	req.Respond(WWhsGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs", WWhs{}, func(add func(interface{}) error) error {
+			return WWhsGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWhs) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("whs", w, err)
 }
 
 func GetWWhsIn(req Req) {
-	req.Respond(WWhsInGet(req.IntParam))
+	w, err := WWhsInGet(req.IntParam)
+	if err == nil && req.OwnOnly() && w.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(w, err)
 }
 
 func GetWWhsInAll(req Req) {
-	req.Respond(WWhsInGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("whs_in", WWhsIn{}, func(add func(interface{}) error) error {
+				_, err := WWhsInGetByQueryEach(q, func(w WWhsIn) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WWhsInGetByQuery(q)
+		req.RespondList("whs_in", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("whs_in", WWhsIn{}, func(add func(interface{}) error) error {
+			return WWhsInGetAllEach(req.WithDeleted, req.DeletedOnly, func(w WWhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsInGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWWhsInByFilterInt(req Req) {
-	req.Respond(WWhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_in", WWhsIn{}, func(add func(interface{}) error) error {
+			return WWhsInGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(w WWhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWWhsInByFilterStr(req Req) {
-	req.Respond(WWhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_in", WWhsIn{}, func(add func(interface{}) error) error {
+			return WWhsInGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWWhsInBetweenCreatedAt(req Req) {
-	req.Respond(WWhsInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_in", WWhsIn{}, func(add func(interface{}) error) error {
+			return WWhsInGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsInGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWWhsInBetweenContragentCreatedAt(req Req) {
-	req.Respond(WWhsInGetBetweenContragentCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_in", WWhsIn{}, func(add func(interface{}) error) error {
+			return WWhsInGetBetweenContragentCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWhsIn) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsInGetBetweenContragentCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsInOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_in", w, err)
 }
 
 func GetWWhsOut(req Req) {
-	req.Respond(WWhsOutGet(req.IntParam))
+	w, err := WWhsOutGet(req.IntParam)
+	if err == nil && req.OwnOnly() && w.UserId != req.UserId {
+		req.Respond(nil, ErrNotOwner)
+		return
+	}
+	req.Respond(w, err)
 }
 
 func GetWWhsOutAll(req Req) {
-	req.Respond(WWhsOutGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.Owned(req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("whs_out", WWhsOut{}, func(add func(interface{}) error) error {
+				_, err := WWhsOutGetByQueryEach(q, func(w WWhsOut) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WWhsOutGetByQuery(q)
+		req.RespondList("whs_out", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("whs_out", WWhsOut{}, func(add func(interface{}) error) error {
+			return WWhsOutGetAllEach(req.WithDeleted, req.DeletedOnly, func(w WWhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsOutGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func GetWWhsOutByFilterInt(req Req) {
-	req.Respond(WWhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_out", WWhsOut{}, func(add func(interface{}) error) error {
+			return WWhsOutGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(w WWhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func GetWWhsOutByFilterStr(req Req) {
-	req.Respond(WWhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_out", WWhsOut{}, func(add func(interface{}) error) error {
+			return WWhsOutGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func GetWWhsOutBetweenCreatedAt(req Req) {
-	req.Respond(WWhsOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("whs_out", WWhsOut{}, func(add func(interface{}) error) error {
+			return WWhsOutGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWhsOut) error {
+				if req.OwnOnly() && w.UserId != req.UserId {
+					return nil
+				}
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWhsOutGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		w = WWhsOutOwnedBy(w, req.UserId)
+	}
+	req.RespondRows("whs_out", w, err)
 }
 
 func GetWMatherialToWhsIn(req Req) {
-	req.Respond(WMatherialToWhsInGet(req.IntParam))
+	m, err := WMatherialToWhsInGet(req.IntParam)
+	if err == nil {
+		err = req.OwnDocCheck("whs_in", m.WhsInId)
+	}
+	req.Respond(m, err)
 }
 
 func GetWMatherialToWhsInAll(req Req) {
-	req.Respond(WMatherialToWhsInGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("whs_in_id", "whs_in", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_whs_in", WMatherialToWhsIn{}, func(add func(interface{}) error) error {
+				_, err := WMatherialToWhsInGetByQueryEach(q, func(m WMatherialToWhsIn) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialToWhsInGetByQuery(q)
+		req.RespondList("matherial_to_whs_in", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_whs_in", WMatherialToWhsIn{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_in")
+			if err != nil {
+				return err
+			}
+			return WMatherialToWhsInGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialToWhsIn) error {
+				if owned != nil && !owned[m.WhsInId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToWhsInGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToWhsInOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_in", m, err)
 }
 
 func GetWMatherialToWhsInByFilterInt(req Req) {
-	req.Respond(WMatherialToWhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_in", WMatherialToWhsIn{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_in")
+			if err != nil {
+				return err
+			}
+			return WMatherialToWhsInGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialToWhsIn) error {
+				if owned != nil && !owned[m.WhsInId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToWhsInGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToWhsInOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_in", m, err)
 }
 
 func GetWMatherialToWhsInByFilterStr(req Req) {
-	req.Respond(WMatherialToWhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_in", WMatherialToWhsIn{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_in")
+			if err != nil {
+				return err
+			}
+			return WMatherialToWhsInGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialToWhsIn) error {
+				if owned != nil && !owned[m.WhsInId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToWhsInGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToWhsInOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_in", m, err)
 }
 
 func GetWMatherialToWhsOut(req Req) {
-	req.Respond(WMatherialToWhsOutGet(req.IntParam))
+	m, err := WMatherialToWhsOutGet(req.IntParam)
+	if err == nil {
+		err = req.OwnDocCheck("whs_out", m.WhsOutId)
+	}
+	req.Respond(m, err)
 }
 
 func GetWMatherialToWhsOutAll(req Req) {
-	req.Respond(WMatherialToWhsOutGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.OwnOnly() {
+			q = q.OwnedIn("whs_out_id", "whs_out", req.UserId)
+		}
+		if req.Exporting() {
+			req.Export("matherial_to_whs_out", WMatherialToWhsOut{}, func(add func(interface{}) error) error {
+				_, err := WMatherialToWhsOutGetByQueryEach(q, func(m WMatherialToWhsOut) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialToWhsOutGetByQuery(q)
+		req.RespondList("matherial_to_whs_out", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_to_whs_out", WMatherialToWhsOut{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_out")
+			if err != nil {
+				return err
+			}
+			return WMatherialToWhsOutGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialToWhsOut) error {
+				if owned != nil && !owned[m.WhsOutId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToWhsOutGetAll(req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToWhsOutOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_out", m, err)
 }
 
 func GetWMatherialToWhsOutByFilterInt(req Req) {
-	req.Respond(WMatherialToWhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_out", WMatherialToWhsOut{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_out")
+			if err != nil {
+				return err
+			}
+			return WMatherialToWhsOutGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialToWhsOut) error {
+				if owned != nil && !owned[m.WhsOutId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToWhsOutGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToWhsOutOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_out", m, err)
 }
 
 func GetWMatherialToWhsOutByFilterStr(req Req) {
-	req.Respond(WMatherialToWhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_to_whs_out", WMatherialToWhsOut{}, func(add func(interface{}) error) error {
+			owned, err := req.OwnDocs("whs_out")
+			if err != nil {
+				return err
+			}
+			return WMatherialToWhsOutGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialToWhsOut) error {
+				if owned != nil && !owned[m.WhsOutId] {
+					return nil
+				}
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialToWhsOutGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	if err == nil && req.OwnOnly() {
+		m, err = WMatherialToWhsOutOwnedBy(m, req.UserId)
+	}
+	req.RespondRows("matherial_to_whs_out", m, err)
 }
 
 func GetWMatherialPart(req Req) {
@@ -3314,19 +10642,74 @@ func GetWMatherialPart(req Req) {
 }
 
 func GetWMatherialPartAll(req Req) {
-	req.Respond(WMatherialPartGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_part", WMatherialPart{}, func(add func(interface{}) error) error {
+				_, err := WMatherialPartGetByQueryEach(q, func(m WMatherialPart) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialPartGetByQuery(q)
+		req.RespondList("matherial_part", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_part", WMatherialPart{}, func(add func(interface{}) error) error {
+			return WMatherialPartGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func GetWMatherialPartByFilterInt(req Req) {
-	req.Respond(WMatherialPartGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part", WMatherialPart{}, func(add func(interface{}) error) error {
+			return WMatherialPartGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func GetWMatherialPartByFilterStr(req Req) {
-	req.Respond(WMatherialPartGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part", WMatherialPart{}, func(add func(interface{}) error) error {
+			return WMatherialPartGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func GetWMatherialPartBetweenCreatedAt(req Req) {
-	req.Respond(WMatherialPartGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part", WMatherialPart{}, func(add func(interface{}) error) error {
+			return WMatherialPartGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialPart) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part", m, err)
 }
 
 func GetWMatherialPartSlice(req Req) {
@@ -3334,19 +10717,74 @@ func GetWMatherialPartSlice(req Req) {
 }
 
 func GetWMatherialPartSliceAll(req Req) {
-	req.Respond(WMatherialPartSliceGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("matherial_part_slice", WMatherialPartSlice{}, func(add func(interface{}) error) error {
+				_, err := WMatherialPartSliceGetByQueryEach(q, func(m WMatherialPartSlice) error {
+					return add(m)
+				})
+				return err
+			})
+			return
+		}
+		m, total, err := WMatherialPartSliceGetByQuery(q)
+		req.RespondList("matherial_part_slice", m, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("matherial_part_slice", WMatherialPartSlice{}, func(add func(interface{}) error) error {
+			return WMatherialPartSliceGetAllEach(req.WithDeleted, req.DeletedOnly, func(m WMatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartSliceGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func GetWMatherialPartSliceByFilterInt(req Req) {
-	req.Respond(WMatherialPartSliceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part_slice", WMatherialPartSlice{}, func(add func(interface{}) error) error {
+			return WMatherialPartSliceGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(m WMatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartSliceGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func GetWMatherialPartSliceByFilterStr(req Req) {
-	req.Respond(WMatherialPartSliceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part_slice", WMatherialPartSlice{}, func(add func(interface{}) error) error {
+			return WMatherialPartSliceGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartSliceGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func GetWMatherialPartSliceBetweenCreatedAt(req Req) {
-	req.Respond(WMatherialPartSliceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("matherial_part_slice", WMatherialPartSlice{}, func(add func(interface{}) error) error {
+			return WMatherialPartSliceGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(m WMatherialPartSlice) error {
+				return add(m)
+			})
+		})
+		return
+	}
+	m, err := WMatherialPartSliceGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("matherial_part_slice", m, err)
 }
 
 func GetWProjectGroup(req Req) {
@@ -3354,15 +10792,61 @@ func GetWProjectGroup(req Req) {
 }
 
 func GetWProjectGroupAll(req Req) {
-	req.Respond(WProjectGroupGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project_group", WProjectGroup{}, func(add func(interface{}) error) error {
+				_, err := WProjectGroupGetByQueryEach(q, func(p WProjectGroup) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProjectGroupGetByQuery(q)
+		req.RespondList("project_group", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project_group", WProjectGroup{}, func(add func(interface{}) error) error {
+			return WProjectGroupGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProjectGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGroupGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_group", p, err)
 }
 
 func GetWProjectGroupByFilterInt(req Req) {
-	req.Respond(WProjectGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project_group", WProjectGroup{}, func(add func(interface{}) error) error {
+			return WProjectGroupGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProjectGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGroupGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_group", p, err)
 }
 
 func GetWProjectGroupByFilterStr(req Req) {
-	req.Respond(WProjectGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project_group", WProjectGroup{}, func(add func(interface{}) error) error {
+			return WProjectGroupGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProjectGroup) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGroupGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_group", p, err)
 }
 
 func GetWProjectStatus(req Req) {
@@ -3370,15 +10854,61 @@ func GetWProjectStatus(req Req) {
 }
 
 func GetWProjectStatusAll(req Req) {
-	req.Respond(WProjectStatusGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project_status", WProjectStatus{}, func(add func(interface{}) error) error {
+				_, err := WProjectStatusGetByQueryEach(q, func(p WProjectStatus) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProjectStatusGetByQuery(q)
+		req.RespondList("project_status", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project_status", WProjectStatus{}, func(add func(interface{}) error) error {
+			return WProjectStatusGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProjectStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectStatusGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_status", p, err)
 }
 
 func GetWProjectStatusByFilterInt(req Req) {
-	req.Respond(WProjectStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project_status", WProjectStatus{}, func(add func(interface{}) error) error {
+			return WProjectStatusGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProjectStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectStatusGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_status", p, err)
 }
 
 func GetWProjectStatusByFilterStr(req Req) {
-	req.Respond(WProjectStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project_status", WProjectStatus{}, func(add func(interface{}) error) error {
+			return WProjectStatusGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProjectStatus) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectStatusGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_status", p, err)
 }
 
 func GetWProjectType(req Req) {
@@ -3386,15 +10916,61 @@ func GetWProjectType(req Req) {
 }
 
 func GetWProjectTypeAll(req Req) {
-	req.Respond(WProjectTypeGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project_type", WProjectType{}, func(add func(interface{}) error) error {
+				_, err := WProjectTypeGetByQueryEach(q, func(p WProjectType) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProjectTypeGetByQuery(q)
+		req.RespondList("project_type", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project_type", WProjectType{}, func(add func(interface{}) error) error {
+			return WProjectTypeGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProjectType) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectTypeGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_type", p, err)
 }
 
 func GetWProjectTypeByFilterInt(req Req) {
-	req.Respond(WProjectTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project_type", WProjectType{}, func(add func(interface{}) error) error {
+			return WProjectTypeGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProjectType) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectTypeGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_type", p, err)
 }
 
 func GetWProjectTypeByFilterStr(req Req) {
-	req.Respond(WProjectTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project_type", WProjectType{}, func(add func(interface{}) error) error {
+			return WProjectTypeGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProjectType) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectTypeGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project_type", p, err)
 }
 
 func GetWProject(req Req) {
@@ -3402,23 +10978,87 @@ func GetWProject(req Req) {
 }
 
 func GetWProjectAll(req Req) {
-	req.Respond(WProjectGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("project", WProject{}, func(add func(interface{}) error) error {
+				_, err := WProjectGetByQueryEach(q, func(p WProject) error {
+					return add(p)
+				})
+				return err
+			})
+			return
+		}
+		p, total, err := WProjectGetByQuery(q)
+		req.RespondList("project", p, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("project", WProject{}, func(add func(interface{}) error) error {
+			return WProjectGetAllEach(req.WithDeleted, req.DeletedOnly, func(p WProject) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project", p, err)
 }
 
 func GetWProjectByFilterInt(req Req) {
-	req.Respond(WProjectGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project", WProject{}, func(add func(interface{}) error) error {
+			return WProjectGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(p WProject) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project", p, err)
 }
 
 func GetWProjectByFilterStr(req Req) {
-	req.Respond(WProjectGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project", WProject{}, func(add func(interface{}) error) error {
+			return WProjectGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProject) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project", p, err)
 }
 
 func GetWProjectFindByProjectInfoContragentNoSearchContactNoSearch(req Req) {
-	req.Respond(WProjectFindByProjectInfoContragentNoSearchContactNoSearch(req.StrParam))
+	if req.Exporting() {
+		req.Export("project", WProject{}, func(add func(interface{}) error) error {
+			return WProjectFindByProjectInfoContragentNoSearchContactNoSearchEach(req.StrParam, func(p WProject) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectFindByProjectInfoContragentNoSearchContactNoSearch(req.StrParam)
+	req.RespondRows("project", p, err)
 }
 
 func GetWProjectBetweenCreatedAt(req Req) {
-	req.Respond(WProjectGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("project", WProject{}, func(add func(interface{}) error) error {
+			return WProjectGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(p WProject) error {
+				return add(p)
+			})
+		})
+		return
+	}
+	p, err := WProjectGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("project", p, err)
 }
 
 func GetWCounter(req Req) {
@@ -3426,15 +11066,61 @@ func GetWCounter(req Req) {
 }
 
 func GetWCounterAll(req Req) {
-	req.Respond(WCounterGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("counter", WCounter{}, func(add func(interface{}) error) error {
+				_, err := WCounterGetByQueryEach(q, func(c WCounter) error {
+					return add(c)
+				})
+				return err
+			})
+			return
+		}
+		c, total, err := WCounterGetByQuery(q)
+		req.RespondList("counter", c, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("counter", WCounter{}, func(add func(interface{}) error) error {
+			return WCounterGetAllEach(req.WithDeleted, req.DeletedOnly, func(c WCounter) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCounterGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("counter", c, err)
 }
 
 func GetWCounterByFilterInt(req Req) {
-	req.Respond(WCounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("counter", WCounter{}, func(add func(interface{}) error) error {
+			return WCounterGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(c WCounter) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("counter", c, err)
 }
 
 func GetWCounterByFilterStr(req Req) {
-	req.Respond(WCounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("counter", WCounter{}, func(add func(interface{}) error) error {
+			return WCounterGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(c WCounter) error {
+				return add(c)
+			})
+		})
+		return
+	}
+	c, err := WCounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("counter", c, err)
 }
 
 func GetWRecordToCounter(req Req) {
@@ -3442,19 +11128,74 @@ func GetWRecordToCounter(req Req) {
 }
 
 func GetWRecordToCounterAll(req Req) {
-	req.Respond(WRecordToCounterGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("record_to_counter", WRecordToCounter{}, func(add func(interface{}) error) error {
+				_, err := WRecordToCounterGetByQueryEach(q, func(r WRecordToCounter) error {
+					return add(r)
+				})
+				return err
+			})
+			return
+		}
+		r, total, err := WRecordToCounterGetByQuery(q)
+		req.RespondList("record_to_counter", r, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("record_to_counter", WRecordToCounter{}, func(add func(interface{}) error) error {
+			return WRecordToCounterGetAllEach(req.WithDeleted, req.DeletedOnly, func(r WRecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := WRecordToCounterGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func GetWRecordToCounterByFilterInt(req Req) {
-	req.Respond(WRecordToCounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("record_to_counter", WRecordToCounter{}, func(add func(interface{}) error) error {
+			return WRecordToCounterGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(r WRecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := WRecordToCounterGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func GetWRecordToCounterByFilterStr(req Req) {
-	req.Respond(WRecordToCounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("record_to_counter", WRecordToCounter{}, func(add func(interface{}) error) error {
+			return WRecordToCounterGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(r WRecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := WRecordToCounterGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func GetWRecordToCounterBetweenCreatedAt(req Req) {
-	req.Respond(WRecordToCounterGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("record_to_counter", WRecordToCounter{}, func(add func(interface{}) error) error {
+			return WRecordToCounterGetBetweenCreatedAtEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(r WRecordToCounter) error {
+				return add(r)
+			})
+		})
+		return
+	}
+	r, err := WRecordToCounterGetBetweenCreatedAt(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("record_to_counter", r, err)
 }
 
 func GetWWmcNumber(req Req) {
@@ -3462,15 +11203,61 @@ func GetWWmcNumber(req Req) {
 }
 
 func GetWWmcNumberAll(req Req) {
-	req.Respond(WWmcNumberGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("wmc_number", WWmcNumber{}, func(add func(interface{}) error) error {
+				_, err := WWmcNumberGetByQueryEach(q, func(w WWmcNumber) error {
+					return add(w)
+				})
+				return err
+			})
+			return
+		}
+		w, total, err := WWmcNumberGetByQuery(q)
+		req.RespondList("wmc_number", w, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("wmc_number", WWmcNumber{}, func(add func(interface{}) error) error {
+			return WWmcNumberGetAllEach(req.WithDeleted, req.DeletedOnly, func(w WWmcNumber) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWmcNumberGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("wmc_number", w, err)
 }
 
 func GetWWmcNumberByFilterInt(req Req) {
-	req.Respond(WWmcNumberGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("wmc_number", WWmcNumber{}, func(add func(interface{}) error) error {
+			return WWmcNumberGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(w WWmcNumber) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWmcNumberGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("wmc_number", w, err)
 }
 
 func GetWWmcNumberByFilterStr(req Req) {
-	req.Respond(WWmcNumberGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("wmc_number", WWmcNumber{}, func(add func(interface{}) error) error {
+			return WWmcNumberGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(w WWmcNumber) error {
+				return add(w)
+			})
+		})
+		return
+	}
+	w, err := WWmcNumberGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("wmc_number", w, err)
 }
 
 func GetWNumbersToProduct(req Req) {
@@ -3478,13 +11265,59 @@ func GetWNumbersToProduct(req Req) {
 }
 
 func GetWNumbersToProductAll(req Req) {
-	req.Respond(WNumbersToProductGetAll(req.WithDeleted, req.DeletedOnly))
+	if req.HasListQuery() {
+		q, err := req.ListQuery()
+		if err != nil {
+			req.Respond(nil, err)
+			return
+		}
+		if req.Exporting() {
+			req.Export("numbers_to_product", WNumbersToProduct{}, func(add func(interface{}) error) error {
+				_, err := WNumbersToProductGetByQueryEach(q, func(n WNumbersToProduct) error {
+					return add(n)
+				})
+				return err
+			})
+			return
+		}
+		n, total, err := WNumbersToProductGetByQuery(q)
+		req.RespondList("numbers_to_product", n, total, err)
+		return
+	}
+	if req.Exporting() {
+		req.Export("numbers_to_product", WNumbersToProduct{}, func(add func(interface{}) error) error {
+			return WNumbersToProductGetAllEach(req.WithDeleted, req.DeletedOnly, func(n WNumbersToProduct) error {
+				return add(n)
+			})
+		})
+		return
+	}
+	n, err := WNumbersToProductGetAll(req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("numbers_to_product", n, err)
 }
 
 func GetWNumbersToProductByFilterInt(req Req) {
-	req.Respond(WNumbersToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("numbers_to_product", WNumbersToProduct{}, func(add func(interface{}) error) error {
+			return WNumbersToProductGetByFilterIntEach(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly, func(n WNumbersToProduct) error {
+				return add(n)
+			})
+		})
+		return
+	}
+	n, err := WNumbersToProductGetByFilterInt(req.StrParam, req.IntParam, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("numbers_to_product", n, err)
 }
 
 func GetWNumbersToProductByFilterStr(req Req) {
-	req.Respond(WNumbersToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly))
+	if req.Exporting() {
+		req.Export("numbers_to_product", WNumbersToProduct{}, func(add func(interface{}) error) error {
+			return WNumbersToProductGetByFilterStrEach(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly, func(n WNumbersToProduct) error {
+				return add(n)
+			})
+		})
+		return
+	}
+	n, err := WNumbersToProductGetByFilterStr(req.StrParam, req.Str2Param, req.WithDeleted, req.DeletedOnly)
+	req.RespondRows("numbers_to_product", n, err)
 }
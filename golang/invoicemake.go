@@ -0,0 +1,246 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Invoice of an ordering: the server makes it as the client did, one line for
+// each top level product, matherial and operation of the ordering. Sized
+// lines are counted in pieces (measure_pieces of the config) with the size
+// in the name. With apply_persent the lines are scaled to the rounded cost
+// of the ordering, the last line takes the rounding, otherwise lines keep
+// their own costs. With group lines of the same name, measure and price
+// are merged.
+
+type MakeInvoiceReq struct {
+	OwnerId      int  `json:"owner_id"`
+	Group        bool `json:"group"`
+	ApplyPersent bool `json:"apply_persent"`
+}
+
+type InvoiceWithItems struct {
+	Invoice Invoice         `json:"invoice"`
+	Items   []ItemToInvoice `json:"items"`
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// invoiceOwner returns the owner asked, else the owner of the last invoice of
+// the contragent, else the first active owner
+func invoiceOwner(ownerId, contragentId int, tx *Tx) (int, error) {
+	if ownerId != 0 {
+		return ownerId, nil
+	}
+	err := tx.QueryRow(`SELECT owner_id FROM invoice WHERE contragent_id=? AND owner_id != 0 AND is_active=1
+		ORDER BY id DESC LIMIT 1`, contragentId).Scan(&ownerId)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow("SELECT id FROM owner WHERE is_active=1 ORDER BY id LIMIT 1").Scan(&ownerId)
+		if err == sql.ErrNoRows {
+			return 0, errors.New("no owner for the invoice")
+		}
+	}
+	return ownerId, err
+}
+
+// invoiceSizedLine counts the sized line in pieces and adds the size to the name
+func invoiceSizedLine(name string, width, length float64, pieces int) (ItemToInvoice, error) {
+	if Cfg.MeasurePieces == 0 {
+		return ItemToInvoice{}, errors.New("measure_pieces is not set in the config")
+	}
+	size := fmt.Sprintf("%gx%gмм", width, length)
+	if !strings.Contains(name, size) {
+		name += " " + size
+	}
+	return ItemToInvoice{Name: name, Number: float64(pieces), MeasureId: Cfg.MeasurePieces}, nil
+}
+
+// OrderingInvoiceItems returns lines of the invoice of the ordering
+func OrderingInvoiceItems(o Ordering, group, applyPersent bool, tx *Tx) ([]ItemToInvoice, error) {
+	items := []ItemToInvoice{}
+	p2os, err := ProductToOrderingGetByFilterInt("ordering_id", o.Id, false, false, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p2o := range p2os {
+		if p2o.ProductToOrderingId != 0 {
+			continue
+		}
+		item := ItemToInvoice{Name: p2o.Name, Number: p2o.Number}
+		if p2o.Width != 0 {
+			item, err = invoiceSizedLine(p2o.Name, p2o.Width, p2o.Length, p2o.Pieces)
+		} else {
+			var p Product
+			p, err = ProductGet(p2o.ProductId, tx)
+			item.MeasureId = p.MeasureId
+		}
+		if err != nil {
+			return nil, err
+		}
+		item.Cost = p2o.Cost
+		items = append(items, item)
+	}
+	m2os, err := MatherialToOrderingGetByFilterInt("ordering_id", o.Id, false, false, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m2o := range m2os {
+		if m2o.ProductToOrderingId != 0 {
+			continue
+		}
+		m, err := MatherialGet(m2o.MatherialId, tx)
+		if err != nil {
+			return nil, err
+		}
+		item := ItemToInvoice{Name: m.Name, Number: m2o.Number, MeasureId: m.MeasureId}
+		if m2o.Width != 0 {
+			item, err = invoiceSizedLine(m.Name, m2o.Width, m2o.Length, m2o.Pieces)
+			if err != nil {
+				return nil, err
+			}
+		}
+		item.Cost = m2o.Cost
+		items = append(items, item)
+	}
+	o2os, err := OperationToOrderingGetByFilterInt("ordering_id", o.Id, false, false, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, o2o := range o2os {
+		if o2o.ProductToOrderingId != 0 {
+			continue
+		}
+		op, err := OperationGet(o2o.OperationId, tx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, ItemToInvoice{Name: op.Name, Number: o2o.Number, MeasureId: op.MeasureId, Cost: o2o.Cost})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("ordering %d has no products", o.Id)
+	}
+	if applyPersent {
+		if o.Price == 0 {
+			return nil, fmt.Errorf("ordering %d has no price", o.Id)
+		}
+		total := math.Round(o.Cost)
+		k := total / o.Price
+		rest := total
+		for i := range items {
+			if i == len(items)-1 {
+				items[i].Cost = round2(rest)
+			} else {
+				items[i].Cost = round2(items[i].Cost * k)
+				rest -= items[i].Cost
+			}
+		}
+	}
+	for i := range items {
+		items[i].Price = items[i].Cost
+		if items[i].Number != 0 {
+			items[i].Price = round2(items[i].Cost / items[i].Number)
+		}
+	}
+	if !group {
+		return items, nil
+	}
+	type key struct {
+		name      string
+		measureId int
+		price     float64
+	}
+	grouped := []ItemToInvoice{}
+	index := map[key]int{}
+	for _, item := range items {
+		k := key{item.Name, item.MeasureId, item.Price}
+		if i, ok := index[k]; ok {
+			grouped[i].Number += item.Number
+			grouped[i].Cost = round2(grouped[i].Cost + item.Cost)
+			continue
+		}
+		index[k] = len(grouped)
+		grouped = append(grouped, item)
+	}
+	return grouped, nil
+}
+
+// OrderingMakeInvoice creates the invoice of the ordering with its lines
+func OrderingMakeInvoice(id int, m MakeInvoiceReq, tx *Tx) (InvoiceWithItems, error) {
+	res := InvoiceWithItems{Items: []ItemToInvoice{}}
+	o, err := OrderingGet(id, tx)
+	if err != nil {
+		return res, err
+	}
+	if !o.IsActive {
+		return res, fmt.Errorf("ordering %d is deleted", id)
+	}
+	items, err := OrderingInvoiceItems(o, m.Group, m.ApplyPersent, tx)
+	if err != nil {
+		return res, err
+	}
+	ownerId, err := invoiceOwner(m.OwnerId, o.ContragentId, tx)
+	if err != nil {
+		return res, err
+	}
+	i, err := InvoiceCreate(Invoice{
+		OrderingId:   o.Id,
+		BasedOn:      fmt.Sprintf("ordering.%d", o.Id),
+		OwnerId:      ownerId,
+		Name:         fmt.Sprintf("Рахунок до зам. %d", o.Id),
+		UserId:       tx.UserId,
+		ContragentId: o.ContragentId,
+		ContactId:    o.ContactId,
+		LegalId:      o.LegalId,
+		IsActive:     true,
+	}, tx)
+	if err != nil {
+		return res, err
+	}
+	for _, item := range items {
+		item.InvoiceId = i.Id
+		item.IsActive = true
+		item, err = ItemToInvoiceCreate(item, tx)
+		if err != nil {
+			return res, err
+		}
+		res.Items = append(res.Items, item)
+	}
+	// cash_sum is counted by the lines
+	res.Invoice, err = InvoiceGet(i.Id, tx)
+	return res, err
+}
+
+// Invoice of ordering handlers
+
+func MakeOrderingInvoice(r Req) {
+	var m MakeInvoiceReq
+	if r.R.ContentLength != 0 {
+		decoder := json.NewDecoder(r.R.Body)
+		defer r.R.Body.Close()
+		err := decoder.Decode(&m)
+		if err != nil {
+			r.Respond(nil, err)
+			return
+		}
+	}
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	var res InvoiceWithItems
+	o, err := OrderingGet(r.IntParam, tx)
+	if err == nil && r.OwnOnly() && o.UserId != r.UserId {
+		err = ErrNotOwner
+	}
+	if err == nil {
+		res, err = OrderingMakeInvoice(r.IntParam, m, tx)
+	}
+	r.RespondTx(tx, res, err)
+}
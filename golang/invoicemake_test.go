@@ -0,0 +1,137 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testOrdering creates the ordering with two product lines of one price,
+// a nested product and an operation
+func testOrdering(t *testing.T) Ordering {
+	t.Helper()
+	m, err := MeasureCreate(Measure{Name: "pcs", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ProductCreate(Product{Name: "Card", MeasureId: m.Id, IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := OperationCreate(Operation{Name: "Cut", MeasureId: m.Id, IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := OrderingCreate(Ordering{Name: "order", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2o, err := ProductToOrderingCreate(ProductToOrdering{OrderingId: o.Id, ProductId: p.Id, Name: "Card",
+		Number: 2, Cost: 30, IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []ProductToOrdering{
+		{OrderingId: o.Id, ProductId: p.Id, Name: "Card", Number: 4, Cost: 60, IsActive: true},
+		{OrderingId: o.Id, ProductId: p.Id, Name: "Inner", Number: 1, Cost: 5, ProductToOrderingId: p2o.Id, IsActive: true},
+	} {
+		if _, err = ProductToOrderingCreate(line, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = OperationToOrderingCreate(OperationToOrdering{OrderingId: o.Id, OperationId: op.Id,
+		Number: 1, Cost: 10, IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err = OrderingGet(o.Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.Price, o.Cost = 100, 120.4
+	return o
+}
+
+func TestOrderingInvoiceItems(t *testing.T) {
+	testBase(t)
+	o := testOrdering(t)
+	type line struct {
+		Name   string
+		Number float64
+		Price  float64
+		Cost   float64
+	}
+	tests := []struct {
+		name         string
+		group        bool
+		applyPersent bool
+		want         []line
+	}{
+		{"lines", false, false, []line{{"Card", 2, 15, 30}, {"Card", 4, 15, 60}, {"Cut", 1, 10, 10}}},
+		{"grouped", true, false, []line{{"Card", 6, 15, 90}, {"Cut", 1, 10, 10}}},
+		{"scaled to the rounded cost", false, true, []line{{"Card", 2, 18, 36}, {"Card", 4, 18, 72}, {"Cut", 1, 12, 12}}},
+	}
+	for _, tt := range tests {
+		tx, err := Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := OrderingInvoiceItems(o, tt.group, tt.applyPersent, tx)
+		tx.Rollback()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := []line{}
+		for _, item := range items {
+			got = append(got, line{item.Name, item.Number, item.Price, item.Cost})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: lines %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrderingMakeInvoice(t *testing.T) {
+	testBase(t)
+	o := testOrdering(t)
+	empty, err := OrderingCreate(Ordering{Name: "empty", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := testOrdering(t)
+	if _, err = OrderingDelete(deleted.Id, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		orderingId int
+		owner      bool
+		wantErr    bool
+	}{
+		{"no owner", o.Id, false, true},
+		{"ordering", o.Id, true, false},
+		{"no products", empty.Id, true, true},
+		{"deleted ordering", deleted.Id, true, true},
+	}
+	for _, tt := range tests {
+		if tt.owner {
+			if _, err := OwnerCreate(Owner{Name: "owner", IsActive: true}, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tx, err := Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := OrderingMakeInvoice(tt.orderingId, MakeInvoiceReq{}, tx)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: OrderingMakeInvoice error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil {
+			if len(res.Items) != 3 || res.Invoice.CashSum != 100 || res.Invoice.OrderingId != o.Id || res.Invoice.OwnerId == 0 {
+				t.Errorf("%s: invoice %+v with %d lines", tt.name, res.Invoice, len(res.Items))
+			}
+		}
+		tx.Rollback()
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Ledger checker: running totals (cash, contragent, legal, matherial, wmc_number...)
+// are changed by registers of documents step by step and may drift.
+// The checker recomputes them from active documents by ledgerRegisters
+// generated from models.json: register counts always, rz_register only
+// for realized documents, complex_register is keyed by several fields.
+
+type LedgerRegister struct {
+	Source    string
+	Parent    string // document of the item, "" for documents
+	Target    string
+	Field     string
+	Values    []string
+	Sign      float64
+	Realized  bool
+	KeyFields []string
+	KeyValues []string // "doc.field" is the field of the parent document
+}
+
+type LedgerDiff struct {
+	Entity   string  `json:"entity"`
+	Id       int     `json:"id"`
+	Field    string  `json:"field"`
+	Actual   float64 `json:"actual"`
+	Expected float64 `json:"expected"`
+	Key      []int   `json:"key,omitempty"`
+}
+
+const ledgerTolerance = 0.005
+
+type ledgerTarget struct {
+	table string
+	field string
+}
+
+// ledgerSum returns SELECT of summed values of the register grouped by the target key
+func ledgerSum(reg LedgerRegister) string {
+	values := []string{}
+	for _, v := range reg.Values {
+		values = append(values, "s."+v)
+	}
+	keys := []string{"s." + reg.Target + "_id"}
+	if len(reg.KeyFields) > 0 {
+		keys = []string{}
+		for _, v := range reg.KeyValues {
+			if i := strings.Index(v, "."); i >= 0 {
+				keys = append(keys, "p."+v[i+1:])
+			} else {
+				keys = append(keys, "s."+v)
+			}
+		}
+	}
+	query := fmt.Sprintf("SELECT %s, total(%s) FROM %s s", strings.Join(keys, ", "), strings.Join(values, " + "), reg.Source)
+	where := " WHERE s.is_active = 1"
+	if reg.Parent != "" {
+		query += fmt.Sprintf(" JOIN %s p ON p.id = s.%s_id AND p.is_active = 1", reg.Parent, reg.Parent)
+		if reg.Realized {
+			query += " AND p.is_realized = 1"
+		}
+	} else if reg.Realized {
+		where += " AND s.is_realized = 1"
+	}
+	return query + where + " GROUP BY " + strings.Join(keys, ", ")
+}
+
+// scanKey scans the key of n ints followed by the value
+func scanKey(rows *sql.Rows, n int, head ...interface{}) ([]int, float64, error) {
+	key := make([]int, n)
+	var value float64
+	dest := head
+	for i := range key {
+		dest = append(dest, &key[i])
+	}
+	err := rows.Scan(append(dest, &value)...)
+	return key, value, err
+}
+
+// ledgerState is the expected value of every target field by its key
+type ledgerState struct {
+	targets   []ledgerTarget
+	keyFields map[ledgerTarget][]string
+	keys      map[ledgerTarget]map[string][]int
+	expected  map[ledgerTarget]map[string]float64
+}
+
+// ledgerExpected recomputes expected totals of the registers from active documents
+func ledgerExpected(regs []LedgerRegister, tx *Tx) (ledgerState, error) {
+	st := ledgerState{
+		keyFields: map[ledgerTarget][]string{},
+		keys:      map[ledgerTarget]map[string][]int{},
+		expected:  map[ledgerTarget]map[string]float64{},
+	}
+	for _, reg := range regs {
+		t := ledgerTarget{reg.Target, reg.Field}
+		if st.expected[t] == nil {
+			st.targets = append(st.targets, t)
+			st.keyFields[t] = reg.KeyFields
+			st.keys[t] = map[string][]int{}
+			st.expected[t] = map[string]float64{}
+		}
+		rows, err := tx.Query(ledgerSum(reg))
+		if err != nil {
+			return st, fmt.Errorf("%s to %s.%s: %w", reg.Source, reg.Target, reg.Field, err)
+		}
+		n := len(reg.KeyFields)
+		if n == 0 {
+			n = 1
+		}
+		for rows.Next() {
+			key, sum, err := scanKey(rows, n)
+			if err != nil {
+				rows.Close()
+				return st, err
+			}
+			k := fmt.Sprint(key)
+			st.keys[t][k] = key
+			st.expected[t][k] += reg.Sign * sum
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+// LedgerCheck recomputes totals and returns the rows which differ from expected values,
+// a missing row of complex register has id 0
+func LedgerCheck(tx *Tx) ([]LedgerDiff, error) {
+	st, err := ledgerExpected(ledgerRegisters, tx)
+	if err != nil {
+		return nil, err
+	}
+	keyFields, keys, expected := st.keyFields, st.keys, st.expected
+	res := []LedgerDiff{}
+	for _, t := range st.targets {
+		fields := keyFields[t]
+		if len(fields) == 0 {
+			fields = []string{"id"}
+		}
+		rows, err := tx.Query(fmt.Sprintf("SELECT id, %s, %s FROM %s ORDER BY id",
+			strings.Join(fields, ", "), t.field, t.table))
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		for rows.Next() {
+			var id int
+			key, actual, err := scanKey(rows, len(fields), &id)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			k := fmt.Sprint(key)
+			// registers change the first row of the key, the others must be 0
+			want := 0.0
+			if !seen[k] {
+				want = expected[t][k]
+				seen[k] = true
+			}
+			if math.Abs(actual-want) > ledgerTolerance {
+				d := LedgerDiff{Entity: t.table, Id: id, Field: t.field, Actual: actual, Expected: want}
+				if len(keyFields[t]) > 0 {
+					d.Key = key
+				}
+				res = append(res, d)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(keyFields[t]) == 0 {
+			// ids without a row are references to deleted rows, registers skip them too
+			continue
+		}
+		missing := []LedgerDiff{}
+		for k, want := range expected[t] {
+			if !seen[k] && math.Abs(want) > ledgerTolerance {
+				missing = append(missing, LedgerDiff{Entity: t.table, Field: t.field, Expected: want, Key: keys[t][k]})
+			}
+		}
+		sort.Slice(missing, func(i, j int) bool { return fmt.Sprint(missing[i].Key) < fmt.Sprint(missing[j].Key) })
+		res = append(res, missing...)
+	}
+	return res, nil
+}
+
+// LedgerFix sets expected values of the differences in tx
+func LedgerFix(diffs []LedgerDiff, tx *Tx) error {
+	for _, d := range diffs {
+		if d.Id == 0 {
+			if d.Entity != "wmc_number" || len(d.Key) != 3 {
+				return fmt.Errorf("can't create %s for %v", d.Entity, d.Key)
+			}
+			_, err := WmcNumberCreate(WmcNumber{
+				WhsId:       d.Key[0],
+				MatherialId: d.Key[1],
+				ColorId:     d.Key[2],
+				Total:       d.Expected,
+				IsActive:    true,
+			}, tx)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		_, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s=?, version=version+1 WHERE id=?", d.Entity, d.Field),
+			d.Expected, d.Id)
+		if err != nil {
+			return err
+		}
+		err = Audit(tx, d.Entity, d.Id, "ledger_fix",
+			map[string]float64{d.Field: d.Actual}, map[string]float64{d.Field: d.Expected})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ledgerApplyDelta adds the change of expected totals from before to after,
+// so totals follow documents changed in tx without touching the other drift,
+// returns the number of changed totals
+func ledgerApplyDelta(before, after ledgerState, action string, tx *Tx) (int, error) {
+	changed := 0
+	for _, t := range after.targets {
+		deltas := map[string]float64{}
+		for k, v := range after.expected[t] {
+			deltas[k] += v
+		}
+		for k, v := range before.expected[t] {
+			deltas[k] -= v
+		}
+		keyFields := after.keyFields[t]
+		for k, delta := range deltas {
+			if math.Abs(delta) <= ledgerTolerance {
+				continue
+			}
+			key := after.keys[t][k]
+			if key == nil {
+				key = before.keys[t][k]
+			}
+			var id int
+			var actual float64
+			var err error
+			if len(keyFields) == 0 {
+				id = key[0]
+				err = tx.QueryRow(fmt.Sprintf("SELECT %s FROM %s WHERE id=?", t.field, t.table), id).Scan(&actual)
+			} else {
+				conds := []string{}
+				args := []interface{}{}
+				for i, f := range keyFields {
+					conds = append(conds, f+"=?")
+					args = append(args, key[i])
+				}
+				err = tx.QueryRow(fmt.Sprintf("SELECT id, %s FROM %s WHERE %s ORDER BY id LIMIT 1",
+					t.field, t.table, strings.Join(conds, " AND ")), args...).Scan(&id, &actual)
+				if err == sql.ErrNoRows {
+					err = LedgerFix([]LedgerDiff{{Entity: t.table, Field: t.field, Expected: delta, Key: key}}, tx)
+					if err != nil {
+						return changed, err
+					}
+					changed++
+					continue
+				}
+			}
+			if err == sql.ErrNoRows {
+				// registers skip not existing rows
+				continue
+			}
+			if err != nil {
+				return changed, err
+			}
+			_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET %s=%s+?, version=version+1 WHERE id=?", t.table, t.field, t.field),
+				delta, id)
+			if err != nil {
+				return changed, err
+			}
+			err = Audit(tx, t.table, id, action,
+				map[string]float64{t.field: actual}, map[string]float64{t.field: actual + delta})
+			if err != nil {
+				return changed, err
+			}
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// ledgerScope returns the registers changed by rows of the tables: registers of
+// the tables and of their items, then registers of their targets and so on,
+// as sums of documents are totals of their items and values of other registers
+func ledgerScope(tables []string) []LedgerRegister {
+	changed := map[string]bool{}
+	for _, t := range tables {
+		changed[t] = true
+	}
+	regs := []LedgerRegister{}
+	taken := make([]bool, len(ledgerRegisters))
+	for more := true; more; {
+		more = false
+		for i, reg := range ledgerRegisters {
+			if !taken[i] && (changed[reg.Source] || changed[reg.Parent]) {
+				taken[i] = true
+				regs = append(regs, reg)
+				changed[reg.Target] = true
+				more = true
+			}
+		}
+	}
+	return regs
+}
+
+// ledgerFollow applies the change of expected totals of the registers since before,
+// every pass follows one step of registers of targets, so the delta settles
+// in not more passes than registers unless they make a cycle
+func ledgerFollow(before ledgerState, regs []LedgerRegister, action string, tx *Tx) error {
+	for pass := 0; pass <= len(regs); pass++ {
+		after, err := ledgerExpected(regs, tx)
+		if err != nil {
+			return err
+		}
+		changed, err := ledgerApplyDelta(before, after, action, tx)
+		if err != nil || changed == 0 {
+			return err
+		}
+		before = after
+	}
+	return fmt.Errorf("ledger totals don't settle after %d passes", len(regs)+1)
+}
+
+// ledgerKeyed reports whether the table is the target of a keyed register (wmc_number)
+func ledgerKeyed(table string) bool {
+	for _, reg := range ledgerRegisters {
+		if reg.Target == table && len(reg.KeyFields) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Ledger handlers
+
+func GetLedgerCheck(r Req) {
+	tx, err := Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	defer tx.Rollback()
+	r.Respond(LedgerCheck(tx))
+}
+
+// FixLedger recomputes totals and writes the differences in one transaction
+func FixLedger(r Req) {
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	diffs, err := LedgerCheck(tx)
+	if err == nil {
+		err = LedgerFix(diffs, tx)
+	}
+	r.RespondTx(tx, diffs, err)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLedgerScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		tables  []string
+		targets []string
+	}{
+		{"catalog", []string{"measure"}, []string{}},
+		{"document", []string{"cash_in"}, []string{"cash", "contragent", "contact", "legal"}},
+		{"item and its document", []string{"item_to_invoice"},
+			[]string{"invoice", "contragent", "contact", "legal", "owner"}},
+	}
+	for _, tt := range tests {
+		targets := []string{}
+		seen := map[string]bool{}
+		for _, reg := range ledgerScope(tt.tables) {
+			if !seen[reg.Target] {
+				seen[reg.Target] = true
+				targets = append(targets, reg.Target)
+			}
+		}
+		want := map[string]bool{}
+		for _, target := range tt.targets {
+			want[target] = true
+		}
+		if !reflect.DeepEqual(seen, want) {
+			t.Errorf("%s: ledgerScope(%v) targets %v, want %v", tt.name, tt.tables, targets, tt.targets)
+		}
+	}
+}
+
+func TestLedgerCheckAndFix(t *testing.T) {
+	testBase(t)
+	cash, err := CashCreate(Cash{Name: "main", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sum := range []float64{100, 20.5} {
+		c, err := CashInCreate(CashIn{CashId: cash.Id, CashSum: sum, IsActive: true}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = CashInRealized(c.Id, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func() []LedgerDiff {
+		tx, err := Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		diffs, err := LedgerCheck(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diffs
+	}
+	if diffs := check(); len(diffs) != 0 {
+		t.Fatalf("registers leave differences %+v", diffs)
+	}
+
+	tests := []struct {
+		name  string
+		drift string
+		want  []LedgerDiff
+	}{
+		{"no drift", "", []LedgerDiff{}},
+		{"within tolerance", "UPDATE cash SET total=total+0.001", []LedgerDiff{}},
+		{"drift", "UPDATE cash SET total=total+5",
+			[]LedgerDiff{{Entity: "cash", Id: cash.Id, Field: "total", Actual: 125.501, Expected: 120.5}}},
+	}
+	for _, tt := range tests {
+		if tt.drift != "" {
+			if _, err = db.Exec(tt.drift); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if diffs := check(); !reflect.DeepEqual(diffs, tt.want) {
+			t.Errorf("%s: LedgerCheck = %+v, want %+v", tt.name, diffs, tt.want)
+		}
+	}
+
+	tx, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := LedgerCheck(tx)
+	if err == nil {
+		err = LedgerFix(diffs, tx)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := check(); len(diffs) != 0 {
+		t.Errorf("differences after fix %+v", diffs)
+	}
+	var n int
+	db.QueryRow("SELECT count(*) FROM audit_log WHERE entity='cash' AND action='ledger_fix'").Scan(&n)
+	if n != 1 {
+		t.Errorf("%d audit records of the fix, want 1", n)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query language of _get_all and w_..._get_all lists, all parameters are optional:
+//
+//	f=field:op:value  condition, may be repeated, conditions are joined with AND;
+//	                  op is eq, ne, lt, le, gt, ge, like (value with % or _),
+//	                  in (values separated by comma) or between (two values by comma)
+//	sort=field,-field sort order, "-" for descending
+//	limit=N&offset=M  page, the number of all found rows is in X-Total-Count header
+//
+// e.g. /w_ordering_get_all?f=ordering_status_id:in:1,2&f=created_at:between:2024-01-01,2024-02-01&sort=-created_at&limit=50
+// Fields are checked with TestForExistingField of the table, bool values are 1 and 0.
+// Secret columns can't be filtered or sorted, errors of the query are responded with 400.
+
+type ListCond struct {
+	Field  string
+	Op     string
+	Values []string
+}
+
+type ListSort struct {
+	Field string
+	Desc  bool
+}
+
+type ListQuery struct {
+	Conds       []ListCond
+	Sort        []ListSort
+	Limit       int
+	Offset      int
+	WithDeleted bool
+	DeletedOnly bool
+}
+
+// columns of secrets, lists are never filtered or sorted by them
+var listHidden = map[string]bool{
+	"password":       true,
+	"secret":         true,
+	"recovery_codes": true,
+	"token_hash":     true,
+}
+
+// ListQueryError is the invalid query of the list, Respond sends it with status 400
+type ListQueryError struct {
+	Msg string
+}
+
+func (e ListQueryError) Error() string {
+	return e.Msg
+}
+
+// ListField reports whether lists may be filtered and sorted by the field
+func ListField(field string, fieldExists func(string) bool) bool {
+	return !listHidden[field] && fieldExists(field)
+}
+
+var listOps = map[string]string{
+	"eq":   "=",
+	"ne":   "!=",
+	"lt":   "<",
+	"le":   "<=",
+	"gt":   ">",
+	"ge":   ">=",
+	"like": "LIKE",
+}
+
+// HasListQuery reports whether the request has any list query parameter
+func (r Req) HasListQuery() bool {
+	q := r.R.URL.Query()
+	for _, p := range []string{"f", "sort", "limit", "offset"} {
+		if q.Has(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListQuery parses list query parameters of the request
+func (r Req) ListQuery() (ListQuery, error) {
+	lq, err := ParseListQuery(r.R.URL.Query())
+	lq.WithDeleted = r.WithDeleted
+	lq.DeletedOnly = r.DeletedOnly
+	return lq, err
+}
+
+func ParseListQuery(q url.Values) (ListQuery, error) {
+	var lq ListQuery
+	for _, f := range q["f"] {
+		parts := strings.SplitN(f, ":", 3)
+		if len(parts) != 3 {
+			return lq, ListQueryError{"filter must be like field:op:value - " + f}
+		}
+		c := ListCond{Field: parts[0], Op: parts[1], Values: []string{parts[2]}}
+		switch c.Op {
+		case "in":
+			c.Values = strings.Split(parts[2], ",")
+		case "between":
+			c.Values = strings.Split(parts[2], ",")
+			if len(c.Values) != 2 {
+				return lq, ListQueryError{"between needs two values - " + f}
+			}
+		default:
+			if _, ok := listOps[c.Op]; !ok {
+				return lq, ListQueryError{"unknown filter operation " + c.Op}
+			}
+		}
+		lq.Conds = append(lq.Conds, c)
+	}
+	if s := q.Get("sort"); s != "" {
+		for _, field := range strings.Split(s, ",") {
+			desc := strings.HasPrefix(field, "-")
+			lq.Sort = append(lq.Sort, ListSort{strings.TrimPrefix(field, "-"), desc})
+		}
+	}
+	var err error
+	for name, p := range map[string]*int{"limit": &lq.Limit, "offset": &lq.Offset} {
+		if v := q.Get(name); v != "" {
+			*p, err = strconv.Atoi(v)
+			if err != nil || *p < 0 {
+				return lq, ListQueryError{"invalid integer parameter " + name}
+			}
+		}
+	}
+	return lq, nil
+}
+
+// Owned restricts the query to documents of the user
+func (lq ListQuery) Owned(userId int) ListQuery {
+	lq.Conds = append(lq.Conds[:len(lq.Conds):len(lq.Conds)], ListCond{"user_id", "eq", []string{strconv.Itoa(userId)}})
+	return lq
+}
+
+// OwnedIn restricts the query to rows of documents of the user, field refers
+// to the document table; the op can't come from ParseListQuery
+func (lq ListQuery) OwnedIn(field, docTable string, userId int) ListQuery {
+	lq.Conds = append(lq.Conds[:len(lq.Conds):len(lq.Conds)], ListCond{field, "owned", []string{docTable, strconv.Itoa(userId)}})
+	return lq
+}
+
+// Where returns WHERE clause with its arguments for the table
+func (lq ListQuery) Where(table string, fieldExists func(string) bool) (string, []interface{}, error) {
+	where := []string{}
+	args := []interface{}{}
+	if lq.DeletedOnly {
+		where = append(where, table+".is_active = 0")
+	} else if !lq.WithDeleted {
+		where = append(where, table+".is_active = 1")
+	}
+	for _, c := range lq.Conds {
+		if !ListField(c.Field, fieldExists) {
+			return "", nil, ListQueryError{"field not exist " + c.Field}
+		}
+		col := table + "." + c.Field
+		switch c.Op {
+		case "in":
+			where = append(where, col+" IN (?"+strings.Repeat(", ?", len(c.Values)-1)+")")
+		case "between":
+			where = append(where, col+" BETWEEN ? AND ?")
+		case "owned":
+			where = append(where, col+" IN (SELECT id FROM "+c.Values[0]+" WHERE user_id = ?)")
+			args = append(args, c.Values[1])
+			continue
+		default:
+			where = append(where, col+" "+listOps[c.Op]+" ?")
+		}
+		for _, v := range c.Values {
+			args = append(args, v)
+		}
+	}
+	if len(where) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(where, " AND "), args, nil
+}
+
+// Tail returns ORDER BY and LIMIT clauses for the table,
+// id is always the last sort field, so pages are stable
+func (lq ListQuery) Tail(table string, fieldExists func(string) bool) (string, error) {
+	order := []string{}
+	for _, s := range lq.Sort {
+		if !ListField(s.Field, fieldExists) {
+			return "", ListQueryError{"field not exist " + s.Field}
+		}
+		if s.Field == "id" {
+			continue
+		}
+		if s.Desc {
+			order = append(order, table+"."+s.Field+" DESC")
+		} else {
+			order = append(order, table+"."+s.Field)
+		}
+	}
+	idOrder := table + ".id"
+	for _, s := range lq.Sort {
+		if s.Field == "id" && s.Desc {
+			idOrder += " DESC"
+		}
+	}
+	res := " ORDER BY " + strings.Join(append(order, idOrder), ", ")
+	if lq.Limit > 0 {
+		res += " LIMIT " + strconv.Itoa(lq.Limit)
+	}
+	if lq.Offset > 0 {
+		if lq.Limit == 0 {
+			res += " LIMIT -1"
+		}
+		res += " OFFSET " + strconv.Itoa(lq.Offset)
+	}
+	return res, nil
+}
+
+// RespondList responds a page of the list of the table with the number of all found rows in X-Total-Count
+func (r Req) RespondList(table string, payload interface{}, total int, err error) {
+	if err == nil {
+		r.W.Header().Set("X-Total-Count", strconv.Itoa(total))
+	}
+	r.RespondRows(table, payload, err)
+}
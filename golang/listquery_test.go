@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  ListQuery
+		err   bool
+	}{
+		{"f=name:like:a%25&sort=-created_at,id&limit=10&offset=20", ListQuery{
+			Conds:  []ListCond{{"name", "like", []string{"a%"}}},
+			Sort:   []ListSort{{"created_at", true}, {"id", false}},
+			Limit:  10,
+			Offset: 20,
+		}, false},
+		{"f=id:in:1,2,3&f=created_at:between:2024-01-01,2024-02-01", ListQuery{
+			Conds: []ListCond{{"id", "in", []string{"1", "2", "3"}}, {"created_at", "between", []string{"2024-01-01", "2024-02-01"}}},
+		}, false},
+		{"f=name", ListQuery{}, true},
+		{"f=name:regexp:a", ListQuery{}, true},
+		{"f=user_id:owned:order", ListQuery{}, true},
+		{"f=created_at:between:2024-01-01", ListQuery{}, true},
+		{"limit=-1", ListQuery{}, true},
+		{"offset=x", ListQuery{}, true},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		lq, err := ParseListQuery(q)
+		if tt.err {
+			if _, ok := err.(ListQueryError); !ok {
+				t.Errorf("%s: error %v, want ListQueryError", tt.query, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(lq, tt.want) {
+			t.Errorf("%s: %+v %v, want %+v", tt.query, lq, err, tt.want)
+		}
+	}
+}
+
+func TestListQueryWhere(t *testing.T) {
+	lq := ListQuery{Conds: []ListCond{{"name", "like", []string{"a%"}}, {"id", "in", []string{"1", "2"}}}}
+	where, args, err := lq.Owned(5).Where("ordering", OrderingTestForExistingField)
+	want := " WHERE ordering.is_active = 1 AND ordering.name LIKE ? AND ordering.id IN (?, ?) AND ordering.user_id = ?"
+	if err != nil || where != want || !reflect.DeepEqual(args, []interface{}{"a%", "1", "2", "5"}) {
+		t.Errorf("where %q %v %v", where, args, err)
+	}
+	for _, lq := range []ListQuery{
+		{Conds: []ListCond{{"nothing", "eq", []string{"1"}}}},
+		{Conds: []ListCond{{"password", "like", []string{"$2a$%"}}}},
+	} {
+		if _, _, err := lq.Where("user", UserTestForExistingField); err == nil {
+			t.Errorf("%+v is accepted", lq.Conds)
+		}
+	}
+	if _, err := (ListQuery{Sort: []ListSort{{"password", false}}}).Tail("user", UserTestForExistingField); err == nil {
+		t.Error("sort by password is accepted")
+	}
+}
+
+func TestListSecretColumns(t *testing.T) {
+	testBase(t)
+	c := testLogin(t, "admin", USER_READ|LOGOUT, 0)
+	tests := []struct {
+		url  string
+		code int
+	}{
+		{"/user_get_all?f=login:eq:admin&limit=1", http.StatusOK},
+		{"/user_get_all?f=password:like:pa%25&limit=1", http.StatusBadRequest},
+		{"/user_get_all?sort=password", http.StatusBadRequest},
+		{"/w_user_get_all?f=password:eq:pass", http.StatusBadRequest},
+		{"/user_filter_str/password/pass", http.StatusBadRequest},
+		{"/w_user_filter_str/password/pass", http.StatusBadRequest},
+		{"/user_filter_str/login/admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := c.do("GET", tt.url, ""); w.Code != tt.code {
+			t.Errorf("%s: %d %s, want %d", tt.url, w.Code, w.Body.String(), tt.code)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -30,6 +31,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/measure/{id:[0-9]+}",
 		WrapAuth(DeleteMeasure, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/measure/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMeasure, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/measure_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMeasureByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -51,6 +55,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/count_type/{id:[0-9]+}",
 		WrapAuth(DeleteCountType, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/count_type/{id:[0-9]+}/restore",
+		WrapAuth(RestoreCountType, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/count_type_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetCountTypeByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -72,6 +79,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/color_group/{id:[0-9]+}",
 		WrapAuth(DeleteColorGroup, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/color_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreColorGroup, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/color_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetColorGroupByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -93,6 +103,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/color/{id:[0-9]+}",
 		WrapAuth(DeleteColor, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/color/{id:[0-9]+}/restore",
+		WrapAuth(RestoreColor, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/color_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetColorByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -114,6 +127,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_group/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialGroup, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialGroup, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialGroupByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -135,6 +151,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial/{id:[0-9]+}",
 		WrapAuth(DeleteMatherial, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherial, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -156,6 +175,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/cash/{id:[0-9]+}",
 		WrapAuth(DeleteCash, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/cash/{id:[0-9]+}/restore",
+		WrapAuth(RestoreCash, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/cash_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetCashByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -177,6 +199,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/user_group/{id:[0-9]+}",
 		WrapAuth(DeleteUserGroup, USER_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/user_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreUserGroup, USER_DELETE)).Methods("POST")
+
 	r.HandleFunc("/user_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetUserGroupByFilterInt, USER_READ)).Methods("GET")
 
@@ -198,6 +223,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/user/{id:[0-9]+}",
 		WrapAuth(DeleteUser, USER_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/user/{id:[0-9]+}/restore",
+		WrapAuth(RestoreUser, USER_DELETE)).Methods("POST")
+
 	r.HandleFunc("/user_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetUserByFilterInt, USER_READ)).Methods("GET")
 
@@ -219,6 +247,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/equipment_group/{id:[0-9]+}",
 		WrapAuth(DeleteEquipmentGroup, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/equipment_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreEquipmentGroup, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/equipment_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetEquipmentGroupByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -240,6 +271,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/equipment/{id:[0-9]+}",
 		WrapAuth(DeleteEquipment, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/equipment/{id:[0-9]+}/restore",
+		WrapAuth(RestoreEquipment, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/equipment_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetEquipmentByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -261,6 +295,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/operation_group/{id:[0-9]+}",
 		WrapAuth(DeleteOperationGroup, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/operation_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOperationGroup, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/operation_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOperationGroupByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -282,6 +319,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/operation/{id:[0-9]+}",
 		WrapAuth(DeleteOperation, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/operation/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOperation, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/operation_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOperationByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -303,6 +343,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/product_group/{id:[0-9]+}",
 		WrapAuth(DeleteProductGroup, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/product_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProductGroup, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/product_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProductGroupByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -324,6 +367,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/product/{id:[0-9]+}",
 		WrapAuth(DeleteProduct, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/product/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProduct, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/product_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProductByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -345,6 +391,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/contragent_group/{id:[0-9]+}",
 		WrapAuth(DeleteContragentGroup, CONTRAGENT_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/contragent_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreContragentGroup, CONTRAGENT_DELETE)).Methods("POST")
+
 	r.HandleFunc("/contragent_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetContragentGroupByFilterInt, CONTRAGENT_READ)).Methods("GET")
 
@@ -366,6 +415,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/contragent/{id:[0-9]+}",
 		WrapAuth(DeleteContragent, CONTRAGENT_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/contragent/{id:[0-9]+}/restore",
+		WrapAuth(RestoreContragent, CONTRAGENT_DELETE)).Methods("POST")
+
 	r.HandleFunc("/contragent_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetContragentByFilterInt, CONTRAGENT_READ)).Methods("GET")
 
@@ -390,6 +442,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/legal/{id:[0-9]+}",
 		WrapAuth(DeleteLegal, CONTRAGENT_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/legal/{id:[0-9]+}/restore",
+		WrapAuth(RestoreLegal, CONTRAGENT_DELETE)).Methods("POST")
+
 	r.HandleFunc("/legal_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetLegalByFilterInt, CONTRAGENT_READ)).Methods("GET")
 
@@ -411,6 +466,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/contact/{id:[0-9]+}",
 		WrapAuth(DeleteContact, CONTRAGENT_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/contact/{id:[0-9]+}/restore",
+		WrapAuth(RestoreContact, CONTRAGENT_DELETE)).Methods("POST")
+
 	r.HandleFunc("/contact_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetContactByFilterInt, CONTRAGENT_READ)).Methods("GET")
 
@@ -432,6 +490,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/ordering_status/{id:[0-9]+}",
 		WrapAuth(DeleteOrderingStatus, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/ordering_status/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOrderingStatus, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/ordering_status_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOrderingStatusByFilterInt, DOC_READ)).Methods("GET")
 
@@ -453,6 +514,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/ordering_state/{id:[0-9]+}",
 		WrapAuth(DeleteOrderingState, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/ordering_state/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOrderingState, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/ordering_state_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOrderingStateByFilterInt, DOC_READ)).Methods("GET")
 
@@ -472,11 +536,14 @@ func makeRouter() *mux.Router {
 		WrapAuth(UpdateOrdering, DOC_UPDATE)).Methods("PUT")
 
 	r.HandleFunc("/unrealize/ordering/{id:[0-9]+}",
-		WrapAuth(UnRealizeOrdering, DOC_DELETE)).Methods("GET")
+		WrapAuthAdd(UnRealizeOrdering, DOC_DELETE, ADD_UNREALIZE)).Methods("GET")
 
 	r.HandleFunc("/ordering/{id:[0-9]+}",
 		WrapAuth(DeleteOrdering, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/ordering/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOrdering, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/ordering_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOrderingByFilterInt, DOC_READ)).Methods("GET")
 
@@ -513,6 +580,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/owner/{id:[0-9]+}",
 		WrapAuth(DeleteOwner, OWNER_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/owner/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOwner, OWNER_DELETE)).Methods("POST")
+
 	r.HandleFunc("/owner_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOwnerByFilterInt, OWNER_READ)).Methods("GET")
 
@@ -532,11 +602,14 @@ func makeRouter() *mux.Router {
 		WrapAuth(UpdateInvoice, DOC_UPDATE)).Methods("PUT")
 
 	r.HandleFunc("/unrealize/invoice/{id:[0-9]+}",
-		WrapAuth(UnRealizeInvoice, DOC_DELETE)).Methods("GET")
+		WrapAuthAdd(UnRealizeInvoice, DOC_DELETE, ADD_UNREALIZE)).Methods("GET")
 
 	r.HandleFunc("/invoice/{id:[0-9]+}",
 		WrapAuth(DeleteInvoice, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/invoice/{id:[0-9]+}/restore",
+		WrapAuth(RestoreInvoice, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/invoice_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetInvoiceByFilterInt, DOC_READ)).Methods("GET")
 
@@ -570,6 +643,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/item_to_invoice/{id:[0-9]+}",
 		WrapAuth(DeleteItemToInvoice, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/item_to_invoice/{id:[0-9]+}/restore",
+		WrapAuth(RestoreItemToInvoice, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/item_to_invoice_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetItemToInvoiceByFilterInt, DOC_READ)).Methods("GET")
 
@@ -597,6 +673,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/product_to_ordering_status/{id:[0-9]+}",
 		WrapAuth(DeleteProductToOrderingStatus, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/product_to_ordering_status/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProductToOrderingStatus, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/product_to_ordering_status_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProductToOrderingStatusByFilterInt, DOC_READ)).Methods("GET")
 
@@ -618,6 +697,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/product_to_ordering/{id:[0-9]+}",
 		WrapAuth(DeleteProductToOrdering, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/product_to_ordering/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProductToOrdering, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/product_to_ordering_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProductToOrderingByFilterInt, DOC_READ)).Methods("GET")
 
@@ -645,6 +727,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_to_ordering/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialToOrdering, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_to_ordering/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialToOrdering, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_to_ordering_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialToOrderingByFilterInt, DOC_READ)).Methods("GET")
 
@@ -672,6 +757,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_to_product/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialToProduct, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_to_product/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialToProduct, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_to_product_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialToProductByFilterInt, DOC_READ)).Methods("GET")
 
@@ -699,6 +787,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/operation_to_ordering/{id:[0-9]+}",
 		WrapAuth(DeleteOperationToOrdering, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/operation_to_ordering/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOperationToOrdering, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/operation_to_ordering_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOperationToOrderingByFilterInt, DOC_READ)).Methods("GET")
 
@@ -726,6 +817,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/operation_to_product/{id:[0-9]+}",
 		WrapAuth(DeleteOperationToProduct, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/operation_to_product/{id:[0-9]+}/restore",
+		WrapAuth(RestoreOperationToProduct, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/operation_to_product_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetOperationToProductByFilterInt, DOC_READ)).Methods("GET")
 
@@ -753,6 +847,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/product_to_product/{id:[0-9]+}",
 		WrapAuth(DeleteProductToProduct, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/product_to_product/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProductToProduct, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/product_to_product_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProductToProductByFilterInt, DOC_READ)).Methods("GET")
 
@@ -780,6 +877,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/cbox_check/{id:[0-9]+}",
 		WrapAuth(DeleteCboxCheck, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/cbox_check/{id:[0-9]+}/restore",
+		WrapAuth(RestoreCboxCheck, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/cbox_check_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetCboxCheckByFilterInt, DOC_READ)).Methods("GET")
 
@@ -804,6 +904,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/item_to_cbox_check/{id:[0-9]+}",
 		WrapAuth(DeleteItemToCboxCheck, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/item_to_cbox_check/{id:[0-9]+}/restore",
+		WrapAuth(RestoreItemToCboxCheck, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/item_to_cbox_check_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetItemToCboxCheckByFilterInt, DOC_READ)).Methods("GET")
 
@@ -829,11 +932,14 @@ func makeRouter() *mux.Router {
 		WrapAuth(UpdateCashIn, DOC_UPDATE)).Methods("PUT")
 
 	r.HandleFunc("/unrealize/cash_in/{id:[0-9]+}",
-		WrapAuth(UnRealizeCashIn, DOC_DELETE)).Methods("GET")
+		WrapAuthAdd(UnRealizeCashIn, DOC_DELETE, ADD_UNREALIZE)).Methods("GET")
 
 	r.HandleFunc("/cash_in/{id:[0-9]+}",
 		WrapAuth(DeleteCashIn, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/cash_in/{id:[0-9]+}/restore",
+		WrapAuth(RestoreCashIn, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/cash_in_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetCashInByFilterInt, DOC_READ)).Methods("GET")
 
@@ -865,11 +971,14 @@ func makeRouter() *mux.Router {
 		WrapAuth(UpdateCashOut, DOC_UPDATE)).Methods("PUT")
 
 	r.HandleFunc("/unrealize/cash_out/{id:[0-9]+}",
-		WrapAuth(UnRealizeCashOut, DOC_DELETE)).Methods("GET")
+		WrapAuthAdd(UnRealizeCashOut, DOC_DELETE, ADD_UNREALIZE)).Methods("GET")
 
 	r.HandleFunc("/cash_out/{id:[0-9]+}",
 		WrapAuth(DeleteCashOut, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/cash_out/{id:[0-9]+}/restore",
+		WrapAuth(RestoreCashOut, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/cash_out_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetCashOutByFilterInt, DOC_READ)).Methods("GET")
 
@@ -903,6 +1012,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/whs/{id:[0-9]+}",
 		WrapAuth(DeleteWhs, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/whs/{id:[0-9]+}/restore",
+		WrapAuth(RestoreWhs, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/whs_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetWhsByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -922,11 +1034,14 @@ func makeRouter() *mux.Router {
 		WrapAuth(UpdateWhsIn, DOC_UPDATE)).Methods("PUT")
 
 	r.HandleFunc("/unrealize/whs_in/{id:[0-9]+}",
-		WrapAuth(UnRealizeWhsIn, DOC_DELETE)).Methods("GET")
+		WrapAuthAdd(UnRealizeWhsIn, DOC_DELETE, ADD_UNREALIZE)).Methods("GET")
 
 	r.HandleFunc("/whs_in/{id:[0-9]+}",
 		WrapAuth(DeleteWhsIn, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/whs_in/{id:[0-9]+}/restore",
+		WrapAuth(RestoreWhsIn, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/whs_in_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetWhsInByFilterInt, DOC_READ)).Methods("GET")
 
@@ -961,11 +1076,14 @@ func makeRouter() *mux.Router {
 		WrapAuth(UpdateWhsOut, DOC_UPDATE)).Methods("PUT")
 
 	r.HandleFunc("/unrealize/whs_out/{id:[0-9]+}",
-		WrapAuth(UnRealizeWhsOut, DOC_DELETE)).Methods("GET")
+		WrapAuthAdd(UnRealizeWhsOut, DOC_DELETE, ADD_UNREALIZE)).Methods("GET")
 
 	r.HandleFunc("/whs_out/{id:[0-9]+}",
 		WrapAuth(DeleteWhsOut, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/whs_out/{id:[0-9]+}/restore",
+		WrapAuth(RestoreWhsOut, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/whs_out_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetWhsOutByFilterInt, DOC_READ)).Methods("GET")
 
@@ -999,6 +1117,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_to_whs_in/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialToWhsIn, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_to_whs_in/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialToWhsIn, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_to_whs_in_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialToWhsInByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1020,6 +1141,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_to_whs_out/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialToWhsOut, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_to_whs_out/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialToWhsOut, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_to_whs_out_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialToWhsOutByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1041,6 +1165,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_part/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialPart, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_part/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialPart, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_part_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialPartByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1065,6 +1192,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/matherial_part_slice/{id:[0-9]+}",
 		WrapAuth(DeleteMatherialPartSlice, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/matherial_part_slice/{id:[0-9]+}/restore",
+		WrapAuth(RestoreMatherialPartSlice, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/matherial_part_slice_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetMatherialPartSliceByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1089,6 +1219,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/project_group/{id:[0-9]+}",
 		WrapAuth(DeleteProjectGroup, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/project_group/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProjectGroup, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/project_group_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProjectGroupByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1110,6 +1243,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/project_status/{id:[0-9]+}",
 		WrapAuth(DeleteProjectStatus, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/project_status/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProjectStatus, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/project_status_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProjectStatusByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1131,6 +1267,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/project_type/{id:[0-9]+}",
 		WrapAuth(DeleteProjectType, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/project_type/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProjectType, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/project_type_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProjectTypeByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1152,6 +1291,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/project/{id:[0-9]+}",
 		WrapAuth(DeleteProject, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/project/{id:[0-9]+}/restore",
+		WrapAuth(RestoreProject, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/project_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetProjectByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1179,6 +1321,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/counter/{id:[0-9]+}",
 		WrapAuth(DeleteCounter, CATALOG_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/counter/{id:[0-9]+}/restore",
+		WrapAuth(RestoreCounter, CATALOG_DELETE)).Methods("POST")
+
 	r.HandleFunc("/counter_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetCounterByFilterInt, CATALOG_READ)).Methods("GET")
 
@@ -1227,6 +1372,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/wmc_number/{id:[0-9]+}",
 		WrapAuth(DeleteWmcNumber, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/wmc_number/{id:[0-9]+}/restore",
+		WrapAuth(RestoreWmcNumber, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/wmc_number_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetWmcNumberByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1248,6 +1396,9 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/numbers_to_product/{id:[0-9]+}",
 		WrapAuth(DeleteNumbersToProduct, DOC_DELETE)).Methods("DELETE")
 
+	r.HandleFunc("/numbers_to_product/{id:[0-9]+}/restore",
+		WrapAuth(RestoreNumbersToProduct, DOC_DELETE)).Methods("POST")
+
 	r.HandleFunc("/numbers_to_product_filter_int/{fs}/{id:[0-9]+}",
 		WrapAuth(GetNumbersToProductByFilterInt, DOC_READ)).Methods("GET")
 
@@ -1949,20 +2100,66 @@ func makeRouter() *mux.Router {
 	r.HandleFunc("/copy_base/{fs}", WrapAuth(CopyBase, ADMIN)).Methods("GET")
 	r.HandleFunc("/delete_base/{fs}", WrapAuth(DeleteBackupBase, ADMIN)).Methods("GET")
 	r.HandleFunc("/get_bases", WrapAuth(GetBackupBases, ADMIN)).Methods("GET")
-	r.HandleFunc("/restore_base/{fs}", WrapAuth(RestoreBaseFromBackup, ADMIN)).Methods("GET")
+	r.HandleFunc("/restore_base/{fs}", WrapAuthAdd(RestoreBaseFromBackup, ADMIN, ADD_RESTORE_BASE)).Methods("GET")
 	r.HandleFunc("/ws", WrapAuth(UpgradeWS, WS_CONNECT)).Methods("GET")
+	r.HandleFunc("/revoke_sessions/{id:[0-9]+}", WrapAuth(RevokeSessions, ADMIN)).Methods("GET")
+	r.HandleFunc("/session_get_all", WrapAuth(GetSessionAll, ADMIN)).Methods("GET")
+	r.HandleFunc("/session/{id:[0-9]+}", WrapAuth(TerminateSession, ADMIN)).Methods("DELETE")
+	r.HandleFunc("/access_names", WrapAuth(GetAccessNames, LOGOUT)).Methods("GET")
+	r.HandleFunc("/search", WrapAuth(GetSearch, LOGOUT)).Methods("GET")
+	r.HandleFunc("/api_token", WrapAuth(CreateApiToken, ADMIN)).Methods("POST")
+	r.HandleFunc("/api_token_get_all", WrapAuth(GetApiTokenAll, ADMIN)).Methods("GET")
+	r.HandleFunc("/api_token/{id:[0-9]+}", WrapAuth(RevokeApiToken, ADMIN)).Methods("DELETE")
+	r.HandleFunc("/login_lock_get_all", WrapAuth(GetLoginLockAll, ADMIN)).Methods("GET")
+	r.HandleFunc("/login_lock/{id:[0-9]+}", WrapAuth(DeleteLoginLock, ADMIN)).Methods("DELETE")
+	r.HandleFunc("/login_attempt_between_created_at/{fs}/{fs2}", WrapAuth(GetLoginAttemptBetweenCreatedAt, ADMIN)).Methods("GET")
+	r.HandleFunc("/audit_log", WrapAuth(GetAuditLog, ADMIN)).Methods("GET")
+	r.HandleFunc("/ledger_check", WrapAuth(GetLedgerCheck, ADMIN)).Methods("GET")
+	r.HandleFunc("/ledger_fix", WrapAuth(FixLedger, ADMIN)).Methods("POST")
+	r.HandleFunc("/balance/{fs}", WrapAuth(GetBalance, DOC_READ)).Methods("GET")
+	r.HandleFunc("/movement/{fs}", WrapAuth(GetMovements, DOC_READ)).Methods("GET")
+	r.HandleFunc("/document_tree/{fs}/{id:[0-9]+}", WrapAuth(GetDocumentTree, DOC_READ)).Methods("GET")
+	r.HandleFunc("/period_close_get_all", WrapAuth(GetPeriodCloseAll, DOC_READ)).Methods("GET")
+	r.HandleFunc("/period_close", WrapAuth(SetPeriodClose, ADMIN)).Methods("POST")
+	r.HandleFunc("/purge", WrapAuth(PurgeDeleted, ADMIN)).Methods("POST")
+	r.HandleFunc("/doc_sequence_get_all", WrapAuth(GetDocSequenceAll, DOC_READ)).Methods("GET")
+	r.HandleFunc("/doc_sequence", WrapAuth(SetDocSequence, ADMIN)).Methods("POST")
+	r.HandleFunc("/doc_sequence_next/{fs}", WrapAuth(GetDocSequenceNext, DOC_READ)).Methods("GET")
+	r.HandleFunc("/ordering_transition_get_all", WrapAuth(GetOrderingTransitionAll, DOC_READ)).Methods("GET")
+	r.HandleFunc("/ordering_transition", WrapAuth(SetOrderingTransition, ADMIN)).Methods("POST")
+	r.HandleFunc("/ordering_transition/{id:[0-9]+}", WrapAuth(DeleteOrderingTransition, ADMIN)).Methods("DELETE")
+	r.HandleFunc("/ordering/{id:[0-9]+}/transition/{id2:[0-9]+}", WrapAuth(CheckOrderingTransition, DOC_READ)).Methods("GET")
+	r.HandleFunc("/ordering/{id:[0-9]+}/transition/{id2:[0-9]+}", WrapAuth(TransitOrdering, DOC_UPDATE)).Methods("POST")
+	r.HandleFunc("/ordering/{id:[0-9]+}/make_invoice", WrapAuth(MakeOrderingInvoice, DOC_CREATE)).Methods("POST")
+	r.HandleFunc("/ordering/{id:[0-9]+}/pdf", WrapAuth(GetOrderingPdf, DOC_READ)).Methods("GET")
+	r.HandleFunc("/invoice/{id:[0-9]+}/pdf", WrapAuth(GetInvoicePdf, DOC_READ)).Methods("GET")
+	r.HandleFunc("/whs_out/{id:[0-9]+}/pdf", WrapAuth(GetWhsOutPdf, DOC_READ)).Methods("GET")
+	r.HandleFunc("/contragent/{id:[0-9]+}/reconciliation/pdf", WrapAuth(GetReconciliationPdf, DOC_READ)).Methods("GET")
+	r.HandleFunc("/login_totp", WrapAuth(LoginTotp, LOGIN)).Methods("POST")
+	r.HandleFunc("/totp_status", WrapAuth(GetTotpStatus, LOGOUT)).Methods("GET")
+	r.HandleFunc("/totp_enroll", WrapAuth(EnrollTotp, LOGOUT)).Methods("POST")
+	r.HandleFunc("/totp_confirm", WrapAuth(ConfirmTotp, LOGOUT)).Methods("POST")
+	r.HandleFunc("/totp_disable", WrapAuth(DisableTotp, LOGOUT)).Methods("POST")
+	r.HandleFunc("/user_totp/{id:[0-9]+}", WrapAuth(ResetUserTotp, ADMIN)).Methods("DELETE")
 
 	return r
 }
 
 type Config struct {
-	Port          string   `json:"port"`
-	BckpPath      string   `json:"bckp_path"`
-	MaketsPath    string   `json:"makets_path"`
-	OldMaketsPath string   `json:"old_makets_path"`
-	NewMaketsPath string   `json:"new_makets_path"`
-	MaketDirs     []string `json:"maket_dirs"`
-	DBFile        string   `json:"db_file"`
+	Port          string        `json:"port"`
+	BckpPath      string        `json:"bckp_path"`
+	MaketsPath    string        `json:"makets_path"`
+	OldMaketsPath string        `json:"old_makets_path"`
+	NewMaketsPath string        `json:"new_makets_path"`
+	MaketDirs     []string      `json:"maket_dirs"`
+	DBFile        string        `json:"db_file"`
+	SessionKeys   []SessionKey  `json:"session_keys"`
+	SessionStore  string        `json:"session_store"`
+	LoginThrottle LoginThrottle `json:"login_throttle"`
+	Totp          TotpConfig    `json:"totp"`
+	ManualMigrate bool          `json:"manual_migrate"`
+	MeasurePieces int           `json:"measure_pieces"`
+	Pdf           PdfConfig     `json:"pdf"`
 }
 
 var Cfg Config
@@ -1978,11 +2175,23 @@ func LoadConfig() error {
 
 func main() {
 
+	migrateFlag := flag.Bool("migrate", false, "migrate the base to the server schema version and exit")
+	flag.Parse()
+
 	if err := LoadConfig(); err != nil {
 		log.Fatal("Can`t load config file!")
 	}
 
-	err := DBconnect(Cfg.DBFile)
+	if *migrateFlag {
+		RunMigrations()
+	}
+
+	err := OpenBase(Cfg.DBFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = InitSessions()
 	if err != nil {
 		log.Fatal(err)
 	}
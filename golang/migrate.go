@@ -0,0 +1,315 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schema migrations: numbered steps built into the binary.
+// The applied versions are kept in schema_version, OpenBase applies
+// the missing ones (or refuses to work if config has "manual_migrate": true,
+// then run the server with -migrate) and refuses a base newer than the server.
+// A change of the schema is a new migration at the end of the list,
+// applied migrations must never change, so they keep their own SQL
+// instead of calling code of the server.
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const schemaVersionTable = `CREATE TABLE IF NOT EXISTS schema_version
+(
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TEXT NOT NULL
+);`
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+}
+
+var migrations = []Migration{
+	{1, "base", execFile("0001_base.sql")},
+	{2, "service tables", execFile("0002_service_tables.sql")},
+	{3, "session activity", addColumns("session", [][2]string{
+		{"login_at", "TEXT NOT NULL DEFAULT ''"},
+		{"last_seen_at", "TEXT NOT NULL DEFAULT ''"},
+		{"remote_addr", "TEXT NOT NULL DEFAULT ''"},
+		{"user_agent", "TEXT NOT NULL DEFAULT ''"},
+	})},
+	{4, "row version", addBaseColumn("version", "INT NOT NULL DEFAULT 1")},
+	{5, "movement journal", execFile("0005_movement_journal.sql")},
+	{6, "document links", execFile("0006_document_links.sql")},
+	{7, "period close", execFile("0007_period_close.sql")},
+	{8, "ordering transitions", execFile("0008_ordering_transitions.sql")},
+	{9, "document sequences", execFile("0009_document_sequences.sql")},
+	{10, "user group access", rebuildTable("user_group", "0010_user_group_access.sql")},
+	{11, "additional access", execSQL(addAccessSeed("user"), addAccessSeed("user_group"))},
+}
+
+func execSQL(queries ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, q := range queries {
+			_, err := tx.Exec(q)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func execFile(name string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		data, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(string(data))
+		return err
+	}
+}
+
+// addAccessSeed gives additional access bits to users and groups which could
+// do the guarded actions before the bits: unrealize to DOC_DELETE (32768),
+// prices to CATALOG_CREATE (131072) and CATALOG_UPDATE (262144),
+// all the bits (65536..1048576) to ADMIN (33554432)
+func addAccessSeed(table string) string {
+	return fmt.Sprintf(`UPDATE %[1]s SET add_access = add_access | 65536 WHERE base_access & (32768 | 33554432) != 0;
+UPDATE %[1]s SET add_access = add_access | 262144 WHERE base_access & (131072 | 262144) != 0;
+UPDATE %[1]s SET add_access = add_access | 2031616 WHERE base_access & 33554432 != 0;`, table)
+}
+
+// rebuildTable recreates the table by create of <table>_new in the file and copies
+// the columns both tables have. Queries of models select * and scan columns
+// in the order of models.json, so a new column in the middle of a table
+// needs the rebuild instead of ALTER TABLE ADD COLUMN
+func rebuildTable(table, name string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		err := execFile(name)(tx)
+		if err != nil {
+			return err
+		}
+		rows, err := tx.Query(`SELECT n.name FROM pragma_table_info(?) n
+			JOIN pragma_table_info(?) o ON o.name=n.name ORDER BY n.cid`, table+"_new", table)
+		if err != nil {
+			return err
+		}
+		columns := []string{}
+		for rows.Next() {
+			var c string
+			if err = rows.Scan(&c); err != nil {
+				rows.Close()
+				return err
+			}
+			columns = append(columns, c)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		list := strings.Join(columns, ", ")
+		return execSQL(
+			fmt.Sprintf("INSERT INTO %[1]s_new (%[2]s) SELECT %[2]s FROM %[1]s", table, list),
+			fmt.Sprintf("DROP TABLE %s", table),
+			fmt.Sprintf("ALTER TABLE %[1]s_new RENAME TO %[1]s", table),
+		)(tx)
+	}
+}
+
+// addColumns adds missing columns, bases made before migrations may have some of them
+func addColumns(table string, columns [][2]string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, c := range columns {
+			err := addColumn(tx, table, c[0], c[1])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addBaseColumn adds the column to every table of 0001_base.sql
+func addBaseColumn(column, def string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		data, err := migrationFiles.ReadFile("migrations/0001_base.sql")
+		if err != nil {
+			return err
+		}
+		for _, m := range regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\w+)`).FindAllStringSubmatch(string(data), -1) {
+			err = addColumn(tx, m[1], column, def)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumn adds the column to the table if it has no such column yet
+func addColumn(tx *sql.Tx, table, column, def string) error {
+	var n int
+	err := tx.QueryRow("SELECT count(*) FROM pragma_table_info(?) WHERE name=?", table, column).Scan(&n)
+	if err != nil || n > 0 {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
+	return err
+}
+
+// LatestSchemaVersion is the schema version the server works with
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the version of the connected base, 0 for a base without migrations
+func SchemaVersion() (int, error) {
+	return schemaVersion(db)
+}
+
+func schemaVersion(d *sql.DB) (int, error) {
+	var n int
+	err := d.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='schema_version'").Scan(&n)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	var v int
+	err = d.QueryRow("SELECT ifnull(max(version), 0) FROM schema_version").Scan(&v)
+	return v, err
+}
+
+// SchemaVersionOf returns the schema version of the base file without connecting to it
+func SchemaVersionOf(dbFile string) (int, error) {
+	if !fileExists(dbFile) {
+		return 0, fmt.Errorf("base %s does not exist", dbFile)
+	}
+	d, err := sql.Open("sqlite3", "file:"+dbFile+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	return schemaVersion(d)
+}
+
+func checkSchemaVersion(v int) error {
+	if v > LatestSchemaVersion() {
+		return fmt.Errorf("base schema version %d is newer than %d of the server - update the server",
+			v, LatestSchemaVersion())
+	}
+	return nil
+}
+
+// Migrate applies missing migrations, each one in its own transaction
+func Migrate() error {
+	_, err := db.Exec(schemaVersionTable)
+	if err != nil {
+		return err
+	}
+	v, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if err = checkSchemaVersion(v); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.Version <= v {
+			continue
+		}
+		log.Printf("applying migration %d %s", m.Version, m.Name)
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		err = m.Up(tx)
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES(?, ?, ?)",
+				m.Version, m.Name, time.Now().Format("2006-01-02T15:04:05"))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupBeforeMigrate copies the base with tables into bckp_path
+func backupBeforeMigrate(dbFile string, version int) error {
+	var n int
+	err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table'").Scan(&n)
+	if err != nil || n == 0 || Cfg.BckpPath == "" {
+		return err
+	}
+	name := fmt.Sprintf("before_migration_v%d_%s", version, time.Now().Format("2006-01-02T15-04-05"))
+	log.Print("backup of the base before migration: ", name)
+	return copyFile(dbFile, filepath.Join(Cfg.BckpPath, name))
+}
+
+// OpenBase connects to the base, brings its schema to the server version
+// and prepares the search index
+func OpenBase(dbFile string) error {
+	return openBase(dbFile, Cfg.ManualMigrate)
+}
+
+func openBase(dbFile string, manual bool) error {
+	err := DBconnect(dbFile)
+	if err != nil {
+		return err
+	}
+	v, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if err = checkSchemaVersion(v); err != nil {
+		return err
+	}
+	if v < LatestSchemaVersion() {
+		if manual {
+			return fmt.Errorf("base schema version %d, server needs %d - run the server with -migrate",
+				v, LatestSchemaVersion())
+		}
+		err = backupBeforeMigrate(dbFile, v)
+		if err != nil {
+			return err
+		}
+		err = Migrate()
+		if err != nil {
+			return err
+		}
+	}
+	return SearchInit()
+}
+
+// RunMigrations is the -migrate command: migrates the base from config and exits
+func RunMigrations() {
+	err := DBconnect(Cfg.DBFile)
+	if err == nil {
+		var v int
+		v, err = SchemaVersion()
+		if err == nil && v < LatestSchemaVersion() {
+			err = backupBeforeMigrate(Cfg.DBFile, v)
+		}
+	}
+	if err == nil {
+		err = Migrate()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Print("base schema version ", LatestSchemaVersion())
+	os.Exit(0)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrationsOrdered(t *testing.T) {
+	names := map[string]bool{}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %q has version %d at position %d", m.Name, m.Version, i+1)
+		}
+		if names[m.Name] {
+			t.Errorf("migration name %q is repeated", m.Name)
+		}
+		names[m.Name] = true
+	}
+	if LatestSchemaVersion() != len(migrations) {
+		t.Errorf("latest version %d of %d migrations", LatestSchemaVersion(), len(migrations))
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	latest := LatestSchemaVersion()
+	tests := []struct {
+		name    string
+		prepare string // the base before openBase
+		manual  bool
+		wantErr string
+	}{
+		{"new base", "", false, ""},
+		{"base before migrations", "base", false, ""},
+		{"migrated base", "migrated", false, ""},
+		{"manual migration", "base", true, "run the server with -migrate"},
+		{"newer base", "newer", false, "is newer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbFile := t.TempDir() + "/test.db"
+			if err := DBconnect(dbFile); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			switch tt.prepare {
+			case "base":
+				data, _ := migrationFiles.ReadFile("migrations/0001_base.sql")
+				_, err = db.Exec(string(data))
+			case "migrated":
+				err = Migrate()
+			case "newer":
+				if _, err = db.Exec(schemaVersionTable); err == nil {
+					_, err = db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES(?, 'future', '')", latest+1)
+				}
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			DbClose()
+			err = openBase(dbFile, tt.manual)
+			t.Cleanup(func() { DbClose() })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("openBase error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var n int
+			if err = db.QueryRow("SELECT count(*) FROM schema_version").Scan(&n); err != nil || n != latest {
+				t.Errorf("%d applied migrations, %v, want %d", n, err, latest)
+			}
+			if err = Migrate(); err != nil {
+				t.Errorf("second Migrate: %v", err)
+			}
+			if v, err := SchemaVersion(); err != nil || v != latest {
+				t.Errorf("version after second Migrate %d, %v, want %d", v, err, latest)
+			}
+			var cols int
+			db.QueryRow("SELECT count(*) FROM pragma_table_info('session') WHERE name='last_seen_at'").Scan(&cols)
+			if cols != 1 {
+				t.Error("session has no columns of migration 3")
+			}
+		})
+	}
+}
@@ -0,0 +1,644 @@
+-- schema of models.json tables when migrations were introduced,
+-- bases made by build/build.py before that are at this version
+CREATE TABLE IF NOT EXISTS measure
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	full_name TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS count_type
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS color_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	color_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS color
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	color_group_id INT NOT NULL,
+	name TEXT NOT NULL,
+	total REAL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	matherial_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	full_name TEXT NOT NULL,
+	matherial_group_id INT NOT NULL,
+	measure_id INT NOT NULL,
+	color_group_id INT NOT NULL,
+	price REAL NOT NULL,
+	cost REAL NOT NULL,
+	total REAL NOT NULL,
+	barcode TEXT NOT NULL,
+	count_type_id INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS cash
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	persent REAL NOT NULL,
+	total REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_fiscal BOOL NOT NULL,
+	is_account BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS user_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	user_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS user
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	full_name TEXT NOT NULL,
+	user_group_id INT NOT NULL,
+	cash_id INT NOT NULL,
+	phone TEXT NOT NULL,
+	email TEXT NOT NULL,
+	comm TEXT NOT NULL,
+	login TEXT NOT NULL,
+	password TEXT NOT NULL,
+	base_access INT NOT NULL,
+	add_access INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS equipment_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	equipment_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS equipment
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	full_name TEXT NOT NULL,
+	equipment_group_id INT NOT NULL,
+	cost REAL NOT NULL,
+	total REAL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS operation_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	operation_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS operation
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	full_name TEXT NOT NULL,
+	operation_group_id INT NOT NULL,
+	measure_id INT NOT NULL,
+	user_id INT NOT NULL,
+	price REAL NOT NULL,
+	cost REAL NOT NULL,
+	equipment_id INT NOT NULL,
+	equipment_price REAL NOT NULL,
+	barcode TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS product_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	product_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS product
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	short_name TEXT NOT NULL,
+	product_group_id INT NOT NULL,
+	measure_id INT NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	min_cost REAL NOT NULL,
+	cost REAL NOT NULL,
+	round_to REAL NOT NULL,
+	user_id INT NOT NULL,
+	barcode TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS contragent_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	contragent_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS contragent
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	contragent_group_id INT NOT NULL,
+	phone TEXT NOT NULL,
+	email TEXT NOT NULL,
+	web TEXT NOT NULL,
+	comm TEXT NOT NULL,
+	dir_name TEXT NOT NULL,
+	search TEXT NOT NULL,
+	total REAL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS legal
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	contragent_id INT NOT NULL,
+	name TEXT NOT NULL,
+	comm TEXT NOT NULL,
+	search TEXT NOT NULL,
+	total REAL NOT NULL,
+	full_name TEXT NOT NULL,
+	edrpou TEXT NOT NULL,
+	ipn TEXT NOT NULL,
+	iban TEXT NOT NULL,
+	bank TEXT NOT NULL,
+	mfo TEXT NOT NULL,
+	fop TEXT NOT NULL,
+	address TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS contact
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	contragent_id INT NOT NULL,
+	name TEXT NOT NULL,
+	phone TEXT NOT NULL,
+	email TEXT NOT NULL,
+	viber TEXT NOT NULL,
+	telegram TEXT NOT NULL,
+	telegram_uid INT NOT NULL,
+	search TEXT NOT NULL,
+	total REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ordering_status
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ordering_state
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ordering
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	deadline_at TEXT NOT NULL,
+	finished_at TEXT NOT NULL,
+	user_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	legal_id INT NOT NULL,
+	price REAL NOT NULL,
+	persent REAL NOT NULL,
+	profit REAL NOT NULL,
+	cost REAL NOT NULL,
+	info TEXT NOT NULL,
+	ordering_status_id INT NOT NULL,
+	ordering_state_id INT NOT NULL,
+	is_realized BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS owner
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	phone TEXT NOT NULL,
+	email TEXT NOT NULL,
+	web TEXT NOT NULL,
+	comm TEXT NOT NULL,
+	total REAL NOT NULL,
+	full_name TEXT NOT NULL,
+	edrpou TEXT NOT NULL,
+	ipn TEXT NOT NULL,
+	iban TEXT NOT NULL,
+	bank TEXT NOT NULL,
+	mfo TEXT NOT NULL,
+	fop TEXT NOT NULL,
+	address TEXT NOT NULL,
+	sign TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS invoice
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ordering_id INT NOT NULL,
+	based_on TEXT NOT NULL,
+	owner_id INT NOT NULL,
+	name TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	user_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	legal_id INT NOT NULL,
+	cash_sum REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_realized BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS item_to_invoice
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	invoice_id INT NOT NULL,
+	number REAL NOT NULL,
+	measure_id INT NOT NULL,
+	price REAL NOT NULL,
+	cost REAL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS product_to_ordering_status
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS product_to_ordering
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	ordering_id INT NOT NULL,
+	product_id INT NOT NULL,
+	user_id INT NOT NULL,
+	deadline_at TEXT NOT NULL,
+	product_to_ordering_status_id INT NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	pieces INT NOT NULL,
+	number REAL NOT NULL,
+	price REAL NOT NULL,
+	persent REAL NOT NULL,
+	profit REAL NOT NULL,
+	cost REAL NOT NULL,
+	info TEXT NOT NULL,
+	product_to_ordering_id INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_to_ordering
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ordering_id INT NOT NULL,
+	matherial_id INT NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	pieces INT NOT NULL,
+	color_id INT NOT NULL,
+	user_id INT NOT NULL,
+	number REAL NOT NULL,
+	price REAL NOT NULL,
+	persent REAL NOT NULL,
+	profit REAL NOT NULL,
+	cost REAL NOT NULL,
+	comm TEXT NOT NULL,
+	product_to_ordering_id INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_to_product
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INT NOT NULL,
+	matherial_id INT NOT NULL,
+	number REAL NOT NULL,
+	coeff REAL NOT NULL,
+	cost REAL NOT NULL,
+	list_name TEXT NOT NULL,
+	is_multiselect BOOL NOT NULL,
+	comm TEXT NOT NULL,
+	is_used BOOL NOT NULL,
+	ask_num BOOL NOT NULL,
+	add_to_price BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS operation_to_ordering
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ordering_id INT NOT NULL,
+	operation_id INT NOT NULL,
+	user_id INT NOT NULL,
+	number REAL NOT NULL,
+	price REAL NOT NULL,
+	user_sum REAL NOT NULL,
+	cost REAL NOT NULL,
+	equipment_id INT NOT NULL,
+	equipment_cost REAL NOT NULL,
+	comm TEXT NOT NULL,
+	product_to_ordering_id INT NOT NULL,
+	is_done BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS operation_to_product
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INT NOT NULL,
+	operation_id INT NOT NULL,
+	user_id INT NOT NULL,
+	number REAL NOT NULL,
+	coeff REAL NOT NULL,
+	cost REAL NOT NULL,
+	list_name TEXT NOT NULL,
+	is_multiselect BOOL NOT NULL,
+	equipment_id INT NOT NULL,
+	equipment_cost REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_used BOOL NOT NULL,
+	ask_num BOOL NOT NULL,
+	add_to_price BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS product_to_product
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INT NOT NULL,
+	product2_id INT NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	number REAL NOT NULL,
+	coeff REAL NOT NULL,
+	cost REAL NOT NULL,
+	list_name TEXT NOT NULL,
+	is_multiselect BOOL NOT NULL,
+	is_used BOOL NOT NULL,
+	ask_num BOOL NOT NULL,
+	add_to_price BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS cbox_check
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	fs_uid TEXT NOT NULL,
+	checkbox_uid TEXT NOT NULL,
+	user_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	ordering_id INT NOT NULL,
+	based_on TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	cash_sum REAL NOT NULL,
+	discount REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_cash BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS item_to_cbox_check
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	cbox_check_id INT NOT NULL,
+	number REAL NOT NULL,
+	measure_id INT NOT NULL,
+	price REAL NOT NULL,
+	discount REAL NOT NULL,
+	cost REAL NOT NULL,
+	item_code TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS cash_in
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	cash_id INT NOT NULL,
+	user_id INT NOT NULL,
+	based_on TEXT NOT NULL,
+	cbox_check_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	legal_id INT NOT NULL,
+	created_at TEXT NOT NULL,
+	cash_sum REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_realized BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS cash_out
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	cash_id INT NOT NULL,
+	user_id INT NOT NULL,
+	based_on TEXT NOT NULL,
+	cbox_check_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	legal_id INT NOT NULL,
+	created_at TEXT NOT NULL,
+	cash_sum REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_realized BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS whs
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	comm TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS whs_in
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	based_on TEXT NOT NULL,
+	whs_id INT NOT NULL,
+	user_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	legal_id INT NOT NULL,
+	contragent_doc_uid TEXT NOT NULL,
+	contragent_created_at TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	whs_sum REAL NOT NULL,
+	delivery REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_realized BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS whs_out
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	based_on TEXT NOT NULL,
+	whs_id INT NOT NULL,
+	user_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	legal_id INT NOT NULL,
+	created_at TEXT NOT NULL,
+	whs_sum REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_realized BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_to_whs_in
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	matherial_id INT NOT NULL,
+	contragent_mat_uid TEXT NOT NULL,
+	whs_in_id INT NOT NULL,
+	number REAL NOT NULL,
+	price REAL NOT NULL,
+	cost REAL NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	color_id INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_to_whs_out
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	matherial_id INT NOT NULL,
+	whs_out_id INT NOT NULL,
+	number REAL NOT NULL,
+	price REAL NOT NULL,
+	cost REAL NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	color_id INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_part
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	matherial_id INT NOT NULL,
+	part_uid INT NOT NULL,
+	number REAL NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	color_id INT NOT NULL,
+	user_id INT NOT NULL,
+	created_at TEXT NOT NULL,
+	is_recycle BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS matherial_part_slice
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	matherial_part_id INT NOT NULL,
+	user_id INT NOT NULL,
+	created_at TEXT NOT NULL,
+	number REAL NOT NULL,
+	width REAL NOT NULL,
+	length REAL NOT NULL,
+	comm TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project_group
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	project_group_id INT NOT NULL,
+	position INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project_status
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	code_name TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project_type
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	dir_name TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	project_group_id INT NOT NULL,
+	user_id INT NOT NULL,
+	contragent_id INT NOT NULL,
+	contact_id INT NOT NULL,
+	cost REAL NOT NULL,
+	cash_sum REAL NOT NULL,
+	whs_sum REAL NOT NULL,
+	project_type_id INT NOT NULL,
+	type_dir TEXT NOT NULL,
+	project_status_id INT NOT NULL,
+	number_dir TEXT NOT NULL,
+	info TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	is_in_work BOOL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS counter
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	equipment_id INT NOT NULL,
+	total INT NOT NULL,
+	updated_at TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS record_to_counter
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	counter_id INT NOT NULL,
+	created_at TEXT NOT NULL,
+	number INT NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS wmc_number
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	whs_id INT NOT NULL,
+	matherial_id INT NOT NULL,
+	color_id INT NOT NULL,
+	total REAL NOT NULL,
+	is_active BOOL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS numbers_to_product
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INT NOT NULL,
+	number REAL NOT NULL,
+	pieces INT NOT NULL,
+	size REAL NOT NULL,
+	persent REAL NOT NULL,
+	is_active BOOL NOT NULL
+);
@@ -0,0 +1,68 @@
+-- tables of sessions, api tokens, login throttling, two-factor codes
+-- and audit trail
+
+CREATE TABLE IF NOT EXISTS session
+(
+	id TEXT PRIMARY KEY,
+	user_id INT NOT NULL,
+	data BLOB NOT NULL,
+	created_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS api_token
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL,
+	name TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	scope INT NOT NULL,
+	add_scope INT NOT NULL,
+	created_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	last_used_at TEXT NOT NULL,
+	is_active BOOL NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS login_attempt
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	login TEXT NOT NULL,
+	remote_addr TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS login_lock
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,
+	key TEXT NOT NULL,
+	failures INT NOT NULL,
+	last_failure_at TEXT NOT NULL,
+	locked_until TEXT NOT NULL,
+	UNIQUE(kind, key)
+);
+
+CREATE TABLE IF NOT EXISTS user_totp
+(
+	user_id INTEGER PRIMARY KEY,
+	secret TEXT NOT NULL,
+	is_confirmed BOOL NOT NULL,
+	last_step INT NOT NULL,
+	recovery_codes TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS audit_log
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	batch_id TEXT NOT NULL,
+	user_id INT NOT NULL,
+	entity TEXT NOT NULL,
+	entity_id INT NOT NULL,
+	action TEXT NOT NULL,
+	diff TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_log_entity ON audit_log (entity, entity_id);
+CREATE INDEX IF NOT EXISTS audit_log_created_at ON audit_log (created_at);
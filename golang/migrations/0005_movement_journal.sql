@@ -0,0 +1,144 @@
+-- movement journal, movements of documents realized before it are written
+-- by the realize registers the journal was made with
+
+CREATE TABLE IF NOT EXISTS movement
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	doc_table TEXT NOT NULL,
+	doc_id INT NOT NULL,
+	source TEXT NOT NULL,
+	source_id INT NOT NULL,
+	entity TEXT NOT NULL,
+	entity_id INT NOT NULL,
+	field TEXT NOT NULL,
+	amount REAL NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS movement_entity ON movement (entity, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS movement_doc ON movement (doc_table, doc_id);
+
+-- invoice to contragent.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'invoice', s.id, 'invoice', s.id, 'contragent', s.contragent_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM invoice s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contragent);
+-- invoice to contact.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'invoice', s.id, 'invoice', s.id, 'contact', s.contact_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM invoice s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contact);
+-- invoice to legal.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'invoice', s.id, 'invoice', s.id, 'legal', s.legal_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM invoice s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM legal);
+-- invoice to owner.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'invoice', s.id, 'invoice', s.id, 'owner', s.owner_id AS entity_id, 'total', 1 * (s.cash_sum) AS amount, s.created_at
+	FROM invoice s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM owner);
+-- operation_to_ordering to equipment.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'ordering', p.id, 'operation_to_ordering', s.id, 'equipment', s.equipment_id AS entity_id, 'total', 1 * (s.equipment_cost) AS amount, p.created_at
+	FROM operation_to_ordering s JOIN ordering p ON p.id = s.ordering_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM equipment);
+-- cash_in to cash.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_in', s.id, 'cash_in', s.id, 'cash', s.cash_id AS entity_id, 'total', 1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM cash);
+-- cash_in to contragent.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_in', s.id, 'cash_in', s.id, 'contragent', s.contragent_id AS entity_id, 'total', 1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contragent);
+-- cash_in to contact.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_in', s.id, 'cash_in', s.id, 'contact', s.contact_id AS entity_id, 'total', 1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contact);
+-- cash_in to legal.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_in', s.id, 'cash_in', s.id, 'legal', s.legal_id AS entity_id, 'total', 1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM legal);
+-- cash_out to cash.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_out', s.id, 'cash_out', s.id, 'cash', s.cash_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM cash);
+-- cash_out to contragent.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_out', s.id, 'cash_out', s.id, 'contragent', s.contragent_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contragent);
+-- cash_out to contact.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_out', s.id, 'cash_out', s.id, 'contact', s.contact_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contact);
+-- cash_out to legal.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'cash_out', s.id, 'cash_out', s.id, 'legal', s.legal_id AS entity_id, 'total', -1 * (s.cash_sum) AS amount, s.created_at
+	FROM cash_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM legal);
+-- whs_in to contragent.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_in', s.id, 'whs_in', s.id, 'contragent', s.contragent_id AS entity_id, 'total', 1 * (s.whs_sum + s.delivery) AS amount, s.created_at
+	FROM whs_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contragent);
+-- whs_in to contact.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_in', s.id, 'whs_in', s.id, 'contact', s.contact_id AS entity_id, 'total', 1 * (s.whs_sum + s.delivery) AS amount, s.created_at
+	FROM whs_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contact);
+-- whs_in to legal.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_in', s.id, 'whs_in', s.id, 'legal', s.legal_id AS entity_id, 'total', 1 * (s.whs_sum) AS amount, s.created_at
+	FROM whs_in s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM legal);
+-- whs_out to contragent.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_out', s.id, 'whs_out', s.id, 'contragent', s.contragent_id AS entity_id, 'total', -1 * (s.whs_sum) AS amount, s.created_at
+	FROM whs_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contragent);
+-- whs_out to contact.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_out', s.id, 'whs_out', s.id, 'contact', s.contact_id AS entity_id, 'total', -1 * (s.whs_sum) AS amount, s.created_at
+	FROM whs_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM contact);
+-- whs_out to legal.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_out', s.id, 'whs_out', s.id, 'legal', s.legal_id AS entity_id, 'total', -1 * (s.whs_sum) AS amount, s.created_at
+	FROM whs_out s WHERE s.is_active = 1 AND s.is_active = 1 AND s.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM legal);
+-- matherial_to_whs_in to matherial.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_in', p.id, 'matherial_to_whs_in', s.id, 'matherial', s.matherial_id AS entity_id, 'total', 1 * (s.number) AS amount, p.created_at
+	FROM matherial_to_whs_in s JOIN whs_in p ON p.id = s.whs_in_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM matherial);
+-- matherial_to_whs_in to color.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_in', p.id, 'matherial_to_whs_in', s.id, 'color', s.color_id AS entity_id, 'total', 1 * (s.number) AS amount, p.created_at
+	FROM matherial_to_whs_in s JOIN whs_in p ON p.id = s.whs_in_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM color);
+-- matherial_to_whs_in to wmc_number.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_in', p.id, 'matherial_to_whs_in', s.id, 'wmc_number', (SELECT min(k.id) FROM wmc_number k WHERE k.whs_id = p.whs_id AND k.matherial_id = s.matherial_id AND k.color_id = s.color_id) AS entity_id, 'total', 1 * (s.number) AS amount, p.created_at
+	FROM matherial_to_whs_in s JOIN whs_in p ON p.id = s.whs_in_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM wmc_number);
+-- matherial_to_whs_out to matherial.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_out', p.id, 'matherial_to_whs_out', s.id, 'matherial', s.matherial_id AS entity_id, 'total', -1 * (s.number) AS amount, p.created_at
+	FROM matherial_to_whs_out s JOIN whs_out p ON p.id = s.whs_out_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM matherial);
+-- matherial_to_whs_out to color.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_out', p.id, 'matherial_to_whs_out', s.id, 'color', s.color_id AS entity_id, 'total', -1 * (s.number) AS amount, p.created_at
+	FROM matherial_to_whs_out s JOIN whs_out p ON p.id = s.whs_out_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM color);
+-- matherial_to_whs_out to wmc_number.total
+INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+SELECT * FROM (
+	SELECT 'whs_out', p.id, 'matherial_to_whs_out', s.id, 'wmc_number', (SELECT min(k.id) FROM wmc_number k WHERE k.whs_id = p.whs_id AND k.matherial_id = s.matherial_id AND k.color_id = s.color_id) AS entity_id, 'total', -1 * (s.number) AS amount, p.created_at
+	FROM matherial_to_whs_out s JOIN whs_out p ON p.id = s.whs_out_id WHERE s.is_active = 1 AND p.is_active = 1 AND p.is_realized = 1) WHERE amount != 0 AND entity_id IN (SELECT id FROM wmc_number);
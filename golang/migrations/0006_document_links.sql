@@ -0,0 +1,62 @@
+-- document links, documents with based_on "table.id" made before them
+-- are linked to their parents
+
+CREATE TABLE IF NOT EXISTS document_link
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	parent_table TEXT NOT NULL,
+	parent_id INT NOT NULL,
+	child_table TEXT NOT NULL,
+	child_id INT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS document_link_child ON document_link (child_table, child_id);
+CREATE INDEX IF NOT EXISTS document_link_parent ON document_link (parent_table, parent_id);
+
+INSERT OR IGNORE INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+SELECT substr(based_on, 1, instr(based_on, '.') - 1), CAST(substr(based_on, instr(based_on, '.') + 1) AS INT),
+	'invoice', id, created_at
+FROM invoice WHERE instr(based_on, '.') > 1
+	AND substr(based_on, 1, instr(based_on, '.') - 1) NOT GLOB '*[^a-z_]*'
+	AND substr(based_on, instr(based_on, '.') + 1) != ''
+	AND substr(based_on, instr(based_on, '.') + 1) NOT GLOB '*[^0-9]*';
+
+INSERT OR IGNORE INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+SELECT substr(based_on, 1, instr(based_on, '.') - 1), CAST(substr(based_on, instr(based_on, '.') + 1) AS INT),
+	'cbox_check', id, created_at
+FROM cbox_check WHERE instr(based_on, '.') > 1
+	AND substr(based_on, 1, instr(based_on, '.') - 1) NOT GLOB '*[^a-z_]*'
+	AND substr(based_on, instr(based_on, '.') + 1) != ''
+	AND substr(based_on, instr(based_on, '.') + 1) NOT GLOB '*[^0-9]*';
+
+INSERT OR IGNORE INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+SELECT substr(based_on, 1, instr(based_on, '.') - 1), CAST(substr(based_on, instr(based_on, '.') + 1) AS INT),
+	'cash_in', id, created_at
+FROM cash_in WHERE instr(based_on, '.') > 1
+	AND substr(based_on, 1, instr(based_on, '.') - 1) NOT GLOB '*[^a-z_]*'
+	AND substr(based_on, instr(based_on, '.') + 1) != ''
+	AND substr(based_on, instr(based_on, '.') + 1) NOT GLOB '*[^0-9]*';
+
+INSERT OR IGNORE INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+SELECT substr(based_on, 1, instr(based_on, '.') - 1), CAST(substr(based_on, instr(based_on, '.') + 1) AS INT),
+	'cash_out', id, created_at
+FROM cash_out WHERE instr(based_on, '.') > 1
+	AND substr(based_on, 1, instr(based_on, '.') - 1) NOT GLOB '*[^a-z_]*'
+	AND substr(based_on, instr(based_on, '.') + 1) != ''
+	AND substr(based_on, instr(based_on, '.') + 1) NOT GLOB '*[^0-9]*';
+
+INSERT OR IGNORE INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+SELECT substr(based_on, 1, instr(based_on, '.') - 1), CAST(substr(based_on, instr(based_on, '.') + 1) AS INT),
+	'whs_in', id, created_at
+FROM whs_in WHERE instr(based_on, '.') > 1
+	AND substr(based_on, 1, instr(based_on, '.') - 1) NOT GLOB '*[^a-z_]*'
+	AND substr(based_on, instr(based_on, '.') + 1) != ''
+	AND substr(based_on, instr(based_on, '.') + 1) NOT GLOB '*[^0-9]*';
+
+INSERT OR IGNORE INTO document_link (parent_table, parent_id, child_table, child_id, created_at)
+SELECT substr(based_on, 1, instr(based_on, '.') - 1), CAST(substr(based_on, instr(based_on, '.') + 1) AS INT),
+	'whs_out', id, created_at
+FROM whs_out WHERE instr(based_on, '.') > 1
+	AND substr(based_on, 1, instr(based_on, '.') - 1) NOT GLOB '*[^a-z_]*'
+	AND substr(based_on, instr(based_on, '.') + 1) != ''
+	AND substr(based_on, instr(based_on, '.') + 1) NOT GLOB '*[^0-9]*';
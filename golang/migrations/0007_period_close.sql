@@ -0,0 +1,9 @@
+-- closed periods, global (owner_id 0) or of an owner
+
+CREATE TABLE IF NOT EXISTS period_close
+(
+	owner_id INTEGER PRIMARY KEY,
+	closed_until TEXT NOT NULL,
+	user_id INT NOT NULL,
+	updated_at TEXT NOT NULL
+);
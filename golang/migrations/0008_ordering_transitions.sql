@@ -0,0 +1,15 @@
+-- allowed transitions between statuses of orderings
+
+CREATE TABLE IF NOT EXISTS ordering_transition
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	from_status_id INT NOT NULL,
+	to_status_id INT NOT NULL,
+	base_access INT NOT NULL DEFAULT 0,
+	add_access INT NOT NULL DEFAULT 0,
+	state_id INT NOT NULL DEFAULT 0,
+	finish INT NOT NULL DEFAULT 0,
+	realize INT NOT NULL DEFAULT 0,
+	require_paid INT NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ordering_transition_statuses ON ordering_transition (from_status_id, to_status_id);
@@ -0,0 +1,19 @@
+-- numbering patterns of documents and their counters
+
+CREATE TABLE IF NOT EXISTS doc_sequence
+(
+	doc_table TEXT NOT NULL,
+	owner_id INT NOT NULL,
+	pattern TEXT NOT NULL,
+	user_id INT NOT NULL,
+	updated_at TEXT NOT NULL,
+	PRIMARY KEY (doc_table, owner_id)
+);
+CREATE TABLE IF NOT EXISTS doc_sequence_counter
+(
+	doc_table TEXT NOT NULL,
+	owner_id INT NOT NULL,
+	year INT NOT NULL,
+	last INT NOT NULL,
+	PRIMARY KEY (doc_table, owner_id, year)
+);
@@ -0,0 +1,14 @@
+-- user_group with access masks, existing groups get 0 masks
+-- so their users keep own access
+
+CREATE TABLE user_group_new
+(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	user_group_id INT NOT NULL,
+	position INT NOT NULL,
+	base_access INT NOT NULL DEFAULT 0,
+	add_access INT NOT NULL DEFAULT 0,
+	is_active BOOL NOT NULL,
+	version INT NOT NULL DEFAULT 1
+);
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Movement journal: realize of a document writes a signed movement for every
+// total changed by its realize registers (rz_register and complex_register
+// of the document and of its items), unrealize and delete remove them.
+// Movements are never updated, a balance at a date is the sum of movements
+// up to the date, Total fields stay as the cached current balance.
+
+type Movement struct {
+	Id        int     `json:"id"`
+	DocTable  string  `json:"doc_table"`
+	DocId     int     `json:"doc_id"`
+	Source    string  `json:"source"`
+	SourceId  int     `json:"source_id"`
+	Entity    string  `json:"entity"`
+	EntityId  int     `json:"entity_id"`
+	Field     string  `json:"field"`
+	Amount    float64 `json:"amount"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type Balance struct {
+	EntityId int     `json:"entity_id"`
+	Amount   float64 `json:"amount"`
+}
+
+// movementInsert returns INSERT of movements of the realize register for realized documents,
+// of one document if the query gets its id
+func movementInsert(reg LedgerRegister, oneDoc bool) string {
+	values := []string{}
+	for _, v := range reg.Values {
+		values = append(values, "s."+v)
+	}
+	amount := fmt.Sprintf("%g * (%s)", reg.Sign, strings.Join(values, " + "))
+	entityId := "s." + reg.Target + "_id"
+	if len(reg.KeyFields) > 0 {
+		conds := []string{}
+		for i, f := range reg.KeyFields {
+			v := "s." + reg.KeyValues[i]
+			if j := strings.Index(reg.KeyValues[i], "."); j >= 0 {
+				v = "p." + reg.KeyValues[i][j+1:]
+			}
+			conds = append(conds, "k."+f+" = "+v)
+		}
+		// registers change the first row of the key
+		entityId = fmt.Sprintf("(SELECT min(k.id) FROM %s k WHERE %s)", reg.Target, strings.Join(conds, " AND "))
+	}
+	doc := "s"
+	docTable := reg.Source
+	from := reg.Source + " s"
+	if reg.Parent != "" {
+		doc = "p"
+		docTable = reg.Parent
+		from += fmt.Sprintf(" JOIN %s p ON p.id = s.%s_id", reg.Parent, reg.Parent)
+	}
+	query := fmt.Sprintf(`INSERT INTO movement (doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at)
+		SELECT * FROM (
+			SELECT '%s', %s.id, '%s', s.id, '%s', %s AS entity_id, '%s', %s AS amount, %s.created_at
+			FROM %s WHERE s.is_active = 1 AND %s.is_active = 1 AND %s.is_realized = 1`,
+		docTable, doc, reg.Source, reg.Target, entityId, reg.Field, amount, doc, from, doc, doc)
+	if oneDoc {
+		query += " AND " + doc + ".id = ?"
+	}
+	// registers skip totals of not existing rows
+	return query + fmt.Sprintf(") WHERE amount != 0 AND entity_id IN (SELECT id FROM %s)", reg.Target)
+}
+
+// MovementsWrite writes movements of the realized document
+func MovementsWrite(tx *Tx, docTable string, docId int) error {
+	for _, reg := range ledgerRegisters {
+		if !reg.Realized || (reg.Source != docTable && reg.Parent != docTable) {
+			continue
+		}
+		_, err := tx.Exec(movementInsert(reg, true), docId)
+		if err != nil {
+			return fmt.Errorf("movements of %s to %s.%s: %w", reg.Source, reg.Target, reg.Field, err)
+		}
+	}
+	return nil
+}
+
+// MovementsDelete removes movements of the document with its items
+func MovementsDelete(tx *Tx, docTable string, docId int) error {
+	_, err := tx.Exec("DELETE FROM movement WHERE doc_table=? AND doc_id=?", docTable, docId)
+	return err
+}
+
+// MovementsDeleteSource removes movements of the item of realized document
+func MovementsDeleteSource(tx *Tx, source string, sourceId int) error {
+	_, err := tx.Exec("DELETE FROM movement WHERE source=? AND source_id=?", source, sourceId)
+	return err
+}
+
+// MovementsRewrite writes movements of the document again after its update,
+// nothing is written for not realized document
+func MovementsRewrite(tx *Tx, docTable string, docId int) error {
+	err := MovementsDelete(tx, docTable, docId)
+	if err != nil {
+		return err
+	}
+	return MovementsWrite(tx, docTable, docId)
+}
+
+// movementOwnFilter limits the query to movements of documents of the user
+func movementOwnFilter(query string, args []interface{}, userId int) (string, []interface{}) {
+	conds := []string{"0"}
+	for _, t := range ownTables {
+		conds = append(conds, fmt.Sprintf("(doc_table = '%[1]s' AND doc_id IN (SELECT id FROM %[1]s WHERE user_id = ?))", t))
+		args = append(args, userId)
+	}
+	return query + " AND (" + strings.Join(conds, " OR ") + ")", args
+}
+
+// MovementGetAll returns movements of the entity row, of all rows if id is 0,
+// from and to are dates, empty for no limit, of documents of the user if userId is not 0
+func MovementGetAll(entity string, id int, from, to string, userId int) ([]Movement, error) {
+	query := `SELECT id, doc_table, doc_id, source, source_id, entity, entity_id, field, amount, created_at
+		FROM movement WHERE entity = ?`
+	args := []interface{}{entity}
+	if id != 0 {
+		query += " AND entity_id = ?"
+		args = append(args, id)
+	}
+	if userId != 0 {
+		query, args = movementOwnFilter(query, args, userId)
+	}
+	if from != "" {
+		query += " AND created_at >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		query += " AND created_at <= ?"
+		args = append(args, to)
+	}
+	rows, err := db.Query(query+" ORDER BY created_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []Movement{}
+	for rows.Next() {
+		var m Movement
+		err = rows.Scan(&m.Id, &m.DocTable, &m.DocId, &m.Source, &m.SourceId,
+			&m.Entity, &m.EntityId, &m.Field, &m.Amount, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// BalanceGetAt returns balances of the entity field at the date (all movements if empty),
+// of one row if id is not 0, by documents of the user if userId is not 0
+func BalanceGetAt(entity, field string, id int, at string, userId int) ([]Balance, error) {
+	query := "SELECT entity_id, total(amount) FROM movement WHERE entity = ? AND field = ?"
+	args := []interface{}{entity, field}
+	if id != 0 {
+		query += " AND entity_id = ?"
+		args = append(args, id)
+	}
+	if userId != 0 {
+		query, args = movementOwnFilter(query, args, userId)
+	}
+	if at != "" {
+		// a date without time includes the whole day
+		if len(at) == len("2006-01-02") {
+			at += "T23:59:59"
+		}
+		query += " AND created_at <= ?"
+		args = append(args, at)
+	}
+	rows, err := db.Query(query+" GROUP BY entity_id ORDER BY entity_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []Balance{}
+	for rows.Next() {
+		var b Balance
+		if err = rows.Scan(&b.EntityId, &b.Amount); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
+
+// movementEntityExists reports whether realize registers change the field ("" for any) of the entity
+func movementEntityExists(entity, field string) bool {
+	for _, reg := range ledgerRegisters {
+		if reg.Realized && reg.Target == entity && (field == "" || reg.Field == field) {
+			return true
+		}
+	}
+	return false
+}
+
+func movementQueryId(r Req) (int, error) {
+	v := r.R.URL.Query().Get("id")
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.New("invalid integer parameter id")
+	}
+	return id, nil
+}
+
+// Movement handlers
+
+// GetBalance responds balances of the entity (cash, contragent, wmc_number...)
+// at the date, query parameters: at, id and field (total by default)
+func GetBalance(r Req) {
+	entity := r.StrParam
+	q := r.R.URL.Query()
+	field := q.Get("field")
+	if field == "" {
+		field = "total"
+	}
+	if !movementEntityExists(entity, field) {
+		r.Respond(nil, errors.New("no movements of "+entity+"."+field))
+		return
+	}
+	id, err := movementQueryId(r)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	userId := 0
+	if r.OwnOnly() {
+		userId = r.UserId
+	}
+	r.Respond(BalanceGetAt(entity, field, id, q.Get("at"), userId))
+}
+
+// GetMovements responds movements of the entity, query parameters: id, from and to
+func GetMovements(r Req) {
+	entity := r.StrParam
+	if !movementEntityExists(entity, "") {
+		r.Respond(nil, errors.New("no movements of "+entity))
+		return
+	}
+	id, err := movementQueryId(r)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	userId := 0
+	if r.OwnOnly() {
+		userId = r.UserId
+	}
+	q := r.R.URL.Query()
+	r.Respond(MovementGetAll(entity, id, q.Get("from"), q.Get("to"), userId))
+}
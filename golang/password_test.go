@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		stored   string
+		password string
+		ok       bool
+		legacy   bool
+	}{
+		{"hash", hash, "secret", true, false},
+		{"hash wrong", hash, "Secret", false, false},
+		{"plaintext", "secret", "secret", true, true},
+		{"plaintext wrong", "secret", "secret ", false, true},
+		{"inactive user", "", "", false, false},
+	}
+	for _, tt := range tests {
+		ok, legacy := CheckPassword(tt.stored, tt.password)
+		if ok != tt.ok || legacy != tt.legacy {
+			t.Errorf("%s: CheckPassword = %v, %v, want %v, %v", tt.name, ok, legacy, tt.ok, tt.legacy)
+		}
+	}
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	testBase(t)
+	id := testUser(t, "legacy", "pass", 0, 0)
+	stored := func() string {
+		var p string
+		if err := db.QueryRow("SELECT password FROM user WHERE id=?", id).Scan(&p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	tests := []struct {
+		password string
+		code     int
+		hashed   bool
+	}{
+		{"wrong", http.StatusInternalServerError, false},
+		{"pass", http.StatusOK, true},
+		{"pass", http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		c := newTestClient(t)
+		w := c.do("POST", "/login", `{"login":"legacy","password":"`+tt.password+`"}`)
+		if w.Code != tt.code {
+			t.Fatalf("login with %q: %d %s", tt.password, w.Code, w.Body.String())
+		}
+		// the throttle would make the next attempt wait
+		db.Exec("DELETE FROM login_lock")
+		if p := stored(); IsPasswordHash(p) != tt.hashed {
+			t.Errorf("after login with %q stored password is %q", tt.password, p)
+		}
+	}
+	hash := stored()
+	u, err := UserGet(id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Password = ""
+	if _, err = UserUpdate(u, nil); err != nil || stored() != hash {
+		t.Errorf("update without password changed the hash: %v", err)
+	}
+	u.Password = "new"
+	if _, err = UserUpdate(u, nil); err != nil {
+		t.Fatal(err)
+	}
+	if ok, legacy := CheckPassword(stored(), "new"); !ok || legacy {
+		t.Errorf("new password is stored as %q", stored())
+	}
+}
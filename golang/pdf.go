@@ -0,0 +1,840 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// PDF printouts: a template of pdf.path (the default one is written there on
+// first use, so it can be edited) is executed with the document data, every
+// line of the result is a drawing command:
+//
+//	page                          new A4 page
+//	font SIZE [B]                 font size and style
+//	fill R G B                    fill color of cells
+//	cell W H BORDER ALIGN FILL T  text cell, W 0 is up to the right margin,
+//	                              BORDER 0, 1 or sides LTRB, ALIGN L, C or R
+//	multi W H BORDER ALIGN FILL T multiline text, \n breaks lines
+//	ln [H]                        next line
+//	rule WIDTH                    line across the page
+//	image X DY W FILE             image at X of the page, DY from the line
+//
+// Fonts are TrueType files of the config, of pdf.path/fonts or of the system,
+// nothing is loaded from the network.
+
+type PdfConfig struct {
+	Path     string `json:"path"`
+	Font     string `json:"font"`
+	FontBold string `json:"font_bold"`
+}
+
+//go:embed pdf/*.tmpl
+var pdfDefaults embed.FS
+
+// regular and bold fonts looked for when the config and pdf.path/fonts have none
+var pdfSystemFonts = [][2]string{
+	{"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf", "/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf"},
+	{"/usr/share/fonts/dejavu/DejaVuSans.ttf", "/usr/share/fonts/dejavu/DejaVuSans-Bold.ttf"},
+	{"/usr/share/fonts/TTF/DejaVuSans.ttf", "/usr/share/fonts/TTF/DejaVuSans-Bold.ttf"},
+	{`C:\Windows\Fonts\arial.ttf`, `C:\Windows\Fonts\arialbd.ttf`},
+}
+
+type PdfItem struct {
+	N       int
+	Name    string
+	Number  float64
+	Measure string
+	Price   float64
+	Cost    float64
+}
+
+// PdfDoc is the data of invoice and whs_out templates
+type PdfDoc struct {
+	Id         int
+	Number     string
+	Name       string
+	CreatedAt  string
+	WithDate   bool
+	Comm       string
+	Owner      Owner
+	Contragent Contragent
+	Contact    Contact
+	Legal      Legal
+	Items      []PdfItem
+	Total      float64
+	Sign       string
+}
+
+// PdfOrdering is the data of the ordering work sheet
+type PdfOrdering struct {
+	Ordering   WOrdering
+	Contact    Contact
+	Products   []WProductToOrdering
+	Matherials []WMatherialToOrdering
+	Operations []WOperationToOrdering
+}
+
+type PdfReconciliationRow struct {
+	N         int
+	DocTable  string
+	DocId     int
+	Name      string
+	CreatedAt string
+	Debit     float64
+	Credit    float64
+}
+
+// PdfReconciliation is the data of the reconciliation act, debit is what
+// the contragent owes by documents, credit is what it paid
+type PdfReconciliation struct {
+	From          string
+	To            string
+	Owner         Owner
+	Contragent    Contragent
+	Rows          []PdfReconciliationRow
+	OpeningDebit  float64
+	OpeningCredit float64
+	TotalDebit    float64
+	TotalCredit   float64
+	ClosingDebit  float64
+	ClosingCredit float64
+	Sign          string
+}
+
+var pdfMonths = []string{"", "січня", "лютого", "березня", "квітня", "травня", "червня",
+	"липня", "серпня", "вересня", "жовтня", "листопада", "грудня"}
+
+func pdfTime(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if len(s) >= len(layout) {
+			if t, err := time.Parse(layout, s[:len(layout)]); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// pdfMoney formats the amount with spaces between thousands: 1 234.50
+func pdfMoney(v float64) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', 2, 64)
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	if v < 0 {
+		b.WriteString("-")
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(" ")
+		}
+		b.WriteRune(c)
+	}
+	return b.String() + frac
+}
+
+var (
+	uaUnits  = []string{"", "один", "два", "три", "чотири", "п'ять", "шість", "сім", "вісім", "дев'ять"}
+	uaUnitsF = []string{"", "одна", "дві", "три", "чотири", "п'ять", "шість", "сім", "вісім", "дев'ять"}
+	uaTeens  = []string{"десять", "одинадцять", "дванадцять", "тринадцять", "чотирнадцять",
+		"п'ятнадцять", "шістнадцять", "сімнадцять", "вісімнадцять", "дев'ятнадцять"}
+	uaTens = []string{"", "", "двадцять", "тридцять", "сорок", "п'ятдесят",
+		"шістдесят", "сімдесят", "вісімдесят", "дев'яносто"}
+	uaHundreds = []string{"", "сто", "двісті", "триста", "чотириста", "п'ятсот",
+		"шістсот", "сімсот", "вісімсот", "дев'ятсот"}
+)
+
+// uaPlural returns the form of the noun for the number: one, few (2-4) or many
+func uaPlural(n int, one, few, many string) string {
+	if n%100/10 == 1 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	}
+	return many
+}
+
+// uaTriad returns words of 0..999, feminine for hryvnias and thousands
+func uaTriad(n int, feminine bool) []string {
+	words := []string{}
+	if n/100 != 0 {
+		words = append(words, uaHundreds[n/100])
+	}
+	if n%100/10 == 1 {
+		return append(words, uaTeens[n%10])
+	}
+	if n%100/10 != 0 {
+		words = append(words, uaTens[n%100/10])
+	}
+	if n%10 != 0 {
+		if feminine {
+			words = append(words, uaUnitsF[n%10])
+		} else {
+			words = append(words, uaUnits[n%10])
+		}
+	}
+	return words
+}
+
+// pdfWords returns the amount in words: сто двадцять гривень 50 копійок
+func pdfWords(v float64) string {
+	cents := int(math.Round(math.Abs(v) * 100))
+	n, kop := cents/100, cents%100
+	words := []string{}
+	scales := []struct {
+		div            int
+		one, few, many string
+		feminine       bool
+	}{
+		{1000000000, "мільярд", "мільярди", "мільярдів", false},
+		{1000000, "мільйон", "мільйони", "мільйонів", false},
+		{1000, "тисяча", "тисячі", "тисяч", true},
+	}
+	for _, s := range scales {
+		if t := n / s.div % 1000; t != 0 {
+			words = append(words, uaTriad(t, s.feminine)...)
+			words = append(words, uaPlural(t, s.one, s.few, s.many))
+		}
+	}
+	if n == 0 {
+		words = append(words, "нуль")
+	}
+	words = append(words, uaTriad(n%1000, true)...)
+	words = append(words, uaPlural(n, "гривня", "гривні", "гривень"))
+	words = append(words, fmt.Sprintf("%02d", kop), uaPlural(kop, "копійка", "копійки", "копійок"))
+	return strings.Join(words, " ")
+}
+
+var pdfFuncs = template.FuncMap{
+	"money": pdfMoney,
+	"words": pdfWords,
+	"num": func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	},
+	"inc": func(i int) int {
+		return i + 1
+	},
+	"capital": func(s string) string {
+		r := []rune(s)
+		if len(r) == 0 {
+			return s
+		}
+		return strings.ToUpper(string(r[:1])) + string(r[1:])
+	},
+	// 5 березня 2024р.
+	"date": func(s string) string {
+		t, ok := pdfTime(s)
+		if !ok {
+			return s
+		}
+		return fmt.Sprintf("%d %s %dр.", t.Day(), pdfMonths[t.Month()], t.Year())
+	},
+	"year": func(s string) string {
+		t, ok := pdfTime(s)
+		if !ok {
+			return s
+		}
+		return fmt.Sprintf("%dр.", t.Year())
+	},
+	"shortdate": func(s string) string {
+		t, ok := pdfTime(s)
+		if !ok {
+			return s
+		}
+		return t.Format("02.01.2006")
+	},
+	"datetime": func(s string) string {
+		t, ok := pdfTime(s)
+		if !ok {
+			return s
+		}
+		return t.Format("02.01.2006 15:04")
+	},
+}
+
+// pdfTemplateFile returns the template of pdf.path, it writes the default one there if it is missing
+func pdfTemplateFile(name string) ([]byte, error) {
+	def, err := pdfDefaults.ReadFile("pdf/" + name)
+	if err != nil || Cfg.Pdf.Path == "" {
+		return def, err
+	}
+	file := filepath.Join(Cfg.Pdf.Path, name)
+	data, err := os.ReadFile(file)
+	if err == nil {
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err = os.MkdirAll(Cfg.Pdf.Path, 0755); err == nil {
+		err = os.WriteFile(file, def, 0644)
+	}
+	return def, err
+}
+
+func pdfTemplate(name string) (*template.Template, error) {
+	t := template.New(name).Funcs(pdfFuncs)
+	for _, file := range []string{"common.tmpl", name + ".tmpl"} {
+		data, err := pdfTemplateFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = t.New(file).Parse(string(data)); err != nil {
+			return nil, err
+		}
+	}
+	return t.Lookup(name + ".tmpl"), nil
+}
+
+// pdfFonts returns regular and bold font files
+func pdfFonts() (string, string, error) {
+	if Cfg.Pdf.Font != "" {
+		if Cfg.Pdf.FontBold == "" {
+			return Cfg.Pdf.Font, Cfg.Pdf.Font, nil
+		}
+		return Cfg.Pdf.Font, Cfg.Pdf.FontBold, nil
+	}
+	fonts := append([][2]string{{
+		filepath.Join(Cfg.Pdf.Path, "fonts", "DejaVuSans.ttf"),
+		filepath.Join(Cfg.Pdf.Path, "fonts", "DejaVuSans-Bold.ttf"),
+	}}, pdfSystemFonts...)
+	for _, f := range fonts {
+		regular, bold := f[0], f[1]
+		if fileExists(regular) {
+			if !fileExists(bold) {
+				bold = regular
+			}
+			return regular, bold, nil
+		}
+	}
+	return "", "", errors.New("no font for pdf, set pdf.font in the config or put DejaVuSans.ttf to pdf.path/fonts")
+}
+
+// pdfSign returns the sign image file of the owner, "" if there is none
+func pdfSign(o Owner) string {
+	if o.Sign == "" {
+		return ""
+	}
+	file := filepath.Join(Cfg.Pdf.Path, "signs", filepath.Base(o.Sign))
+	if !fileExists(file) {
+		return ""
+	}
+	return file
+}
+
+// pdfOneLine replaces line breaks in strings of the data, they would break commands
+func pdfOneLine(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		pdfOneLine(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			pdfOneLine(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			pdfOneLine(v.Index(i))
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(v.String()))
+		}
+	}
+}
+
+// pdfArgs splits n arguments of the command line and the text after them
+func pdfArgs(line string, n int) ([]string, string) {
+	args := []string{}
+	for i := 0; i < n; i++ {
+		line = strings.TrimLeft(line, " \t")
+		arg, rest, _ := strings.Cut(line, " ")
+		if arg == "" {
+			break
+		}
+		args = append(args, arg)
+		line = rest
+	}
+	return args, strings.TrimLeft(line, " \t")
+}
+
+func pdfFloats(args []string, n int) ([]float64, error) {
+	if len(args) < n {
+		return nil, fmt.Errorf("%d arguments expected", n)
+	}
+	res := make([]float64, n)
+	for i := 0; i < n; i++ {
+		v, err := strconv.ParseFloat(args[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", args[i])
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// pdfCell draws the text cell, the font is reduced to fit a long text into the cell
+func pdfCell(pdf *fpdf.Fpdf, w, h float64, border, align string, fill bool, text string) {
+	text = strings.ReplaceAll(text, `\n`, " ")
+	if w > 0 && text != "" {
+		if tw := pdf.GetStringWidth(text); tw > w-2 {
+			size, _ := pdf.GetFontSize()
+			pdf.SetFontSize(size * (w - 2) / tw)
+			defer pdf.SetFontSize(size)
+		}
+	}
+	pdf.CellFormat(w, h, text, border, 0, align, fill, 0, "")
+}
+
+func pdfCommand(pdf *fpdf.Fpdf, cmd, rest string) error {
+	switch cmd {
+	case "page":
+		pdf.AddPage()
+	case "font":
+		args, _ := pdfArgs(rest, 2)
+		v, err := pdfFloats(args, 1)
+		if err != nil {
+			return err
+		}
+		style := ""
+		if len(args) > 1 {
+			style = args[1]
+		}
+		pdf.SetFont("main", style, v[0])
+	case "fill":
+		args, _ := pdfArgs(rest, 3)
+		v, err := pdfFloats(args, 3)
+		if err != nil {
+			return err
+		}
+		pdf.SetFillColor(int(v[0]), int(v[1]), int(v[2]))
+	case "cell", "multi":
+		args, text := pdfArgs(rest, 5)
+		v, err := pdfFloats(args, 2)
+		if err != nil {
+			return err
+		}
+		if len(args) < 5 {
+			args = append(args, make([]string, 5-len(args))...)
+		}
+		if cmd == "cell" {
+			pdfCell(pdf, v[0], v[1], args[2], args[3], args[4] == "1", text)
+		} else {
+			pdf.MultiCell(v[0], v[1], strings.ReplaceAll(text, `\n`, "\n"), args[2], args[3], args[4] == "1")
+		}
+	case "ln":
+		args, _ := pdfArgs(rest, 1)
+		if len(args) == 0 {
+			pdf.Ln(-1)
+			break
+		}
+		v, err := pdfFloats(args, 1)
+		if err != nil {
+			return err
+		}
+		pdf.Ln(v[0])
+	case "rule":
+		args, _ := pdfArgs(rest, 1)
+		v, err := pdfFloats(args, 1)
+		if err != nil {
+			return err
+		}
+		left, _, right, _ := pdf.GetMargins()
+		width, _ := pdf.GetPageSize()
+		y := pdf.GetY()
+		pdf.SetLineWidth(v[0])
+		pdf.Line(left, y, width-right, y)
+		pdf.SetLineWidth(0.2)
+	case "image":
+		args, file := pdfArgs(rest, 3)
+		v, err := pdfFloats(args, 3)
+		if err != nil {
+			return err
+		}
+		if file == "" {
+			return errors.New("no image file")
+		}
+		pdf.ImageOptions(file, v[0], pdf.GetY()+v[1], v[2], 0, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+	default:
+		return fmt.Errorf("unknown command %s", cmd)
+	}
+	return nil
+}
+
+// PdfRender executes the template with the data and draws the result
+func PdfRender(name string, data interface{}) ([]byte, error) {
+	t, err := pdfTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	pdfOneLine(reflect.ValueOf(data))
+	var script bytes.Buffer
+	if err = t.Execute(&script, data); err != nil {
+		return nil, err
+	}
+	regular, bold, err := pdfFonts()
+	if err != nil {
+		return nil, err
+	}
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 10)
+	for style, file := range map[string]string{"": regular, "B": bold} {
+		font, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		pdf.AddUTF8FontFromBytes("main", style, font)
+	}
+	pdf.SetFont("main", "", 9)
+	for n, line := range strings.Split(script.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmd, rest, _ := strings.Cut(line, " ")
+		if err = pdfCommand(pdf, cmd, rest); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", name, n+1, err)
+		}
+		if err = pdf.Error(); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", name, n+1, err)
+		}
+	}
+	var res bytes.Buffer
+	err = pdf.Output(&res)
+	return res.Bytes(), err
+}
+
+// pdfNumber returns the name of the document numbered by a sequence, the id otherwise
+func pdfNumber(table, name string, id, ownerId int) string {
+	pattern, _, err := docSequencePattern(table, ownerId, nil)
+	if err != nil || pattern == "" {
+		return strconv.Itoa(id)
+	}
+	return name
+}
+
+// pdfParties fills the owner and the contragent of the document
+func pdfParties(d *PdfDoc, ownerId, contragentId, contactId, legalId int) error {
+	var err error
+	if d.Owner, err = OwnerGet(ownerId, nil); err != nil {
+		return err
+	}
+	if contragentId != 0 {
+		if d.Contragent, err = ContragentGet(contragentId, nil); err != nil {
+			return err
+		}
+	}
+	if contactId != 0 {
+		if d.Contact, err = ContactGet(contactId, nil); err != nil {
+			return err
+		}
+	}
+	if legalId != 0 {
+		if d.Legal, err = LegalGet(legalId, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func InvoicePdfData(id int, withSign, withDate bool) (PdfDoc, error) {
+	d := PdfDoc{WithDate: withDate, Items: []PdfItem{}}
+	i, err := InvoiceGet(id, nil)
+	if err != nil {
+		return d, err
+	}
+	d.Id, d.Name, d.CreatedAt, d.Comm = i.Id, i.Name, i.CreatedAt, i.Comm
+	d.Number = pdfNumber("invoice", i.Name, i.Id, i.OwnerId)
+	if err = pdfParties(&d, i.OwnerId, i.ContragentId, i.ContactId, i.LegalId); err != nil {
+		return d, err
+	}
+	items, err := WItemToInvoiceGetByFilterInt("invoice_id", id, false, false)
+	if err != nil {
+		return d, err
+	}
+	for n, item := range items {
+		d.Items = append(d.Items, PdfItem{n + 1, item.Name, item.Number, item.Measure, item.Price, item.Cost})
+		d.Total += item.Cost
+	}
+	if withSign {
+		d.Sign = pdfSign(d.Owner)
+	}
+	return d, nil
+}
+
+// WhsOutPdfData returns the data of the waybill, the owner is asked or found as for a new invoice
+func WhsOutPdfData(id, ownerId int, withSign, withDate bool) (PdfDoc, error) {
+	d := PdfDoc{WithDate: withDate, Items: []PdfItem{}}
+	w, err := WhsOutGet(id, nil)
+	if err != nil {
+		return d, err
+	}
+	d.Id, d.Name, d.CreatedAt, d.Comm = w.Id, w.Name, w.CreatedAt, w.Comm
+	tx, err := Begin()
+	if err != nil {
+		return d, err
+	}
+	ownerId, err = invoiceOwner(ownerId, w.ContragentId, tx)
+	tx.Rollback()
+	if err != nil {
+		return d, err
+	}
+	d.Number = pdfNumber("whs_out", w.Name, w.Id, ownerId)
+	if err = pdfParties(&d, ownerId, w.ContragentId, w.ContactId, w.LegalId); err != nil {
+		return d, err
+	}
+	items, err := MatherialToWhsOutGetByFilterInt("whs_out_id", id, false, false, nil)
+	if err != nil {
+		return d, err
+	}
+	measures := map[int]string{}
+	for n, item := range items {
+		m, err := MatherialGet(item.MatherialId, nil)
+		if err != nil {
+			return d, err
+		}
+		name := m.Name
+		if item.Width != 0 {
+			name += fmt.Sprintf(" %gx%gмм", item.Width, item.Length)
+		}
+		measure, ok := measures[m.MeasureId]
+		if !ok {
+			ms, err := MeasureGet(m.MeasureId, nil)
+			if err != nil {
+				return d, err
+			}
+			measure = ms.Name
+			measures[m.MeasureId] = measure
+		}
+		d.Items = append(d.Items, PdfItem{n + 1, name, item.Number, measure, item.Price, item.Cost})
+		d.Total += item.Cost
+	}
+	if withSign {
+		d.Sign = pdfSign(d.Owner)
+	}
+	return d, nil
+}
+
+func OrderingPdfData(id int) (PdfOrdering, error) {
+	var d PdfOrdering
+	var err error
+	if d.Ordering, err = WOrderingGet(id); err != nil {
+		return d, err
+	}
+	if d.Ordering.ContactId != 0 {
+		if d.Contact, err = ContactGet(d.Ordering.ContactId, nil); err != nil {
+			return d, err
+		}
+	}
+	if d.Products, err = WProductToOrderingGetByFilterInt("ordering_id", id, false, false); err != nil {
+		return d, err
+	}
+	if d.Matherials, err = WMatherialToOrderingGetByFilterInt("ordering_id", id, false, false); err != nil {
+		return d, err
+	}
+	d.Operations, err = WOperationToOrderingGetByFilterInt("ordering_id", id, false, false)
+	return d, err
+}
+
+// ReconciliationPdfData returns movements of the contragent total from and to the dates
+func ReconciliationPdfData(contragentId, ownerId int, from, to string, withSign bool) (PdfReconciliation, error) {
+	d := PdfReconciliation{Rows: []PdfReconciliationRow{}}
+	now := time.Now()
+	if from == "" {
+		from = now.Format("2006") + "-01-01"
+	}
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	from, to = dateOf(from), dateOf(to)
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return d, errors.New("dates must be like 2006-01-02")
+		}
+	}
+	d.From, d.To = from, to
+	var err error
+	if d.Contragent, err = ContragentGet(contragentId, nil); err != nil {
+		return d, err
+	}
+	tx, err := Begin()
+	if err != nil {
+		return d, err
+	}
+	defer tx.Rollback()
+	if ownerId, err = invoiceOwner(ownerId, contragentId, tx); err != nil {
+		return d, err
+	}
+	if d.Owner, err = OwnerGet(ownerId, tx); err != nil {
+		return d, err
+	}
+	var opening float64
+	err = tx.QueryRow(`SELECT total(amount) FROM movement
+		WHERE entity='contragent' AND field='total' AND entity_id=? AND created_at < ?`,
+		contragentId, from).Scan(&opening)
+	if err != nil {
+		return d, err
+	}
+	rows, err := tx.Query(`SELECT doc_table, doc_id, min(created_at), total(amount) FROM movement
+		WHERE entity='contragent' AND field='total' AND entity_id=? AND created_at >= ? AND created_at <= ?
+		GROUP BY doc_table, doc_id ORDER BY min(created_at), min(id)`,
+		contragentId, from, to+"T23:59:59")
+	if err != nil {
+		return d, err
+	}
+	for rows.Next() {
+		var r PdfReconciliationRow
+		var amount float64
+		if err = rows.Scan(&r.DocTable, &r.DocId, &r.CreatedAt, &amount); err != nil {
+			rows.Close()
+			return d, err
+		}
+		// the total of the contragent goes down by what it owes
+		if amount < 0 {
+			r.Debit = -amount
+		} else {
+			r.Credit = amount
+		}
+		d.TotalDebit += r.Debit
+		d.TotalCredit += r.Credit
+		r.N = len(d.Rows) + 1
+		d.Rows = append(d.Rows, r)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return d, err
+	}
+	for i, r := range d.Rows {
+		err = tx.QueryRow(fmt.Sprintf("SELECT name FROM %s WHERE id=?", r.DocTable), r.DocId).Scan(&d.Rows[i].Name)
+		if err != nil && err != sql.ErrNoRows {
+			return d, err
+		}
+	}
+	if opening < 0 {
+		d.OpeningDebit = -opening
+	} else {
+		d.OpeningCredit = opening
+	}
+	closing := round2(opening - d.TotalDebit + d.TotalCredit)
+	if closing < 0 {
+		d.ClosingDebit = -closing
+	} else {
+		d.ClosingCredit = closing
+	}
+	if withSign {
+		d.Sign = pdfSign(d.Owner)
+	}
+	return d, nil
+}
+
+// pdfQueryInt returns the integer query parameter, 0 if it is not set
+func pdfQueryInt(r Req, name string) (int, error) {
+	v := r.R.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.New("invalid integer parameter " + name)
+	}
+	return i, nil
+}
+
+func pdfQueryBool(r Req, name string) bool {
+	b, _ := strconv.ParseBool(r.R.URL.Query().Get(name))
+	return b
+}
+
+// PDF handlers, query parameters sign=1 adds the sign image of the owner,
+// date=1 prints the date of the document instead of a blank
+
+func GetInvoicePdf(r Req) {
+	d, err := InvoicePdfData(r.IntParam, pdfQueryBool(r, "sign"), pdfQueryBool(r, "date"))
+	if err == nil && r.OwnOnly() {
+		var i Invoice
+		i, err = InvoiceGet(r.IntParam, nil)
+		if err == nil && i.UserId != r.UserId {
+			err = ErrNotOwner
+		}
+	}
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	data, err := PdfRender("invoice", &d)
+	r.RespondFile(fmt.Sprintf("invoice_%d.pdf", r.IntParam), "application/pdf", data, err)
+}
+
+// GetWhsOutPdf responds the waybill, query parameter owner_id chooses the supplier
+func GetWhsOutPdf(r Req) {
+	ownerId, err := pdfQueryInt(r, "owner_id")
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	d, err := WhsOutPdfData(r.IntParam, ownerId, pdfQueryBool(r, "sign"), pdfQueryBool(r, "date"))
+	if err == nil && r.OwnOnly() {
+		var w WhsOut
+		w, err = WhsOutGet(r.IntParam, nil)
+		if err == nil && w.UserId != r.UserId {
+			err = ErrNotOwner
+		}
+	}
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	data, err := PdfRender("whs_out", &d)
+	r.RespondFile(fmt.Sprintf("whs_out_%d.pdf", r.IntParam), "application/pdf", data, err)
+}
+
+func GetOrderingPdf(r Req) {
+	d, err := OrderingPdfData(r.IntParam)
+	if err == nil && r.OwnOnly() && d.Ordering.UserId != r.UserId {
+		err = ErrNotOwner
+	}
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	data, err := PdfRender("ordering", &d)
+	r.RespondFile(fmt.Sprintf("ordering_%d.pdf", r.IntParam), "application/pdf", data, err)
+}
+
+// GetReconciliationPdf responds the reconciliation act of the contragent,
+// query parameters: from (the first day of the year by default), to (today), owner_id
+func GetReconciliationPdf(r Req) {
+	if r.OwnOnly() {
+		r.Respond(nil, ErrAccessDenied)
+		return
+	}
+	ownerId, err := pdfQueryInt(r, "owner_id")
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	q := r.R.URL.Query()
+	d, err := ReconciliationPdfData(r.IntParam, ownerId, q.Get("from"), q.Get("to"), pdfQueryBool(r, "sign"))
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	data, err := PdfRender("reconciliation", &d)
+	r.RespondFile(fmt.Sprintf("reconciliation_%d.pdf", r.IntParam), "application/pdf", data, err)
+}
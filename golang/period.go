@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period closing: documents created on or before the closed date can't be
+// created, updated, deleted, realized or unrealized without ADD_CLOSED_PERIOD.
+// The date is global (owner_id 0) or of an owner, documents with owner_id
+// are closed by the later of both dates.
+
+type PeriodClose struct {
+	OwnerId     int    `json:"owner_id"`
+	ClosedUntil string `json:"closed_until"`
+	UserId      int    `json:"user_id"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type PeriodClosedError struct {
+	Table       string
+	Id          int
+	CreatedAt   string
+	ClosedUntil string
+}
+
+func (e PeriodClosedError) Error() string {
+	return fmt.Sprintf("period is closed until %s: %s %d of %s can't be changed",
+		e.ClosedUntil, e.Table, e.Id, dateOf(e.CreatedAt))
+}
+
+// dateOf returns the date part of "2006-01-02T15:04:05"
+func dateOf(t string) string {
+	if len(t) > len("2006-01-02") {
+		return t[:len("2006-01-02")]
+	}
+	return t
+}
+
+// PeriodClosedUntil returns the closed date for documents of the owner, "" if nothing is closed
+func PeriodClosedUntil(ownerId int, tx *Tx) (string, error) {
+	query := "SELECT ifnull(max(closed_until), '') FROM period_close WHERE owner_id IN (0, ?)"
+	var until string
+	var err error
+	if tx != nil {
+		err = tx.QueryRow(query, ownerId).Scan(&until)
+	} else {
+		err = db.QueryRow(query, ownerId).Scan(&until)
+	}
+	return until, err
+}
+
+// PeriodCheck refuses changes of the document created in the closed period,
+// unless the request of tx has ADD_CLOSED_PERIOD
+func PeriodCheck(table string, id int, createdAt string, ownerId int, tx *Tx) error {
+	until, err := PeriodClosedUntil(ownerId, tx)
+	if err != nil || until == "" {
+		return err
+	}
+	if dateOf(createdAt) > until || tx.AddAccess&ADD_CLOSED_PERIOD != 0 {
+		return nil
+	}
+	return PeriodClosedError{table, id, createdAt, until}
+}
+
+// rowDocument returns the document of the row: the row itself or the document of the item,
+// "" for other rows
+func rowDocument(table string, id int, tx *Tx) (string, int, error) {
+	if _, ok := documentPeriodChecks[table]; ok {
+		return table, id, nil
+	}
+	i := strings.LastIndex(table, "_to_")
+	if i < 0 {
+		return "", 0, nil
+	}
+	doc := table[i+len("_to_"):]
+	if _, ok := documentPeriodChecks[doc]; !ok {
+		return "", 0, nil
+	}
+	var docId int
+	err := tx.QueryRow(fmt.Sprintf("SELECT %s_id FROM %s WHERE id=?", doc, table), id).Scan(&docId)
+	return doc, docId, err
+}
+
+// PeriodCheckRow checks the period of the document of the row
+func PeriodCheckRow(table string, id int, tx *Tx) error {
+	doc, docId, err := rowDocument(table, id, tx)
+	if err != nil || doc == "" {
+		return err
+	}
+	return documentPeriodChecks[doc](docId, tx)
+}
+
+func PeriodCloseGetAll() ([]PeriodClose, error) {
+	rows, err := db.Query("SELECT owner_id, closed_until, user_id, updated_at FROM period_close ORDER BY owner_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []PeriodClose{}
+	for rows.Next() {
+		var p PeriodClose
+		if err = rows.Scan(&p.OwnerId, &p.ClosedUntil, &p.UserId, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+// PeriodCloseSet sets the closed date of the owner (0 for all), empty date opens the period
+func PeriodCloseSet(p PeriodClose, tx *Tx) (PeriodClose, error) {
+	if p.ClosedUntil != "" {
+		if _, err := time.Parse("2006-01-02", p.ClosedUntil); err != nil {
+			return p, errors.New("closed_until must be a date like 2006-01-02")
+		}
+	}
+	before := PeriodClose{OwnerId: p.OwnerId}
+	err := tx.QueryRow("SELECT closed_until, user_id, updated_at FROM period_close WHERE owner_id=?", p.OwnerId).
+		Scan(&before.ClosedUntil, &before.UserId, &before.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return p, err
+	}
+	p.UserId = tx.UserId
+	p.UpdatedAt = time.Now().Format("2006-01-02T15:04:05")
+	if p.ClosedUntil == "" {
+		_, err = tx.Exec("DELETE FROM period_close WHERE owner_id=?", p.OwnerId)
+	} else {
+		_, err = tx.Exec(`INSERT INTO period_close (owner_id, closed_until, user_id, updated_at) VALUES(?, ?, ?, ?)
+			ON CONFLICT(owner_id) DO UPDATE SET closed_until=excluded.closed_until,
+				user_id=excluded.user_id, updated_at=excluded.updated_at`,
+			p.OwnerId, p.ClosedUntil, p.UserId, p.UpdatedAt)
+	}
+	if err != nil {
+		return p, err
+	}
+	err = Audit(tx, "period_close", p.OwnerId, "update", before, p)
+	return p, err
+}
+
+// Period handlers
+
+func GetPeriodCloseAll(r Req) {
+	r.Respond(PeriodCloseGetAll())
+}
+
+func SetPeriodClose(r Req) {
+	var p PeriodClose
+	decoder := json.NewDecoder(r.R.Body)
+	defer r.R.Body.Close()
+	err := decoder.Decode(&p)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	p, err = PeriodCloseSet(p, tx)
+	r.RespondTx(tx, p, err)
+}
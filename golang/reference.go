@@ -0,0 +1,267 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Referential integrity: create and update refuse foreign keys of models.json
+// to missing or deleted rows (0 is no reference). Delete of a catalog row used
+// by active rows is refused with the list of them, unless the request cascades
+// the delete to them or replaces the row with another one. Cascade deletes
+// catalog rows only, documents and their items are never deleted by it.
+// Rows of keyed registers (wmc_number) are totals and user_id is the author
+// of the row, they are not references.
+
+type Reference struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Id     int    `json:"id"`
+}
+
+type ForeignKeyError struct {
+	Table    string
+	Column   string
+	RefTable string
+	RefId    int
+	Deleted  bool
+}
+
+func (e ForeignKeyError) Error() string {
+	state := "does not exist"
+	if e.Deleted {
+		state = "is deleted"
+	}
+	return fmt.Sprintf("%s.%s: %s %d %s", e.Table, e.Column, e.RefTable, e.RefId, state)
+}
+
+type ReferencedError struct {
+	Table      string
+	Id         int
+	References []Reference
+}
+
+func (e ReferencedError) Error() string {
+	return fmt.Sprintf("%s %d is used by %d active rows", e.Table, e.Id, len(e.References))
+}
+
+func isCatalog(table string) bool {
+	for _, t := range catalogTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// rowActive returns whether the row exists and whether it is active
+func rowActive(table string, id int, tx *Tx) (bool, bool, error) {
+	var active bool
+	err := tx.QueryRow(fmt.Sprintf("SELECT is_active FROM %s WHERE id=?", table), id).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
+	return err == nil, active, err
+}
+
+// ForeignKeysCheck checks references of the row, only the changed ones if old is not nil
+func ForeignKeysCheck(table string, values, old map[string]int, tx *Tx) error {
+	for _, fk := range foreignKeys {
+		if fk.Table != table {
+			continue
+		}
+		id := values[fk.Column]
+		if id == 0 || (old != nil && old[fk.Column] == id) {
+			continue
+		}
+		exists, active, err := rowActive(fk.RefTable, id, tx)
+		if err != nil {
+			return err
+		}
+		if !active {
+			return ForeignKeyError{table, fk.Column, fk.RefTable, id, exists}
+		}
+	}
+	return nil
+}
+
+// References returns active rows which refer to the row of the catalog
+func References(table string, id int, tx *Tx) ([]Reference, error) {
+	res := []Reference{}
+	if !isCatalog(table) {
+		return res, nil
+	}
+	for _, fk := range foreignKeys {
+		if fk.RefTable != table || ledgerKeyed(fk.Table) || fk.Column == "user_id" {
+			continue
+		}
+		query := fmt.Sprintf("SELECT id FROM %s WHERE %s=? AND is_active=1", fk.Table, fk.Column)
+		if fk.Table == table {
+			query += fmt.Sprintf(" AND id != %d", id)
+		}
+		rows, err := tx.Query(query+" ORDER BY id", id)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			r := Reference{Table: fk.Table, Column: fk.Column}
+			if err = rows.Scan(&r.Id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			res = append(res, r)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ReferencesCheck refuses delete of the row used by active rows
+func ReferencesCheck(table string, id int, tx *Tx) error {
+	refs, err := References(table, id, tx)
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		return ReferencedError{table, id, refs}
+	}
+	return nil
+}
+
+// ReferencesResolve does what the delete request asks with the references of the row:
+// cascade=1 deletes them, replace=<id> refers them to the other row
+func ReferencesResolve(table string, id int, q url.Values, tx *Tx) error {
+	cascade, _ := strconv.ParseBool(q.Get("cascade"))
+	replace := q.Get("replace")
+	if replace == "" {
+		if cascade {
+			return ReferencesCascade(table, id, map[string]bool{}, tx)
+		}
+		return nil
+	}
+	if cascade {
+		return errors.New("cascade and replace can't be used together")
+	}
+	newId, err := strconv.Atoi(replace)
+	if err != nil {
+		return errors.New("invalid integer parameter replace")
+	}
+	return ReferencesReplace(table, id, newId, tx)
+}
+
+// ReferencesCascade deletes rows which refer to the row, with their references first
+func ReferencesCascade(table string, id int, visited map[string]bool, tx *Tx) error {
+	visited[fmt.Sprintf("%s.%d", table, id)] = true
+	refs, err := References(table, id, tx)
+	if err != nil {
+		return err
+	}
+	docRefs := []Reference{}
+	for _, r := range refs {
+		if !isCatalog(r.Table) {
+			docRefs = append(docRefs, r)
+		}
+	}
+	if len(docRefs) > 0 {
+		return ReferencedError{table, id, docRefs}
+	}
+	for _, r := range refs {
+		if visited[fmt.Sprintf("%s.%d", r.Table, r.Id)] {
+			continue
+		}
+		err = ReferencesCascade(r.Table, r.Id, visited, tx)
+		if err != nil {
+			return err
+		}
+		// items are deleted already with their documents
+		_, active, err := rowActive(r.Table, r.Id, tx)
+		if err != nil {
+			return err
+		}
+		if !active {
+			continue
+		}
+		del, ok := deleteFuncs[r.Table]
+		if !ok {
+			return fmt.Errorf("%s %d can't be deleted", r.Table, r.Id)
+		}
+		err = del(r.Id, tx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReferencesReplace refers rows which refer to the row to the other row,
+// totals and movements of realized documents follow the change
+func ReferencesReplace(table string, id, newId int, tx *Tx) error {
+	if newId == id {
+		return errors.New("replace must be another row")
+	}
+	exists, active, err := rowActive(table, newId, tx)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ForeignKeyError{table, "replace", table, newId, exists}
+	}
+	refs, err := References(table, id, tx)
+	if err != nil {
+		return err
+	}
+	tables := []string{}
+	for _, r := range refs {
+		tables = append(tables, r.Table)
+	}
+	regs := ledgerScope(tables)
+	before, err := ledgerExpected(regs, tx)
+	if err != nil {
+		return err
+	}
+	type doc struct {
+		table string
+		id    int
+	}
+	docs := []doc{}
+	seen := map[doc]bool{}
+	for _, r := range refs {
+		err = PeriodCheckRow(r.Table, r.Id, tx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET %s=?, version=version+1 WHERE id=?", r.Table, r.Column), newId, r.Id)
+		if err != nil {
+			return err
+		}
+		err = Audit(tx, r.Table, r.Id, "replace", map[string]int{r.Column: id}, map[string]int{r.Column: newId})
+		if err != nil {
+			return err
+		}
+		docTable, docId, err := rowDocument(r.Table, r.Id, tx)
+		if err != nil {
+			return err
+		}
+		if d := (doc{docTable, docId}); docTable != "" && !seen[d] {
+			seen[d] = true
+			docs = append(docs, d)
+		}
+	}
+	err = ledgerFollow(before, regs, "replace", tx)
+	if err != nil {
+		return err
+	}
+	for _, d := range docs {
+		err = MovementsRewrite(tx, d.table, d.id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
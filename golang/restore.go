@@ -0,0 +1,265 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Restore and purge of soft deleted rows.
+// Restore makes the row active again with the rows deleted in the same
+// audit batch, i.e. the cascade of the delete, then totals get the change
+// of the ledger and realized documents get their movements back.
+// Purge removes rows deleted more than N days ago which nothing refers to
+// by foreignKeys of models.json, document links or movements.
+
+type ForeignKey struct {
+	Table    string
+	Column   string
+	RefTable string
+}
+
+type RestoredRow struct {
+	Entity string `json:"entity"`
+	Id     int    `json:"id"`
+}
+
+type PurgeReq struct {
+	Days   int  `json:"days"`
+	DryRun bool `json:"dry_run"`
+}
+
+type PurgeResult struct {
+	Purged map[string]int `json:"purged"`
+	Kept   map[string]int `json:"kept"`
+}
+
+func modelTableExists(table string) bool {
+	for _, t := range modelTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteBatch returns the rows deleted with the row, the row is the first one
+func deleteBatch(table string, id int, tx *Tx) ([]RestoredRow, error) {
+	res := []RestoredRow{{table, id}}
+	var batchId string
+	var auditId int
+	err := tx.QueryRow(`SELECT batch_id, id FROM audit_log WHERE entity=? AND entity_id=? AND action='delete'
+		ORDER BY id DESC LIMIT 1`, table, id).Scan(&batchId, &auditId)
+	if err == sql.ErrNoRows {
+		// deleted before the audit trail
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`SELECT entity, entity_id FROM audit_log WHERE batch_id=? AND action='delete' AND id!=?
+		ORDER BY id`, batchId, auditId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r RestoredRow
+		if err = rows.Scan(&r.Entity, &r.Id); err != nil {
+			return nil, err
+		}
+		if modelTableExists(r.Entity) {
+			res = append(res, r)
+		}
+	}
+	return res, rows.Err()
+}
+
+// Restore reverses the soft delete of the row and of the rows deleted with it
+func Restore(table string, id int, tx *Tx) ([]RestoredRow, error) {
+	var active bool
+	err := tx.QueryRow(fmt.Sprintf("SELECT is_active FROM %s WHERE id=?", table), id).Scan(&active)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, fmt.Errorf("%s %d is not deleted", table, id)
+	}
+	batch, err := deleteBatch(table, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	tables := []string{}
+	for _, r := range batch {
+		tables = append(tables, r.Entity)
+	}
+	regs := ledgerScope(tables)
+	before, err := ledgerExpected(regs, tx)
+	if err != nil {
+		return nil, err
+	}
+	restored := []RestoredRow{}
+	for _, r := range batch {
+		err = PeriodCheckRow(r.Entity, r.Id, tx)
+		if err != nil {
+			return nil, err
+		}
+		res, err := tx.Exec(fmt.Sprintf("UPDATE %s SET is_active=1, version=version+1 WHERE id=? AND is_active=0", r.Entity), r.Id)
+		if err != nil {
+			return nil, err
+		}
+		// already restored or purged
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		err = Audit(tx, r.Entity, r.Id, "restore", map[string]bool{"is_active": false}, map[string]bool{"is_active": true})
+		if err != nil {
+			return nil, err
+		}
+		restored = append(restored, r)
+	}
+	err = ledgerFollow(before, regs, "restore", tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range restored {
+		docTable, docId, err := rowDocument(r.Entity, r.Id, tx)
+		if err != nil {
+			return nil, err
+		}
+		if docTable != "" {
+			err = MovementsRewrite(tx, docTable, docId)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return restored, nil
+}
+
+// purgeDeletedAt is the condition of rows deleted before the date,
+// rows without delete in audit_log were deleted before the audit trail
+func purgeDeletedAt(table string) string {
+	return fmt.Sprintf(`t.is_active = 0 AND ifnull((SELECT max(a.created_at) FROM audit_log a
+		WHERE a.entity='%s' AND a.entity_id=t.id AND a.action='delete'), ?) < ?`, table)
+}
+
+// purgeNotReferenced is the condition of rows which nothing refers to
+func purgeNotReferenced(table string) string {
+	conds := []string{
+		fmt.Sprintf("NOT EXISTS (SELECT 1 FROM document_link l WHERE l.parent_table='%s' AND l.parent_id=t.id)", table),
+		fmt.Sprintf("NOT EXISTS (SELECT 1 FROM movement m WHERE m.entity='%s' AND m.entity_id=t.id)", table),
+	}
+	for _, fk := range foreignKeys {
+		if fk.RefTable != table {
+			continue
+		}
+		self := ""
+		if fk.Table == table {
+			self = " AND r.id != t.id"
+		}
+		conds = append(conds, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s r WHERE r.%s = t.id%s)", fk.Table, fk.Column, self))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// Purge removes rows deleted more than days ago which nothing refers to,
+// rows referring to each other go away together
+func Purge(days int, tx *Tx) (PurgeResult, error) {
+	res := PurgeResult{map[string]int{}, map[string]int{}}
+	if days < 1 {
+		return res, errors.New("days must be positive")
+	}
+	var auditStart string
+	err := tx.QueryRow("SELECT ifnull(min(applied_at), '') FROM schema_version WHERE version=2").Scan(&auditStart)
+	if err != nil {
+		return res, err
+	}
+	cutoff := time.Now().AddDate(0, 0, -days).Format("2006-01-02T15:04:05")
+	for {
+		purged := 0
+		for _, table := range modelTables {
+			rows, err := tx.Query(fmt.Sprintf("SELECT t.id FROM %s t WHERE %s AND %s",
+				table, purgeDeletedAt(table), purgeNotReferenced(table)), auditStart, cutoff)
+			if err != nil {
+				return res, err
+			}
+			ids := []int{}
+			for rows.Next() {
+				var id int
+				if err = rows.Scan(&id); err != nil {
+					rows.Close()
+					return res, err
+				}
+				ids = append(ids, id)
+			}
+			rows.Close()
+			if err = rows.Err(); err != nil {
+				return res, err
+			}
+			for _, id := range ids {
+				_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id=?", table), id)
+				if err == nil {
+					_, err = tx.Exec("DELETE FROM document_link WHERE child_table=? AND child_id=?", table, id)
+				}
+				if err == nil {
+					err = Audit(tx, table, id, "purge", nil, nil)
+				}
+				if err != nil {
+					return res, err
+				}
+			}
+			res.Purged[table] += len(ids)
+			purged += len(ids)
+		}
+		if purged == 0 {
+			break
+		}
+	}
+	for _, table := range modelTables {
+		var n int
+		err = tx.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s t WHERE %s", table, purgeDeletedAt(table)),
+			auditStart, cutoff).Scan(&n)
+		if err != nil {
+			return res, err
+		}
+		if n > 0 {
+			res.Kept[table] = n
+		}
+	}
+	for table, n := range res.Purged {
+		if n == 0 {
+			delete(res.Purged, table)
+		}
+	}
+	return res, nil
+}
+
+// Restore and purge handlers
+
+// PurgeDeleted purges deleted rows, with dry_run only counts them
+func PurgeDeleted(r Req) {
+	var p PurgeReq
+	decoder := json.NewDecoder(r.R.Body)
+	defer r.R.Body.Close()
+	err := decoder.Decode(&p)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	res, err := Purge(p.Days, tx)
+	if p.DryRun && err == nil {
+		tx.Rollback()
+		r.Respond(res, nil)
+		return
+	}
+	r.RespondTx(tx, res, err)
+}
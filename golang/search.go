@@ -0,0 +1,380 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Full-text search over contragents, contacts, legals, orderings and projects.
+// search_index is an FTS5 table with trigram tokenizer, so any part of a word
+// or a phone number is found. Triggers on the tables keep it in sync.
+// FTS5 is compiled into go-sqlite3 only with "-tags sqlite_fts5",
+// without it the triggers are dropped and /search returns an error,
+// the index is rebuilt when the server with FTS5 opens the base next time.
+// The query is also searched transliterated Ukrainian <-> Latin,
+// if nothing is found, words are searched by their trigrams to forgive typos.
+
+const searchIndexTable = `CREATE VIRTUAL TABLE IF NOT EXISTS search_index
+USING fts5(entity UNINDEXED, entity_id UNINDEXED, title, body, tokenize='trigram');`
+
+// rowid of search_index is id*searchKinds + Kind of the entity
+const searchKinds = 8
+
+// part of trigrams of a word which must be found in a hit of typo tolerant search
+const searchFuzzyMatch = 0.5
+
+type searchEntity struct {
+	Entity string
+	Kind   int
+	Access uint64
+	Own    bool
+	Title  string
+	Body   []string
+	Phones []string
+}
+
+var searchEntities = []searchEntity{
+	{"contragent", 1, CONTRAGENT_READ, false, "name",
+		[]string{"phone", "email", "web", "comm", "dir_name"}, []string{"phone"}},
+	{"contact", 2, CONTRAGENT_READ, false, "name",
+		[]string{"phone", "email", "viber", "telegram", "comm"}, []string{"phone", "viber"}},
+	{"legal", 3, CONTRAGENT_READ, false, "name",
+		[]string{"full_name", "edrpou", "ipn", "iban", "address", "comm"}, nil},
+	{"ordering", 4, DOC_READ, true, "name", []string{"info"}, nil},
+	{"project", 5, DOC_READ, false, "name", []string{"info", "number_dir"}, nil},
+}
+
+type SearchHit struct {
+	Entity  string  `json:"entity"`
+	Id      int     `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// values returns rowid, entity, entity_id, title and body expressions of the row p
+func (e searchEntity) values(p string) string {
+	body := []string{}
+	for _, c := range e.Body {
+		body = append(body, p+"."+c)
+	}
+	// phones are indexed also as digits only
+	for _, c := range e.Phones {
+		d := p + "." + c
+		for _, ch := range []string{" ", "-", "(", ")", "+", "."} {
+			d = fmt.Sprintf("replace(%s, '%s', '')", d, ch)
+		}
+		body = append(body, d)
+	}
+	return fmt.Sprintf("%[1]s.id*%[2]d+%[3]d, '%[4]s', %[1]s.id, %[1]s.%[5]s, %[6]s",
+		p, searchKinds, e.Kind, e.Entity, e.Title, strings.Join(body, " || ' ' || "))
+}
+
+func (e searchEntity) triggers() []string {
+	del := fmt.Sprintf("DELETE FROM search_index WHERE rowid = old.id*%d+%d;", searchKinds, e.Kind)
+	ins := fmt.Sprintf("INSERT INTO search_index (rowid, entity, entity_id, title, body) SELECT %s WHERE new.is_active;",
+		e.values("new"))
+	columns := append([]string{e.Title, "is_active"}, e.Body...)
+	return []string{
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS search_%[1]s_ai AFTER INSERT ON %[1]s BEGIN\n\t%[2]s\nEND;", e.Entity, ins),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS search_%[1]s_au AFTER UPDATE OF %[2]s ON %[1]s BEGIN\n\t%[3]s\n\t%[4]s\nEND;",
+			e.Entity, strings.Join(columns, ", "), del, ins),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS search_%[1]s_ad AFTER DELETE ON %[1]s BEGIN\n\t%[2]s\nEND;", e.Entity, del),
+	}
+}
+
+func searchAvailable() bool {
+	var used bool
+	err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used)
+	return err == nil && used
+}
+
+// SearchInit creates search_index with its triggers and fills it if it may be outdated
+func SearchInit() error {
+	if !searchAvailable() {
+		log.Print("full-text search is not available, build with -tags sqlite_fts5")
+		for _, e := range searchEntities {
+			for _, a := range []string{"ai", "au", "ad"} {
+				_, err := db.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS search_%s_%s", e.Entity, a))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	var n int
+	err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='trigger' AND name LIKE 'search\\_%' ESCAPE '\\'").Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n == 3*len(searchEntities) {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(searchIndexTable)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM search_index")
+	if err != nil {
+		return err
+	}
+	for _, e := range searchEntities {
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO search_index (rowid, entity, entity_id, title, body) SELECT %s FROM %s WHERE %[2]s.is_active",
+			e.values(e.Entity), e.Entity))
+		if err != nil {
+			return err
+		}
+		for _, t := range e.triggers() {
+			_, err = tx.Exec(t)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	log.Print("search index is rebuilt")
+	return tx.Commit()
+}
+
+var translitUk = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e", 'є': "ie",
+	'ж': "zh", 'з': "z", 'и': "y", 'і': "i", 'ї': "i", 'й': "i", 'к': "k", 'л': "l",
+	'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ь': "", 'ю': "iu",
+	'я': "ia", '\'': "", 'ʼ': "", 'ы': "y", 'э': "e", 'ё': "e", 'ъ': "",
+}
+
+// latin letters and pairs with their ukrainian letters, longer first
+var translitLat = [][2]string{
+	{"shch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"iu", "ю"}, {"yu", "ю"}, {"ia", "я"}, {"ya", "я"}, {"ie", "є"}, {"ye", "є"},
+	{"a", "а"}, {"b", "б"}, {"c", "ц"}, {"d", "д"}, {"e", "е"}, {"f", "ф"}, {"g", "г"},
+	{"h", "г"}, {"i", "і"}, {"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"},
+	{"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"},
+	{"v", "в"}, {"w", "в"}, {"x", "кс"}, {"y", "и"}, {"z", "з"},
+}
+
+// Translit returns the word in latin letters if it has ukrainian ones and vice versa
+func Translit(word string) string {
+	word = strings.ToLower(word)
+	var b strings.Builder
+	cyrillic := false
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			cyrillic = true
+			break
+		}
+	}
+	if cyrillic {
+		for _, r := range word {
+			if s, ok := translitUk[r]; ok {
+				b.WriteString(s)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	}
+	for i := 0; i < len(word); {
+		found := false
+		for _, p := range translitLat {
+			if strings.HasPrefix(word[i:], p[0]) {
+				b.WriteString(p[1])
+				i += len(p[0])
+				found = true
+				break
+			}
+		}
+		if !found {
+			b.WriteByte(word[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+func searchVariants(word string) []string {
+	res := []string{strings.ToLower(word)}
+	if t := Translit(word); t != res[0] {
+		res = append(res, t)
+	}
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, word)
+	if len(digits) >= 3 && digits != res[0] {
+		res = append(res, digits)
+	}
+	return res
+}
+
+func trigrams(s string) []string {
+	r := []rune(s)
+	res := []string{}
+	for i := 0; i+3 <= len(r); i++ {
+		res = append(res, string(r[i:i+3]))
+	}
+	return res
+}
+
+func ftsQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// searchWords returns words of the query with at least 3 letters
+func searchWords(q string) []string {
+	res := []string{}
+	for _, w := range strings.Fields(q) {
+		if len([]rune(w)) >= 3 {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+func searchQuery(match string, filter string, args []interface{}, limit int) ([]SearchHit, []string, error) {
+	rows, err := db.Query(`SELECT entity, entity_id, title, body,
+		snippet(search_index, -1, '[', ']', '…', 12), bm25(search_index, 0, 0, 10.0, 1.0) AS r
+		FROM search_index WHERE search_index MATCH ?`+filter+` ORDER BY r LIMIT ?`,
+		append(append([]interface{}{match}, args...), limit)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	hits := []SearchHit{}
+	texts := []string{}
+	for rows.Next() {
+		var h SearchHit
+		var title, body string
+		if err := rows.Scan(&h.Entity, &h.Id, &title, &body, &h.Snippet, &h.Rank); err != nil {
+			return nil, nil, err
+		}
+		h.Title = title
+		hits = append(hits, h)
+		texts = append(texts, strings.ToLower(title+" "+body))
+	}
+	return hits, texts, nil
+}
+
+// Search returns hits of the query ranked by relevance,
+// baseAccess and userId limit entities to those the user can read
+func Search(q string, limit int, baseAccess uint64, userId int) ([]SearchHit, error) {
+	if !searchAvailable() {
+		return nil, errors.New("full-text search is not available")
+	}
+	words := searchWords(q)
+	if len(words) == 0 {
+		return nil, errors.New("search query needs at least 3 characters")
+	}
+	entities := []string{}
+	args := []interface{}{}
+	own := ""
+	for _, e := range searchEntities {
+		granted := grantedAccess(e.Access, baseAccess)
+		if granted == 0 {
+			continue
+		}
+		entities = append(entities, "?")
+		args = append(args, e.Entity)
+		if e.Own && granted == DOC_OWNREAD {
+			own += fmt.Sprintf(" AND (entity != '%[1]s' OR entity_id IN (SELECT id FROM %[1]s WHERE user_id = %[2]d))",
+				e.Entity, userId)
+		}
+	}
+	if len(entities) == 0 {
+		return nil, ErrAccessDenied
+	}
+	filter := " AND entity IN (" + strings.Join(entities, ", ") + ")" + own
+
+	// every word in any of its variants
+	terms := []string{}
+	for _, w := range words {
+		variants := []string{}
+		for _, v := range searchVariants(w) {
+			variants = append(variants, ftsQuote(v))
+		}
+		terms = append(terms, "("+strings.Join(variants, " OR ")+")")
+	}
+	hits, _, err := searchQuery(strings.Join(terms, " AND "), filter, args, limit)
+	if err != nil || len(hits) > 0 {
+		return hits, err
+	}
+
+	// typo tolerant: any trigram of the words, then hits with enough trigrams of every word
+	grams := map[string]bool{}
+	for _, w := range words {
+		for _, v := range searchVariants(w) {
+			for _, g := range trigrams(v) {
+				grams[g] = true
+			}
+		}
+	}
+	quoted := []string{}
+	for g := range grams {
+		quoted = append(quoted, ftsQuote(g))
+	}
+	sort.Strings(quoted)
+	candidates, texts, err := searchQuery(strings.Join(quoted, " OR "), filter, args, limit*20)
+	if err != nil {
+		return nil, err
+	}
+	hits = []SearchHit{}
+	for i, h := range candidates {
+		if fuzzyMatch(words, texts[i]) {
+			hits = append(hits, h)
+			if len(hits) == limit {
+				break
+			}
+		}
+	}
+	return hits, nil
+}
+
+func fuzzyMatch(words []string, text string) bool {
+	for _, w := range words {
+		best := 0.0
+		for _, v := range searchVariants(w) {
+			grams := trigrams(v)
+			found := 0
+			for _, g := range grams {
+				if strings.Contains(text, g) {
+					found++
+				}
+			}
+			if len(grams) > 0 && float64(found)/float64(len(grams)) > best {
+				best = float64(found) / float64(len(grams))
+			}
+		}
+		if best < searchFuzzyMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// GetSearch searches q in all entities the user can read, limit is 20 by default
+func GetSearch(r Req) {
+	q := r.R.URL.Query()
+	limit := 20
+	if v := q.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			r.Respond(nil, errors.New("invalid integer parameter limit"))
+			return
+		}
+	}
+	r.Respond(Search(q.Get("q"), limit, r.BaseAccess, r.UserId))
+}
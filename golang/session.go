@@ -1,19 +1,308 @@
 package main
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 )
 
-// Store the cookie store which is going to store session data in the cookie
-var Store = sessions.NewCookieStore([]byte("dfrtyu7654erdsw3213456789iuytre4"))
+// Store the session store, sqlite store by default or cookie store
+// if config has "session_store": "cookie". Sessions of the cookie store
+// can't be listed, terminated or revoked, these requests get 501.
+var Store sessions.Store
 
-func init() {
-	Store.Options = &sessions.Options{
+var ErrSessionStore = errors.New(`sessions are kept in cookies, remove "session_store": "cookie" from the config`)
+
+// SessionKey is a pair of keys from config, base64 encoded.
+// Auth signs the cookie, Encrypt (16, 24 or 32 bytes, may be empty) encrypts it.
+// The first pair is used for new cookies, the rest only for reading,
+// so keys can be rotated by adding a new pair at the top.
+type SessionKey struct {
+	Auth    string `json:"auth"`
+	Encrypt string `json:"encrypt"`
+}
+
+func sessionKeyPairs(keys []SessionKey) ([][]byte, error) {
+	if len(keys) == 0 {
+		log.Print("no session_keys in config, sessions are signed with a random key until restart")
+		return [][]byte{securecookie.GenerateRandomKey(32), securecookie.GenerateRandomKey(32)}, nil
+	}
+	pairs := [][]byte{}
+	for i, k := range keys {
+		auth, err := base64.StdEncoding.DecodeString(k.Auth)
+		if err != nil || len(auth) < 32 {
+			return nil, fmt.Errorf("session key %d: auth key must be at least 32 bytes base64 encoded", i)
+		}
+		var enc []byte
+		if k.Encrypt != "" {
+			enc, err = base64.StdEncoding.DecodeString(k.Encrypt)
+			if err != nil || (len(enc) != 16 && len(enc) != 24 && len(enc) != 32) {
+				return nil, fmt.Errorf("session key %d: encrypt key must be 16, 24 or 32 bytes base64 encoded", i)
+			}
+		}
+		pairs = append(pairs, auth, enc)
+	}
+	return pairs, nil
+}
+
+func InitSessions() error {
+	keyPairs, err := sessionKeyPairs(Cfg.SessionKeys)
+	if err != nil {
+		return err
+	}
+	options := &sessions.Options{
 		MaxAge:   86400 * 7,
 		HttpOnly: true,
 	}
+	switch Cfg.SessionStore {
+	case "", "sqlite":
+		Store = &SqliteStore{
+			Codecs:  securecookie.CodecsFromPairs(keyPairs...),
+			Options: options,
+		}
+	case "cookie":
+		cs := sessions.NewCookieStore(keyPairs...)
+		cs.Options = options
+		Store = cs
+	default:
+		return errors.New("unknown session_store " + Cfg.SessionStore)
+	}
+	return nil
+}
+
+// SqliteStore keeps session values in the session table,
+// the cookie holds only signed session id.
+// Deleting a row revokes the session.
+type SqliteStore struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options
+}
+
+// last_seen_at is written not more often than this
+const sessionSeenInterval = time.Minute
+
+// ActiveSession describes a logged in session for admins,
+// Id is rowid of the session, the session id itself is never shown
+type ActiveSession struct {
+	Id          int    `json:"id"`
+	UserId      int    `json:"user_id"`
+	LoginAt     string `json:"login_at"`
+	LastSeenAt  string `json:"last_seen_at"`
+	ExpiresAt   string `json:"expires_at"`
+	RemoteAddr  string `json:"remote_addr"`
+	UserAgent   string `json:"user_agent"`
+	WsConnected bool   `json:"ws_connected"`
+}
+
+func (s *SqliteStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+func (s *SqliteStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	// the cookie is emptied on logout
+	if err != nil || c.Value == "" {
+		return session, nil
+	}
+	err = securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...)
+	if err != nil {
+		return session, err
+	}
+	err = s.load(r, session)
+	if err == sql.ErrNoRows {
+		// revoked or expired session, start the new one
+		session.ID = ""
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *SqliteStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			_, err := db.Exec("DELETE FROM session WHERE id=?", session.ID)
+			if err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+	if session.ID == "" {
+		session.ID = strings.TrimRight(
+			base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+	}
+	err := s.save(r, session)
+	if err != nil {
+		return err
+	}
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+func (s *SqliteStore) load(r *http.Request, session *sessions.Session) error {
+	var data []byte
+	var lastSeen string
+	t := time.Now()
+	now := t.Format("2006-01-02T15:04:05")
+	err := db.QueryRow("SELECT data, last_seen_at FROM session WHERE id=? AND expires_at > ?",
+		session.ID, now).Scan(&data, &lastSeen)
+	if err != nil {
+		return err
+	}
+	if lastSeen < t.Add(-sessionSeenInterval).Format("2006-01-02T15:04:05") {
+		_, err = db.Exec("UPDATE session SET last_seen_at=?, remote_addr=? WHERE id=?", now, RemoteAddr(r), session.ID)
+		if err != nil {
+			log.Println("Error updating session last seen", err)
+		}
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(&session.Values)
+}
+
+func (s *SqliteStore) save(r *http.Request, session *sessions.Session) error {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(session.Values)
+	if err != nil {
+		return err
+	}
+	uid, _ := session.Values["userid"].(int)
+	t := time.Now()
+	createdAt := t.Format("2006-01-02T15:04:05")
+	expiresAt := t.Add(time.Duration(session.Options.MaxAge) * time.Second).Format("2006-01-02T15:04:05")
+
+	// login_at changes only when other user logs in with the same session
+	_, err = db.Exec(`INSERT INTO session (id, user_id, data, created_at, expires_at, login_at, last_seen_at, remote_addr, user_agent)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET user_id=excluded.user_id, data=excluded.data, expires_at=excluded.expires_at,
+		login_at=CASE WHEN session.user_id=excluded.user_id THEN session.login_at ELSE excluded.login_at END,
+		last_seen_at=excluded.last_seen_at, remote_addr=excluded.remote_addr, user_agent=excluded.user_agent;`,
+		session.ID, uid, buf.Bytes(), createdAt, expiresAt, createdAt, createdAt, RemoteAddr(r), r.UserAgent())
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM session WHERE expires_at <= ?", createdAt)
+	return err
+}
+
+// SessionRenew gives the session a new id when the user logs in, so an id
+// known before the login can't be used for the logged in session
+func SessionRenew(session *sessions.Session) error {
+	if _, ok := session.Store().(*SqliteStore); ok && session.ID != "" {
+		_, err := db.Exec("DELETE FROM session WHERE id=?", session.ID)
+		if err != nil {
+			return err
+		}
+	}
+	session.ID = ""
+	return nil
+}
+
+// SessionsRevoke deletes all server side sessions of the user
+func SessionsRevoke(userId int) (int64, error) {
+	if _, ok := Store.(*SqliteStore); !ok {
+		return 0, ErrSessionStore
+	}
+	ids, err := sessionIds("user_id=?", userId)
+	if err != nil {
+		return 0, err
+	}
+	res, err := db.Exec("DELETE FROM session WHERE user_id=?", userId)
+	if err != nil {
+		return 0, err
+	}
+	WsCloseSessions(ids)
+	return res.RowsAffected()
+}
+
+func sessionIds(where string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query("SELECT id FROM session WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// SessionGetAll returns not expired logged in sessions, of the user if userId is not 0
+func SessionGetAll(userId int) ([]ActiveSession, error) {
+	if _, ok := Store.(*SqliteStore); !ok {
+		return nil, ErrSessionStore
+	}
+	query := `SELECT rowid, id, user_id, login_at, last_seen_at, expires_at, remote_addr, user_agent
+		FROM session WHERE user_id != 0 AND expires_at > ?`
+	args := []interface{}{time.Now().Format("2006-01-02T15:04:05")}
+	if userId != 0 {
+		query += " AND user_id = ?"
+		args = append(args, userId)
+	}
+	query += " ORDER BY user_id, last_seen_at DESC"
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	connected := WsSessions()
+	res := []ActiveSession{}
+	for rows.Next() {
+		var a ActiveSession
+		var id string
+		err := rows.Scan(&a.Id, &id, &a.UserId, &a.LoginAt, &a.LastSeenAt, &a.ExpiresAt, &a.RemoteAddr, &a.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+		a.WsConnected = connected[id]
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// SessionTerminate deletes the session by its rowid and closes its websocket
+func SessionTerminate(rowid int) (int64, error) {
+	if _, ok := Store.(*SqliteStore); !ok {
+		return 0, ErrSessionStore
+	}
+	ids, err := sessionIds("rowid=?", rowid)
+	if err != nil {
+		return 0, err
+	}
+	res, err := db.Exec("DELETE FROM session WHERE rowid=?", rowid)
+	if err != nil {
+		return 0, err
+	}
+	WsCloseSessions(ids)
+	return res.RowsAffected()
 }
 
 // IsLoggedIn will check if the user has an active session and return True
@@ -30,34 +319,46 @@ func CurrentUser(r *http.Request) (int, string, error) {
 	if err != nil {
 		return 0, "", err
 	}
-	uid := sess.Values["userid"].(int)
-	login := sess.Values["username"].(string)
+	uid, ok := sess.Values["userid"].(int)
+	if !ok {
+		return 0, "", errors.New("user is not logged in")
+	}
+	login, _ := sess.Values["username"].(string)
 	return uid, login, nil
 }
 
 func HasBaseAccess(accessMask uint64, r *http.Request) bool {
+	return GrantedBaseAccess(accessMask, r) != 0
+}
+
+// GrantedBaseAccess returns the access bit of the user which allows accessMask,
+// DOC_OWNREAD or DOC_OWNUPDATE if only own documents are allowed, 0 if denied
+func GrantedBaseAccess(accessMask uint64, r *http.Request) uint64 {
 	sess, err := Store.Get(r, "sess")
 	if err != nil {
-		return false
+		return 0
 	}
 	uid, ok := sess.Values["userid"].(int)
 	if !ok {
-		return false
+		return 0
 	}
-	userAccess := UserBaseAccess(uid)
+	return grantedAccess(accessMask, UserBaseAccess(uid))
+}
+
+func grantedAccess(accessMask uint64, userAccess uint64) uint64 {
 	if userAccess&accessMask != 0 {
-		return true
+		return accessMask
 	}
-	if accessMask == DOC_READ {
-		return HasBaseAccess(DOC_OWNREAD, r)
+	if accessMask == DOC_READ && userAccess&DOC_OWNREAD != 0 {
+		return DOC_OWNREAD
 	}
-	if accessMask == DOC_UPDATE {
-		return HasBaseAccess(DOC_OWNUPDATE, r)
+	if accessMask == DOC_UPDATE && userAccess&DOC_OWNUPDATE != 0 {
+		return DOC_OWNUPDATE
 	}
 	// if accessMask == WS_CONNECT {
 	// 	return true
 	// }
-	return false
+	return 0
 }
 
 func HasAddAccess(accessMask uint64, r *http.Request) bool {
@@ -65,7 +366,10 @@ func HasAddAccess(accessMask uint64, r *http.Request) bool {
 	if err != nil {
 		return false
 	}
-	uid := sess.Values["userid"].(int)
+	uid, ok := sess.Values["userid"].(int)
+	if !ok {
+		return false
+	}
 	userAccess := UserAddAccess(uid)
 	return userAccess&accessMask != 0
 }
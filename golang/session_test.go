@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSessionEndpoints(t *testing.T) {
+	tests := []struct {
+		store string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"sqlite", http.StatusOK},
+		{"cookie", http.StatusNotImplemented},
+	}
+	saved := Cfg.SessionStore
+	t.Cleanup(func() { Cfg.SessionStore = saved })
+	for _, tt := range tests {
+		t.Run("store "+tt.store, func(t *testing.T) {
+			Cfg.SessionStore = tt.store
+			testBase(t)
+			admin := testLogin(t, "admin", ADMIN, 0)
+			uid := testUser(t, "user", "pass", CATALOG_READ, 0)
+			for _, req := range [][2]string{
+				{"GET", "/session_get_all"},
+				{"GET", fmt.Sprintf("/revoke_sessions/%d", uid)},
+				{"DELETE", "/session/1000"},
+			} {
+				if w := admin.do(req[0], req[1], ""); w.Code != tt.code {
+					t.Errorf("%s %s = %d %s, want %d", req[0], req[1], w.Code, w.Body.String(), tt.code)
+				}
+			}
+		})
+	}
+}
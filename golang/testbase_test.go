@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testBase opens a new migrated base in the temp dir of the test
+func testBase(t *testing.T) {
+	t.Helper()
+	if err := OpenBase(t.TempDir() + "/test.db"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { DbClose() })
+	if err := InitSessions(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for range broadcast {
+		}
+	}()
+}
+
+// testUser adds the active user with the plain password and access masks
+func testUser(t *testing.T, login, password string, baseAccess, addAccess uint64) int {
+	t.Helper()
+	res, err := db.Exec(`INSERT INTO user (name, full_name, user_group_id, cash_id, phone, email, comm,
+		login, password, base_access, add_access, is_active) VALUES (?, ?, 0, 0, '', '', '', ?, ?, ?, ?, 1)`,
+		login, login, login, password, baseAccess, addAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+	return int(id)
+}
+
+type testClient struct {
+	t       *testing.T
+	router  http.Handler
+	cookies []*http.Cookie
+}
+
+func newTestClient(t *testing.T) *testClient {
+	return &testClient{t: t, router: makeRouter()}
+}
+
+func (c *testClient) do(method, url, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	for _, ck := range c.cookies {
+		req.AddCookie(ck)
+	}
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+	if ck := w.Result().Cookies(); len(ck) > 0 {
+		c.cookies = ck
+	}
+	return w
+}
+
+// testLogin adds the user with access masks and logs in by the client
+func testLogin(t *testing.T, login string, baseAccess, addAccess uint64) *testClient {
+	t.Helper()
+	testUser(t, login, "pass", baseAccess, addAccess)
+	c := newTestClient(t)
+	if w := c.do("POST", "/login", `{"login":"`+login+`","password":"pass"}`); w.Code != http.StatusOK {
+		t.Fatalf("login %s: %d %s", login, w.Code, w.Body.String())
+	}
+	return c
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Login throttling: failures are counted per login and per remote address,
+// each next attempt waits exponentially longer, after MaxFailures
+// (IpMaxFailures for address) the key is locked for LockMinutes.
+
+type LoginThrottle struct {
+	MaxFailures       int `json:"max_failures"`
+	IpMaxFailures     int `json:"ip_max_failures"`
+	LockMinutes       int `json:"lock_minutes"`
+	MaxBackoffSeconds int `json:"max_backoff_seconds"`
+}
+
+const timeLayout = "2006-01-02T15:04:05"
+
+type LoginAttempt struct {
+	Id         int    `json:"id"`
+	Login      string `json:"login"`
+	RemoteAddr string `json:"remote_addr"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type LoginLock struct {
+	Id            int    `json:"id"`
+	Kind          string `json:"kind"`
+	Key           string `json:"key"`
+	Failures      int    `json:"failures"`
+	LastFailureAt string `json:"last_failure_at"`
+	LockedUntil   string `json:"locked_until"`
+}
+
+// attempts of a login from an address are checked one by one, so parallel
+// requests can't pass the counters, loginMu guards only the check and loginBusy
+var loginMu sync.Mutex
+var loginBusy = map[string]bool{}
+
+func throttleConfig() LoginThrottle {
+	c := Cfg.LoginThrottle
+	if c.MaxFailures <= 0 {
+		c.MaxFailures = 5
+	}
+	if c.IpMaxFailures <= 0 {
+		c.IpMaxFailures = 20
+	}
+	if c.LockMinutes <= 0 {
+		c.LockMinutes = 15
+	}
+	if c.MaxBackoffSeconds <= 0 {
+		c.MaxBackoffSeconds = 60
+	}
+	return c
+}
+
+func RemoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func loginLockGet(kind, key string) (LoginLock, error) {
+	var l LoginLock
+	row := db.QueryRow("SELECT id, kind, key, failures, last_failure_at, locked_until FROM login_lock WHERE kind=? AND key=?", kind, key)
+	err := row.Scan(&l.Id, &l.Kind, &l.Key, &l.Failures, &l.LastFailureAt, &l.LockedUntil)
+	if err == sql.ErrNoRows {
+		return LoginLock{Kind: kind, Key: key}, nil
+	}
+	return l, err
+}
+
+// current returns the lock with failures forgotten after the lock window
+func (l LoginLock) current(now time.Time, c LoginThrottle) LoginLock {
+	if l.Failures == 0 {
+		return l
+	}
+	last, err := time.ParseInLocation(timeLayout, l.LastFailureAt, time.Local)
+	if err != nil || now.Sub(last) > time.Duration(c.LockMinutes)*time.Minute {
+		if l.LockedUntil <= now.Format(timeLayout) {
+			l.Failures = 0
+			l.LockedUntil = ""
+		}
+	}
+	return l
+}
+
+// wait returns how long the key must wait before next attempt
+func (l LoginLock) wait(now time.Time, c LoginThrottle) time.Duration {
+	if l.LockedUntil > now.Format(timeLayout) {
+		until, _ := time.ParseInLocation(timeLayout, l.LockedUntil, time.Local)
+		return until.Sub(now)
+	}
+	if l.Failures == 0 {
+		return 0
+	}
+	last, err := time.ParseInLocation(timeLayout, l.LastFailureAt, time.Local)
+	if err != nil {
+		return 0
+	}
+	backoff := math.Min(math.Pow(2, float64(l.Failures-1)), float64(c.MaxBackoffSeconds))
+	return last.Add(time.Duration(backoff) * time.Second).Sub(now)
+}
+
+// LoginAllowed returns an error if the login or the address must wait
+func LoginAllowed(login, addr string) error {
+	c := throttleConfig()
+	now := time.Now()
+	for _, kv := range [][2]string{{"login", login}, {"ip", addr}} {
+		l, err := loginLockGet(kv[0], kv[1])
+		if err != nil {
+			return err
+		}
+		wait := l.current(now, c).wait(now, c)
+		if wait > 0 {
+			return fmt.Errorf("забагато невдалих спроб входу - спробуйте через %d с", int(math.Ceil(wait.Seconds())))
+		}
+	}
+	return nil
+}
+
+// LoginBegin checks the throttle and reserves the login from the address
+// until release, a parallel attempt of the same login and address is refused
+func LoginBegin(login, addr string) (release func(), err error) {
+	key := login + "\x00" + addr
+	loginMu.Lock()
+	defer loginMu.Unlock()
+	if loginBusy[key] {
+		return nil, errors.New("попередня спроба входу ще перевіряється - спробуйте ще раз")
+	}
+	err = LoginAllowed(login, addr)
+	if err != nil {
+		return nil, err
+	}
+	loginBusy[key] = true
+	return func() {
+		loginMu.Lock()
+		delete(loginBusy, key)
+		loginMu.Unlock()
+	}, nil
+}
+
+// LoginFailed records the attempt and counts the failure for the login and the address
+func LoginFailed(login, addr string) error {
+	c := throttleConfig()
+	now := time.Now()
+	_, err := db.Exec("INSERT INTO login_attempt (login, remote_addr, created_at) VALUES(?, ?, ?)",
+		login, addr, now.Format(timeLayout))
+	if err != nil {
+		return err
+	}
+	for _, kv := range [][2]string{{"login", login}, {"ip", addr}} {
+		l, err := loginLockGet(kv[0], kv[1])
+		if err != nil {
+			return err
+		}
+		l = l.current(now, c)
+		l.Failures++
+		l.LastFailureAt = now.Format(timeLayout)
+		limit := c.MaxFailures
+		if l.Kind == "ip" {
+			limit = c.IpMaxFailures
+		}
+		if l.Failures >= limit {
+			l.LockedUntil = now.Add(time.Duration(c.LockMinutes) * time.Minute).Format(timeLayout)
+		}
+		_, err = db.Exec(`INSERT INTO login_lock (kind, key, failures, last_failure_at, locked_until)
+			VALUES(?, ?, ?, ?, ?)
+			ON CONFLICT(kind, key) DO UPDATE SET failures=excluded.failures,
+			last_failure_at=excluded.last_failure_at, locked_until=excluded.locked_until;`,
+			l.Kind, l.Key, l.Failures, l.LastFailureAt, l.LockedUntil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoginSucceeded clears the counter of the login, the address keeps its counter
+func LoginSucceeded(login string) error {
+	_, err := db.Exec("DELETE FROM login_lock WHERE kind='login' AND key=?", login)
+	return err
+}
+
+func LoginLockGetAll() ([]LoginLock, error) {
+	rows, err := db.Query("SELECT id, kind, key, failures, last_failure_at, locked_until FROM login_lock ORDER BY last_failure_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []LoginLock{}
+	for rows.Next() {
+		var l LoginLock
+		if err := rows.Scan(&l.Id, &l.Kind, &l.Key, &l.Failures, &l.LastFailureAt, &l.LockedUntil); err != nil {
+			return nil, err
+		}
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func LoginLockDelete(id int) (LoginLock, error) {
+	var l LoginLock
+	row := db.QueryRow("SELECT id, kind, key, failures, last_failure_at, locked_until FROM login_lock WHERE id=?", id)
+	err := row.Scan(&l.Id, &l.Kind, &l.Key, &l.Failures, &l.LastFailureAt, &l.LockedUntil)
+	if err != nil {
+		return l, err
+	}
+	_, err = db.Exec("DELETE FROM login_lock WHERE id=?", id)
+	return l, err
+}
+
+func LoginAttemptGetBetweenCreatedAt(createdAt1, createdAt2 string) ([]LoginAttempt, error) {
+	rows, err := db.Query("SELECT id, login, remote_addr, created_at FROM login_attempt WHERE created_at BETWEEN ? AND ? ORDER BY id DESC",
+		createdAt1, createdAt2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []LoginAttempt{}
+	for rows.Next() {
+		var a LoginAttempt
+		if err := rows.Scan(&a.Id, &a.Login, &a.RemoteAddr, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// Login throttling handlers
+
+func GetLoginLockAll(r Req) {
+	r.Respond(LoginLockGetAll())
+}
+
+func DeleteLoginLock(r Req) {
+	r.Respond(LoginLockDelete(r.IntParam))
+}
+
+func GetLoginAttemptBetweenCreatedAt(r Req) {
+	r.Respond(LoginAttemptGetBetweenCreatedAt(r.StrParam, r.Str2Param))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLockWait(t *testing.T) {
+	c := LoginThrottle{MaxFailures: 5, IpMaxFailures: 20, LockMinutes: 15, MaxBackoffSeconds: 60}
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	ago := func(d time.Duration) string { return now.Add(-d).Format(timeLayout) }
+	tests := []struct {
+		name string
+		lock LoginLock
+		want time.Duration
+	}{
+		{"no failures", LoginLock{}, 0},
+		{"first failure", LoginLock{Failures: 1, LastFailureAt: ago(0)}, time.Second},
+		{"backoff doubles", LoginLock{Failures: 4, LastFailureAt: ago(2 * time.Second)}, 6 * time.Second},
+		{"backoff is capped", LoginLock{Failures: 30, LastFailureAt: ago(0)}, time.Minute},
+		{"backoff passed", LoginLock{Failures: 3, LastFailureAt: ago(10 * time.Second)}, -6 * time.Second},
+		{"locked", LoginLock{Failures: 5, LastFailureAt: ago(time.Minute),
+			LockedUntil: now.Add(14 * time.Minute).Format(timeLayout)}, 14 * time.Minute},
+		{"forgotten after the window", LoginLock{Failures: 30, LastFailureAt: ago(16 * time.Minute)}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.lock.current(now, c).wait(now, c); got != tt.want {
+			t.Errorf("%s: wait = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	testBase(t)
+	saved := Cfg.LoginThrottle
+	t.Cleanup(func() { Cfg.LoginThrottle = saved })
+	Cfg.LoginThrottle = LoginThrottle{MaxFailures: 3, IpMaxFailures: 5, LockMinutes: 15, MaxBackoffSeconds: 60}
+
+	if err := LoginAllowed("admin", "10.0.0.1"); err != nil {
+		t.Fatalf("first attempt: %v", err)
+	}
+	release, err := LoginBegin("admin", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoginBegin("admin", "10.0.0.1"); err == nil {
+		t.Error("parallel attempt of the same login and address is allowed")
+	}
+	release()
+
+	for i := 0; i < 3; i++ {
+		if err = LoginFailed("admin", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name    string
+		login   string
+		addr    string
+		allowed bool
+	}{
+		{"locked login", "admin", "10.0.0.2", false},
+		{"address waits", "other", "10.0.0.1", false},
+		{"other login and address", "other", "10.0.0.2", true},
+	}
+	for _, tt := range tests {
+		if err := LoginAllowed(tt.login, tt.addr); (err == nil) != tt.allowed {
+			t.Errorf("%s: LoginAllowed = %v, want allowed %v", tt.name, err, tt.allowed)
+		}
+	}
+	l, err := loginLockGet("login", "admin")
+	if err != nil || l.Failures != 3 || l.LockedUntil == "" {
+		t.Errorf("lock of the login: %+v, %v", l, err)
+	}
+	if err = LoginSucceeded("admin"); err != nil {
+		t.Fatal(err)
+	}
+	if l, _ = loginLockGet("login", "admin"); l.Failures != 0 {
+		t.Errorf("success keeps failures of the login: %+v", l)
+	}
+	if l, _ = loginLockGet("ip", "10.0.0.1"); l.Failures != 3 {
+		t.Errorf("success clears failures of the address: %+v", l)
+	}
+}
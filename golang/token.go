@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// API tokens for scripts and integrations, sent as "Authorization: Bearer <token>".
+// Only sha256 of the token is stored, the token itself is shown once on creation.
+// Scope and AddScope limit the access bits of the user the token belongs to.
+
+const apiTokenPrefix = "tgt_"
+
+type ApiToken struct {
+	Id         int    `json:"id"`
+	UserId     int    `json:"user_id"`
+	Name       string `json:"name"`
+	Scope      uint64 `json:"scope"`
+	AddScope   uint64 `json:"add_scope"`
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at"`
+	LastUsedAt string `json:"last_used_at"`
+	IsActive   bool   `json:"is_active"`
+}
+
+const apiTokenFields = "id, user_id, name, scope, add_scope, created_at, expires_at, last_used_at, is_active"
+
+func apiTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken returns the token from Authorization header if there is one
+func BearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(h[7:])
+	return token, token != ""
+}
+
+func scanApiToken(row interface{ Scan(...any) error }) (ApiToken, error) {
+	var t ApiToken
+	err := row.Scan(
+		&t.Id,
+		&t.UserId,
+		&t.Name,
+		&t.Scope,
+		&t.AddScope,
+		&t.CreatedAt,
+		&t.ExpiresAt,
+		&t.LastUsedAt,
+		&t.IsActive,
+	)
+	return t, err
+}
+
+// ApiTokenCheck returns active not expired token of active user
+func ApiTokenCheck(token string) (ApiToken, error) {
+	now := time.Now().Format("2006-01-02T15:04:05")
+	row := db.QueryRow(`SELECT api_token.id, api_token.user_id, api_token.name, api_token.scope,
+		api_token.add_scope, api_token.created_at, api_token.expires_at, api_token.last_used_at, api_token.is_active
+		FROM api_token JOIN user ON api_token.user_id = user.id
+		WHERE api_token.token_hash = ? AND api_token.is_active = 1 AND api_token.expires_at > ? AND user.is_active = 1`,
+		apiTokenHash(token), now)
+	t, err := scanApiToken(row)
+	if err != nil {
+		return t, errors.New("invalid api token")
+	}
+	_, err = db.Exec("UPDATE api_token SET last_used_at=? WHERE id=?", now, t.Id)
+	t.LastUsedAt = now
+	return t, err
+}
+
+func ApiTokenGetAll(withDeleted bool, deletedOnly bool) ([]ApiToken, error) {
+	query := "SELECT " + apiTokenFields + " FROM api_token"
+	if deletedOnly {
+		query += " WHERE is_active = 0"
+	} else if !withDeleted {
+		query += " WHERE is_active = 1"
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []ApiToken{}
+	for rows.Next() {
+		t, err := scanApiToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// ApiTokenCreate saves the token and returns it with the plain token
+func ApiTokenCreate(t ApiToken) (ApiToken, string, error) {
+	expires, err := time.ParseInLocation("2006-01-02T15:04:05", t.ExpiresAt, time.Local)
+	if err != nil {
+		expires, err = time.ParseInLocation("2006-01-02", t.ExpiresAt, time.Local)
+	}
+	if err != nil {
+		return t, "", errors.New("expires_at must be like 2006-01-02 or 2006-01-02T15:04:05")
+	}
+	now := time.Now()
+	if !expires.After(now) {
+		return t, "", errors.New("expires_at must be in the future")
+	}
+	_, err = UserGet(t.UserId, nil)
+	if err == sql.ErrNoRows {
+		return t, "", errors.New("user does not exist")
+	}
+	if err != nil {
+		return t, "", err
+	}
+
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		return t, "", err
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	t.CreatedAt = now.Format("2006-01-02T15:04:05")
+	t.ExpiresAt = expires.Format("2006-01-02T15:04:05")
+	t.LastUsedAt = ""
+	t.IsActive = true
+	res, err := db.Exec(`INSERT INTO api_token (user_id, name, token_hash, scope, add_scope, created_at, expires_at, last_used_at, is_active)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.UserId, t.Name, apiTokenHash(token), t.Scope, t.AddScope, t.CreatedAt, t.ExpiresAt, t.LastUsedAt, t.IsActive)
+	if err != nil {
+		return t, "", err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return t, "", err
+	}
+	t.Id = int(id)
+	return t, token, nil
+}
+
+func ApiTokenRevoke(id int) (ApiToken, error) {
+	t, err := scanApiToken(db.QueryRow("SELECT "+apiTokenFields+" FROM api_token WHERE id=?", id))
+	if err != nil {
+		return t, err
+	}
+	_, err = db.Exec("UPDATE api_token SET is_active=0 WHERE id=?", id)
+	t.IsActive = false
+	return t, err
+}
+
+// Api token handlers
+
+func CreateApiToken(r Req) {
+	decoder := json.NewDecoder(r.R.Body)
+	defer r.R.Body.Close()
+	var t ApiToken
+	err := decoder.Decode(&t)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	t, token, err := ApiTokenCreate(t)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	r.Respond(map[string]interface{}{"api_token": t, "token": token}, nil)
+}
+
+func GetApiTokenAll(r Req) {
+	r.Respond(ApiTokenGetAll(r.WithDeleted, r.DeletedOnly))
+}
+
+func RevokeApiToken(r Req) {
+	r.Respond(ApiTokenRevoke(r.IntParam))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApiTokenCreate(t *testing.T) {
+	testBase(t)
+	uid := testUser(t, "script", "pass", CATALOG_READ, 0)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	tests := []struct {
+		name    string
+		token   ApiToken
+		wantErr bool
+	}{
+		{"date", ApiToken{UserId: uid, Name: "a", ExpiresAt: tomorrow}, false},
+		{"date and time", ApiToken{UserId: uid, Name: "b", ExpiresAt: tomorrow + "T10:00:00"}, false},
+		{"past", ApiToken{UserId: uid, Name: "c", ExpiresAt: "2020-01-01"}, true},
+		{"bad date", ApiToken{UserId: uid, Name: "d", ExpiresAt: "tomorrow"}, true},
+		{"unknown user", ApiToken{UserId: uid + 1, Name: "e", ExpiresAt: tomorrow}, true},
+	}
+	for _, tt := range tests {
+		res, token, err := ApiTokenCreate(tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ApiTokenCreate error %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		var stored string
+		db.QueryRow("SELECT token_hash FROM api_token WHERE id=?", res.Id).Scan(&stored)
+		if token == "" || stored != apiTokenHash(token) {
+			t.Errorf("%s: token %q is stored as %q", tt.name, token, stored)
+		}
+	}
+}
+
+func TestApiTokenAccess(t *testing.T) {
+	testBase(t)
+	uid := testUser(t, "script", "pass", CATALOG_READ|CATALOG_CREATE, 0)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	newToken := func(scope uint64) (ApiToken, string) {
+		res, token, err := ApiTokenCreate(ApiToken{UserId: uid, Name: "t", Scope: scope, ExpiresAt: tomorrow})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, token
+	}
+	_, read := newToken(CATALOG_READ)
+	_, wide := newToken(CATALOG_READ | USER_READ)
+	expired, expiredToken := newToken(CATALOG_READ)
+	db.Exec("UPDATE api_token SET expires_at='2020-01-01T00:00:00' WHERE id=?", expired.Id)
+	revoked, revokedToken := newToken(CATALOG_READ)
+	if _, err := ApiTokenRevoke(revoked.Id); err != nil {
+		t.Fatal(err)
+	}
+	router := makeRouter()
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		url    string
+		ok     bool
+	}{
+		{"in scope", read, "GET", "/measure_get_all", true},
+		{"out of scope", read, "POST", "/measure", false},
+		{"scope above user access", wide, "GET", "/user_get_all", false},
+		{"expired", expiredToken, "GET", "/measure_get_all", false},
+		{"revoked", revokedToken, "GET", "/measure_get_all", false},
+		{"unknown", apiTokenPrefix + "x", "GET", "/measure_get_all", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if (w.Code == http.StatusOK) != tt.ok {
+			t.Errorf("%s: %s %s = %d %s", tt.name, tt.method, tt.url, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/securecookie"
+)
+
+// Two-factor authentication with RFC 6238 TOTP codes (SHA1, 6 digits, 30 s).
+// A user with enabled totp gets the session only after /login_totp.
+// A user who must have totp (by access bits or group from config) but has not
+// enabled it yet gets the session which allows only LOGOUT routes
+// to enroll at /totp_enroll and /totp_confirm.
+
+type TotpConfig struct {
+	Issuer         string `json:"issuer"`
+	RequiredAccess uint64 `json:"required_access"`
+	RequiredGroups []int  `json:"required_groups"`
+}
+
+const (
+	totpPeriod        = 30
+	totpDigits        = 6
+	totpRecoveryCodes = 10
+	totpLoginTimeout  = 5 * 60
+)
+
+var ErrTotpSetup = errors.New("потрібно налаштувати двофакторну автентифікацію")
+var errTotpCode = errors.New("невірний код - спробуйте ще раз")
+
+type userTotp struct {
+	UserId        int
+	Secret        string
+	IsConfirmed   bool
+	LastStep      int64
+	RecoveryCodes []string
+}
+
+type totpCodeReq struct {
+	Code string `json:"code"`
+}
+
+func totpGet(userId int, tx *Tx) (userTotp, error) {
+	var t userTotp
+	var codes string
+	query := "SELECT user_id, secret, is_confirmed, last_step, recovery_codes FROM user_totp WHERE user_id=?"
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRow(query, userId)
+	} else {
+		row = db.QueryRow(query, userId)
+	}
+	err := row.Scan(&t.UserId, &t.Secret, &t.IsConfirmed, &t.LastStep, &codes)
+	if err != nil {
+		return t, err
+	}
+	err = json.Unmarshal([]byte(codes), &t.RecoveryCodes)
+	return t, err
+}
+
+// TotpEnabled reports whether the user has confirmed totp
+func TotpEnabled(userId int) bool {
+	t, err := totpGet(userId, nil)
+	return err == nil && t.IsConfirmed
+}
+
+// TotpRequired reports whether config requires totp for the user
+func TotpRequired(userId int) bool {
+	c := Cfg.Totp
+	if c.RequiredAccess != 0 && UserBaseAccess(userId)&c.RequiredAccess != 0 {
+		return true
+	}
+	if len(c.RequiredGroups) == 0 {
+		return false
+	}
+	var groupId int
+	err := db.QueryRow("SELECT user_group_id FROM user WHERE id=?", userId).Scan(&groupId)
+	visited := map[int]bool{}
+	for err == nil && groupId != 0 && !visited[groupId] {
+		for _, g := range c.RequiredGroups {
+			if g == groupId {
+				return true
+			}
+		}
+		visited[groupId] = true
+		err = db.QueryRow("SELECT user_group_id FROM user_group WHERE id=?", groupId).Scan(&groupId)
+	}
+	return false
+}
+
+// TotpSetupPending reports whether the session must set up totp before work
+func TotpSetupPending(r *http.Request) bool {
+	sess, err := Store.Get(r, "sess")
+	if err != nil {
+		return false
+	}
+	pending, _ := sess.Values["totp_setup"].(bool)
+	return pending
+}
+
+func totpCode(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, v%1000000)
+}
+
+func recoveryCodeHash(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.ReplaceAll(code, "-", ""))))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpCheck checks the code (or unused recovery code if allowRecovery)
+// and saves the used step, so one code can't be used twice
+func totpCheck(userId int, code string, allowRecovery bool) error {
+	tx, err := Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := totpGet(userId, tx)
+	if err != nil {
+		return errTotpCode
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totpDigits {
+		secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(t.Secret)
+		if err != nil {
+			return err
+		}
+		now := time.Now().Unix() / totpPeriod
+		for step := now - 1; step <= now+1; step++ {
+			if step > t.LastStep && hmac.Equal([]byte(totpCode(secret, step)), []byte(code)) {
+				_, err = tx.Exec("UPDATE user_totp SET last_step=? WHERE user_id=?", step, userId)
+				if err != nil {
+					return err
+				}
+				return tx.Commit()
+			}
+		}
+		return errTotpCode
+	}
+	if !allowRecovery || !t.IsConfirmed {
+		return errTotpCode
+	}
+	h := recoveryCodeHash(code)
+	for i, c := range t.RecoveryCodes {
+		if hmac.Equal([]byte(c), []byte(h)) {
+			codes := append(t.RecoveryCodes[:i:i], t.RecoveryCodes[i+1:]...)
+			data, _ := json.Marshal(codes)
+			_, err = tx.Exec("UPDATE user_totp SET recovery_codes=? WHERE user_id=?", string(data), userId)
+			if err != nil {
+				return err
+			}
+			log.Print("user ", userId, " used totp recovery code, ", len(codes), " left")
+			return tx.Commit()
+		}
+	}
+	return errTotpCode
+}
+
+func decodeTotpCode(r Req) (string, error) {
+	decoder := json.NewDecoder(r.R.Body)
+	defer r.R.Body.Close()
+	var c totpCodeReq
+	err := decoder.Decode(&c)
+	return c.Code, err
+}
+
+// Totp handlers
+
+// LoginTotp is the second step of login for users with enabled totp
+func LoginTotp(r Req) {
+	sess, err := Store.Get(r.R, "sess")
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	uid, ok := sess.Values["totp_userid"].(int)
+	at, _ := sess.Values["totp_at"].(int64)
+	if !ok || time.Now().Unix()-at > totpLoginTimeout {
+		r.Respond(nil, errors.New("спочатку введіть логін та пароль"))
+		return
+	}
+	code, err := decodeTotpCode(r)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	u, err := UserGet(uid, nil)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	addr := RemoteAddr(r.R)
+	release, err := LoginBegin(u.Login, addr)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	defer release()
+	err = totpCheck(uid, code, true)
+	if err != nil {
+		log.Print("Invalid totp code of " + u.Login + " from " + addr)
+		if err := LoginFailed(u.Login, addr); err != nil {
+			log.Println("Error recording login failure", err)
+		}
+		r.Respond(nil, err)
+		return
+	}
+	err = LoginSucceeded(u.Login)
+	if err != nil {
+		log.Println("Error clearing login failures of", u.Login, err)
+	}
+	r.Respond(startSession(r, sess, uid, u.Login, u.Name), nil)
+}
+
+func GetTotpStatus(r Req) {
+	r.Respond(map[string]bool{
+		"enabled":  TotpEnabled(r.UserId),
+		"required": TotpRequired(r.UserId),
+	}, nil)
+}
+
+// EnrollTotp creates new not confirmed secret of the user
+func EnrollTotp(r Req) {
+	if TotpEnabled(r.UserId) {
+		r.Respond(nil, errors.New("двофакторна автентифікація вже увімкнена"))
+		return
+	}
+	u, err := UserGet(r.UserId, nil)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(securecookie.GenerateRandomKey(20))
+	_, err = db.Exec(`INSERT INTO user_totp (user_id, secret, is_confirmed, last_step, recovery_codes, created_at)
+		VALUES(?, ?, 0, 0, '[]', ?)
+		ON CONFLICT(user_id) DO UPDATE SET secret=excluded.secret, last_step=0, created_at=excluded.created_at;`,
+		r.UserId, secret, time.Now().Format("2006-01-02T15:04:05"))
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	issuer := Cfg.Totp.Issuer
+	if issuer == "" {
+		issuer = "tgtsrv"
+	}
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + u.Login,
+		RawQuery: q.Encode(),
+	}
+	r.Respond(map[string]string{"secret": secret, "uri": uri.String()}, nil)
+}
+
+// ConfirmTotp enables totp after the first valid code and returns recovery codes
+func ConfirmTotp(r Req) {
+	code, err := decodeTotpCode(r)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	if TotpEnabled(r.UserId) {
+		r.Respond(nil, errors.New("двофакторна автентифікація вже увімкнена"))
+		return
+	}
+	err = totpCheck(r.UserId, code, false)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	codes := []string{}
+	hashes := []string{}
+	for i := 0; i < totpRecoveryCodes; i++ {
+		c := strings.ToLower(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(5)))
+		c = c[:4] + "-" + c[4:]
+		codes = append(codes, c)
+		hashes = append(hashes, recoveryCodeHash(c))
+	}
+	data, _ := json.Marshal(hashes)
+	_, err = db.Exec("UPDATE user_totp SET is_confirmed=1, recovery_codes=? WHERE user_id=?", string(data), r.UserId)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	sess, err := Store.Get(r.R, "sess")
+	if err == nil {
+		delete(sess.Values, "totp_setup")
+		sess.Save(r.R, r.W)
+	}
+	r.Respond(map[string][]string{"recovery_codes": codes}, nil)
+}
+
+// DisableTotp turns off totp of the user with a valid code
+func DisableTotp(r Req) {
+	code, err := decodeTotpCode(r)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	if TotpRequired(r.UserId) {
+		r.Respond(nil, errors.New("двофакторна автентифікація обов'язкова для користувача"))
+		return
+	}
+	err = totpCheck(r.UserId, code, true)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	_, err = db.Exec("DELETE FROM user_totp WHERE user_id=?", r.UserId)
+	r.Respond(map[string]bool{"enabled": false}, err)
+}
+
+// ResetUserTotp lets admin remove totp of the user who lost the device and codes
+func ResetUserTotp(r Req) {
+	res, err := db.Exec("DELETE FROM user_totp WHERE user_id=?", r.IntParam)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	n, err := res.RowsAffected()
+	r.Respond(map[string]int64{"reset": n}, err)
+}
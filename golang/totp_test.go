@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 test vectors for SHA1, last 6 digits
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		if got := totpCode(secret, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTotpLogin(t *testing.T) {
+	testBase(t)
+	c := testLogin(t, "second", LOGOUT|CATALOG_READ, 0)
+	w := c.do("POST", "/totp_enroll", "")
+	var enroll struct {
+		Value struct {
+			Secret string `json:"secret"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &enroll); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("enroll: %d %s", w.Code, w.Body.String())
+	}
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enroll.Value.Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := time.Now().Unix() / totpPeriod
+	code := func(step int64) string { return `{"code":"` + totpCode(secret, step) + `"}` }
+	w = c.do("POST", "/totp_confirm", code(step))
+	var confirm struct {
+		Value struct {
+			RecoveryCodes []string `json:"recovery_codes"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &confirm); err != nil || len(confirm.Value.RecoveryCodes) != totpRecoveryCodes {
+		t.Fatalf("confirm: %d %s", w.Code, w.Body.String())
+	}
+	recovery := `{"code":"` + confirm.Value.RecoveryCodes[0] + `"}`
+
+	tests := []struct {
+		name string
+		code string
+		ok   bool
+	}{
+		{"wrong code", `{"code":"000000"}`, false},
+		{"next code", code(step + 1), true},
+		{"used code", code(step + 1), false},
+		{"older code", code(step), false},
+		{"recovery code", recovery, true},
+		{"used recovery code", recovery, false},
+	}
+	for _, tt := range tests {
+		c := newTestClient(t)
+		w := c.do("POST", "/login", `{"login":"second","password":"pass"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: login %d %s", tt.name, w.Code, w.Body.String())
+		}
+		if w = c.do("GET", "/measure_get_all", ""); w.Code == http.StatusOK {
+			t.Fatalf("%s: password alone gives access", tt.name)
+		}
+		w = c.do("POST", "/login_totp", tt.code)
+		if (w.Code == http.StatusOK) != tt.ok {
+			t.Errorf("%s: login_totp %d %s", tt.name, w.Code, w.Body.String())
+		}
+		if w = c.do("GET", "/measure_get_all", ""); (w.Code == http.StatusOK) != tt.ok {
+			t.Errorf("%s: access after login_totp %d", tt.name, w.Code)
+		}
+		// the throttle would make the next attempt wait
+		db.Exec("DELETE FROM login_lock")
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Ordering lifecycle: rows of ordering_transition allow to move an ordering
+// from one status to another (from 0 is any status) for users with the access
+// bits of the row, with its side effects: set the state, stamp finished_at,
+// realize the ordering, and the check of full payment before.
+// Without any transition orderings work as before. With them the status,
+// finished_at and is_realized of an ordering are changed only by transitions,
+// a new ordering starts from a status no transition leads to.
+
+type OrderingTransition struct {
+	Id           int    `json:"id"`
+	FromStatusId int    `json:"from_status_id"`
+	ToStatusId   int    `json:"to_status_id"`
+	BaseAccess   uint64 `json:"base_access"`
+	AddAccess    uint64 `json:"add_access"`
+	StateId      int    `json:"state_id"`
+	Finish       bool   `json:"finish"`
+	Realize      bool   `json:"realize"`
+	RequirePaid  bool   `json:"require_paid"`
+}
+
+type TransitionCheck struct {
+	Allowed bool     `json:"allowed"`
+	Reasons []string `json:"reasons"`
+}
+
+type TransitionBlockedError struct {
+	OrderingId int
+	ToStatusId int
+	Reasons    []string
+}
+
+func (e TransitionBlockedError) Error() string {
+	return fmt.Sprintf("transition of ordering %d to status %d is blocked: %s",
+		e.OrderingId, e.ToStatusId, strings.Join(e.Reasons, "; "))
+}
+
+const orderingTransitionFields = `id, from_status_id, to_status_id, base_access, add_access,
+	state_id, finish, realize, require_paid`
+
+func scanOrderingTransition(row interface{ Scan(...interface{}) error }) (OrderingTransition, error) {
+	var t OrderingTransition
+	err := row.Scan(&t.Id, &t.FromStatusId, &t.ToStatusId, &t.BaseAccess, &t.AddAccess,
+		&t.StateId, &t.Finish, &t.Realize, &t.RequirePaid)
+	return t, err
+}
+
+func OrderingTransitionGetAll() ([]OrderingTransition, error) {
+	rows, err := db.Query("SELECT " + orderingTransitionFields + " FROM ordering_transition ORDER BY from_status_id, to_status_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []OrderingTransition{}
+	for rows.Next() {
+		t, err := scanOrderingTransition(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// OrderingTransitionSet creates the transition or updates the one between the same statuses
+func OrderingTransitionSet(t OrderingTransition, tx *Tx) (OrderingTransition, error) {
+	// from and state are optional
+	refs := []ForeignKey{
+		{"ordering_transition", "from_status_id", "ordering_status"},
+		{"ordering_transition", "to_status_id", "ordering_status"},
+		{"ordering_transition", "state_id", "ordering_state"},
+	}
+	for i, id := range []int{t.FromStatusId, t.ToStatusId, t.StateId} {
+		if id == 0 && refs[i].Column != "to_status_id" {
+			continue
+		}
+		exists, active, err := rowActive(refs[i].RefTable, id, tx)
+		if err != nil {
+			return t, err
+		}
+		if !active {
+			return t, ForeignKeyError{refs[i].Table, refs[i].Column, refs[i].RefTable, id, exists}
+		}
+	}
+	before, err := scanOrderingTransition(tx.QueryRow("SELECT "+orderingTransitionFields+
+		" FROM ordering_transition WHERE from_status_id=? AND to_status_id=?", t.FromStatusId, t.ToStatusId))
+	action := "update"
+	if err == sql.ErrNoRows {
+		action = "create"
+	} else if err != nil {
+		return t, err
+	}
+	err = tx.QueryRow(`INSERT INTO ordering_transition (from_status_id, to_status_id, base_access, add_access,
+			state_id, finish, realize, require_paid)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(from_status_id, to_status_id) DO UPDATE SET base_access=excluded.base_access,
+			add_access=excluded.add_access, state_id=excluded.state_id, finish=excluded.finish,
+			realize=excluded.realize, require_paid=excluded.require_paid
+		RETURNING id`,
+		t.FromStatusId, t.ToStatusId, t.BaseAccess, t.AddAccess, t.StateId, t.Finish, t.Realize, t.RequirePaid).Scan(&t.Id)
+	if err != nil {
+		return t, err
+	}
+	err = Audit(tx, "ordering_transition", t.Id, action, before, t)
+	return t, err
+}
+
+func OrderingTransitionDelete(id int, tx *Tx) (OrderingTransition, error) {
+	t, err := scanOrderingTransition(tx.QueryRow("SELECT "+orderingTransitionFields+" FROM ordering_transition WHERE id=?", id))
+	if err != nil {
+		return t, err
+	}
+	_, err = tx.Exec("DELETE FROM ordering_transition WHERE id=?", id)
+	if err != nil {
+		return t, err
+	}
+	err = Audit(tx, "ordering_transition", id, "delete", t, nil)
+	return t, err
+}
+
+// orderingTransitionFind returns the transition between the statuses, the one from any status if no other
+func orderingTransitionFind(from, to int, tx *Tx) (OrderingTransition, error) {
+	return scanOrderingTransition(tx.QueryRow("SELECT "+orderingTransitionFields+` FROM ordering_transition
+		WHERE from_status_id IN (?, 0) AND to_status_id=? ORDER BY from_status_id DESC LIMIT 1`, from, to))
+}
+
+func orderingTransitionsConfigured(tx *Tx) (bool, error) {
+	var n int
+	err := tx.QueryRow("SELECT count(*) FROM ordering_transition").Scan(&n)
+	return n > 0, err
+}
+
+// OrderingPaid returns realized cash_in of the ordering (based on it or on its cbox checks)
+// less realized cash_out based on it
+func OrderingPaid(id int, tx *Tx) (float64, error) {
+	var paid float64
+	err := tx.QueryRow(`SELECT
+		(SELECT total(c.cash_sum) FROM cash_in c WHERE c.is_active=1 AND c.is_realized=1 AND (
+			c.id IN (SELECT child_id FROM document_link WHERE parent_table='ordering' AND parent_id=? AND child_table='cash_in')
+			OR c.cbox_check_id IN (SELECT id FROM cbox_check WHERE ordering_id=? AND is_active=1)))
+		- (SELECT total(c.cash_sum) FROM cash_out c WHERE c.is_active=1 AND c.is_realized=1 AND
+			c.id IN (SELECT child_id FROM document_link WHERE parent_table='ordering' AND parent_id=? AND child_table='cash_out'))`,
+		id, id, id).Scan(&paid)
+	return paid, err
+}
+
+// accessMissing returns names of the bits of need missing in has
+func accessMissing(need, has uint64, names []AccessName) []string {
+	res := []string{}
+	for _, n := range names {
+		if need&n.Bit != 0 && has&n.Bit == 0 {
+			res = append(res, n.Hum)
+		}
+	}
+	return res
+}
+
+func orderingStatusName(id int, tx *Tx) string {
+	s, err := OrderingStatusGet(id, tx)
+	if err != nil || s.Name == "" {
+		return fmt.Sprint(id)
+	}
+	return s.Name
+}
+
+// OrderingTransitionCheck returns the transition of the ordering to the status
+// with the reasons which block it, none if it is allowed
+func OrderingTransitionCheck(o Ordering, to int, baseAccess, addAccess uint64, tx *Tx) (OrderingTransition, []string, error) {
+	reasons := []string{}
+	if !o.IsActive {
+		reasons = append(reasons, "ordering is deleted")
+	}
+	if o.OrderingStatusId == to {
+		reasons = append(reasons, "ordering is already in status "+orderingStatusName(to, tx))
+		return OrderingTransition{}, reasons, nil
+	}
+	t, err := orderingTransitionFind(o.OrderingStatusId, to, tx)
+	if err == sql.ErrNoRows {
+		reasons = append(reasons, fmt.Sprintf("no transition from status %s to %s",
+			orderingStatusName(o.OrderingStatusId, tx), orderingStatusName(to, tx)))
+		return t, reasons, nil
+	}
+	if err != nil {
+		return t, nil, err
+	}
+	for _, name := range accessMissing(t.BaseAccess, baseAccess, baseAccessNames) {
+		reasons = append(reasons, "access is required: "+name)
+	}
+	for _, name := range accessMissing(t.AddAccess, addAccess, addAccessNames) {
+		reasons = append(reasons, "access is required: "+name)
+	}
+	if t.RequirePaid {
+		paid, err := OrderingPaid(o.Id, tx)
+		if err != nil {
+			return t, nil, err
+		}
+		if paid < o.Cost-ledgerTolerance {
+			reasons = append(reasons, fmt.Sprintf("ordering is not paid: %.2f of %.2f", paid, o.Cost))
+		}
+	}
+	return t, reasons, nil
+}
+
+// OrderingTransit moves the ordering to the status with the side effects of the transition
+func OrderingTransit(id, to int, baseAccess, addAccess uint64, tx *Tx) (Ordering, error) {
+	o, err := OrderingGet(id, tx)
+	if err != nil {
+		return o, err
+	}
+	t, reasons, err := OrderingTransitionCheck(o, to, baseAccess, addAccess, tx)
+	if err != nil {
+		return o, err
+	}
+	if len(reasons) > 0 {
+		return o, TransitionBlockedError{id, to, reasons}
+	}
+	tx.transit = true
+	defer func() { tx.transit = false }()
+	o.OrderingStatusId = to
+	if t.StateId != 0 {
+		o.OrderingStateId = t.StateId
+	}
+	if t.Finish {
+		o.FinishedAt = time.Now().Format("2006-01-02T15:04:05")
+	}
+	o, err = OrderingUpdate(o, tx)
+	if err != nil || !t.Realize || o.IsRealized {
+		return o, err
+	}
+	_, err = OrderingRealized(id, tx)
+	if err != nil {
+		return o, err
+	}
+	return OrderingGet(id, tx)
+}
+
+// orderingTransitionGuard refuses changes of transitions made outside of them
+func orderingTransitionGuard(tx *Tx) (bool, error) {
+	if tx.transit {
+		return false, nil
+	}
+	return orderingTransitionsConfigured(tx)
+}
+
+// OrderingCreateGuard is the create hook of ordering: a new ordering can't be
+// finished or realized and can't have a status some transition leads to
+func OrderingCreateGuard(o *Ordering, tx *Tx) error {
+	guard, err := orderingTransitionGuard(tx)
+	if err != nil || !guard {
+		return err
+	}
+	// the client keeps the default "date" of models.json until the ordering is finished
+	if (o.FinishedAt != "" && o.FinishedAt != "date") || o.IsRealized {
+		return errors.New("new ordering can't be finished or realized, it is done by /ordering/{id}/transition/{status}")
+	}
+	var n int
+	err = tx.QueryRow("SELECT count(*) FROM ordering_transition WHERE to_status_id=?", o.OrderingStatusId).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("new ordering can't have status %s, it is set by /ordering/{id}/transition/{status}",
+			orderingStatusName(o.OrderingStatusId, tx))
+	}
+	return nil
+}
+
+// OrderingUpdateGuard is the update hook of ordering
+func OrderingUpdateGuard(o *Ordering, tx *Tx) error {
+	guard, err := orderingTransitionGuard(tx)
+	if err != nil || !guard {
+		return err
+	}
+	current, err := OrderingGet(o.Id, tx)
+	if err != nil {
+		return err
+	}
+	if current.OrderingStatusId != o.OrderingStatusId || current.FinishedAt != o.FinishedAt ||
+		current.IsRealized != o.IsRealized {
+		return fmt.Errorf("status, finished_at and realize of ordering %d are changed by /ordering/%d/transition/{status}",
+			o.Id, o.Id)
+	}
+	return nil
+}
+
+// OrderingRealizeGuard is the realize hook of ordering
+func OrderingRealizeGuard(o *Ordering, tx *Tx) error {
+	guard, err := orderingTransitionGuard(tx)
+	if err != nil || !guard {
+		return err
+	}
+	return fmt.Errorf("ordering %d is realized by /ordering/%d/transition/{status}", o.Id, o.Id)
+}
+
+// Ordering transition handlers
+
+func GetOrderingTransitionAll(r Req) {
+	r.Respond(OrderingTransitionGetAll())
+}
+
+func SetOrderingTransition(r Req) {
+	var t OrderingTransition
+	decoder := json.NewDecoder(r.R.Body)
+	defer r.R.Body.Close()
+	err := decoder.Decode(&t)
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	t, err = OrderingTransitionSet(t, tx)
+	r.RespondTx(tx, t, err)
+}
+
+func DeleteOrderingTransition(r Req) {
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	t, err := OrderingTransitionDelete(r.IntParam, tx)
+	r.RespondTx(tx, t, err)
+}
+
+// CheckOrderingTransition responds whether the ordering can go to the status and why not
+func CheckOrderingTransition(r Req) {
+	tx, err := Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	defer tx.Rollback()
+	o, err := OrderingGet(r.IntParam, tx)
+	if err == nil && r.OwnOnly() && o.UserId != r.UserId {
+		err = ErrNotOwner
+	}
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	_, reasons, err := OrderingTransitionCheck(o, r.Int2Param, r.BaseAccess, r.AddAccess, tx)
+	r.Respond(TransitionCheck{len(reasons) == 0, reasons}, err)
+}
+
+func TransitOrdering(r Req) {
+	tx, err := r.Begin()
+	if err != nil {
+		r.Respond(nil, err)
+		return
+	}
+	o, err := OrderingGet(r.IntParam, tx)
+	if err == nil && r.OwnOnly() && o.UserId != r.UserId {
+		err = ErrNotOwner
+	}
+	if err == nil {
+		o, err = OrderingTransit(r.IntParam, r.Int2Param, r.BaseAccess, r.AddAccess, tx)
+	}
+	r.RespondTx(tx, o, err)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testTransitions adds statuses new, work, issued with transitions
+// new -> work and work -> issued, the last one finishes, realizes and needs payment
+func testTransitions(t *testing.T, tx *Tx) (int, int, int) {
+	t.Helper()
+	ids := []int{}
+	for _, name := range []string{"new", "work", "issued"} {
+		s, err := OrderingStatusCreate(OrderingStatus{Name: name, IsActive: true}, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, s.Id)
+	}
+	for _, tr := range []OrderingTransition{
+		{FromStatusId: ids[0], ToStatusId: ids[1], BaseAccess: DOC_UPDATE},
+		{FromStatusId: ids[1], ToStatusId: ids[2], Finish: true, Realize: true, RequirePaid: true},
+	} {
+		if _, err := OrderingTransitionSet(tr, tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ids[0], ids[1], ids[2]
+}
+
+func TestOrderingTransit(t *testing.T) {
+	testBase(t)
+	tx, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	newId, workId, issuedId := testTransitions(t, tx)
+	// the markup makes cost more than price, the customer owes cost
+	o, err := OrderingCreate(Ordering{Name: "o", OrderingStatusId: newId, Price: 100, Persent: 20, Cost: 120, IsActive: true}, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		to         int
+		baseAccess uint64
+		paid       float64
+		reason     string
+	}{
+		{"no access", workId, 0, 0, "access is required"},
+		{"no transition", issuedId, DOC_UPDATE, 0, "no transition"},
+		{"to work", workId, DOC_UPDATE, 0, ""},
+		{"not paid", issuedId, 0, 0, "ordering is not paid: 0.00 of 120.00"},
+		{"paid price only", issuedId, 0, 100, "ordering is not paid: 100.00 of 120.00"},
+		{"paid cost", issuedId, 0, 20, ""},
+	}
+	for _, tt := range tests {
+		if tt.paid != 0 {
+			_, err = CashInCreate(CashIn{BasedOn: fmt.Sprintf("ordering.%d", o.Id), CashSum: tt.paid, IsRealized: true, IsActive: true}, tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		o, err = OrderingTransit(o.Id, tt.to, tt.baseAccess, 0, tx)
+		var blocked TransitionBlockedError
+		if tt.reason == "" {
+			if err != nil || o.OrderingStatusId != tt.to {
+				t.Fatalf("%s: status %d %v", tt.name, o.OrderingStatusId, err)
+			}
+			continue
+		}
+		if !errors.As(err, &blocked) || !strings.Contains(strings.Join(blocked.Reasons, "; "), tt.reason) {
+			t.Errorf("%s: %v, want %q", tt.name, err, tt.reason)
+		}
+	}
+	if !o.IsRealized || o.FinishedAt == "" {
+		t.Errorf("issued ordering is not finished and realized: %+v", o)
+	}
+}
+
+func TestOrderingTransitionGuards(t *testing.T) {
+	testBase(t)
+	tx, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	newId, workId, _ := testTransitions(t, tx)
+	tests := []struct {
+		name string
+		o    Ordering
+		ok   bool
+	}{
+		{"new", Ordering{OrderingStatusId: newId, FinishedAt: "date"}, true},
+		{"status of transition", Ordering{OrderingStatusId: workId}, false},
+		{"finished", Ordering{OrderingStatusId: newId, FinishedAt: "2024-01-01T10:00:00"}, false},
+		{"realized", Ordering{OrderingStatusId: newId, IsRealized: true}, false},
+	}
+	for _, tt := range tests {
+		tt.o.Name = tt.name
+		tt.o.IsActive = true
+		o, err := OrderingCreate(tt.o, tx)
+		if (err == nil) != tt.ok {
+			t.Errorf("create %s: %v", tt.name, err)
+		}
+		if err == nil {
+			o.OrderingStatusId = workId
+			if _, err = OrderingUpdate(o, tx); err == nil {
+				t.Errorf("update of status of %s is accepted", tt.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestUpdateVersioned(t *testing.T) {
+	testBase(t)
+	m, err := MeasureCreate(Measure{Name: "kg", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = MeasureUpdate(Measure{Id: m.Id, Name: "kg2", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		version  int
+		conflict bool
+	}{
+		{"stale", m.Version - 1, true},
+		{"zero", 0, true},
+		{"current", m.Version, false},
+		{"reused", m.Version, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := MeasureUpdateVersioned(Measure{Id: m.Id, Name: tt.name, IsActive: true, Version: tt.version}, nil)
+			ce, ok := err.(ConflictError)
+			if ok != tt.conflict {
+				t.Fatalf("conflict %v, want %v: %v", ok, tt.conflict, err)
+			}
+			if ok {
+				if cur := ce.Current.(Measure); cur.Name == tt.name || cur.Version == tt.version {
+					t.Errorf("current %+v overwritten by version %d", cur, tt.version)
+				}
+				return
+			}
+			if err != nil || res.Version != tt.version+1 {
+				t.Errorf("version %d, %v, want %d", res.Version, err, tt.version+1)
+			}
+		})
+	}
+}
+
+func TestUpdateVersionedHttp(t *testing.T) {
+	testBase(t)
+	c := testLogin(t, "cat", CATALOG_READ|CATALOG_CREATE|CATALOG_UPDATE, 0)
+	m, err := MeasureCreate(Measure{Name: "kg", IsActive: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := fmt.Sprintf("/measure/%d", m.Id)
+	put := func(name string, version int) (int, Measure) {
+		w := c.do("PUT", url, fmt.Sprintf(`{"id":%d,"name":%q,"is_active":true,"version":%d}`, m.Id, name, version))
+		var res struct {
+			Value Measure `json:"value"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &res)
+		return w.Code, res.Value
+	}
+	if code, cur := put("first", m.Version); code != http.StatusOK || cur.Version != m.Version+1 {
+		t.Fatalf("current put: %d %+v", code, cur)
+	}
+	code, cur := put("stale", m.Version)
+	if code != http.StatusConflict || cur.Name != "first" {
+		t.Fatalf("stale put: %d %+v", code, cur)
+	}
+
+	const n = 8
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i], _ = put(fmt.Sprintf("concurrent %d", i), cur.Version)
+		}(i)
+	}
+	wg.Wait()
+	ok := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent put %d: %d", i, code)
+		}
+	}
+	if ok != 1 {
+		t.Errorf("%d concurrent puts of one version succeeded, want 1", ok)
+	}
+}